@@ -0,0 +1,455 @@
+package main
+
+// atlas.go contains code for exporting a campaign's sectors as a self-contained static HTML site, which can be
+// browsed offline or hosted on a file share. The site has an index of the sectors, a page for each sector and
+// subsector with its map embedded as SVG, and a page for each world with its UWP broken down, its system details
+// and the referee's notes. Every page has a search box, which searches an index of the worlds held in a script, so
+// no server is needed.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// atlasOptions are the options for exporting an atlas.
+type atlasOptions struct {
+	Campaign string      // The campaign, whose overlays are applied to the sectors
+	Sectors  []string    // The names of the sectors in the atlas
+	Dir      string      // The directory the site is written to
+	NotesDir string      // The directory of the referee's notes, eg "Foreven 0101.txt", if any
+	Style    hexMapStyle // The style of the maps
+}
+
+// atlasLink is a link to another page of the atlas.
+type atlasLink struct {
+	Href string
+	Text string
+	Info string // Shown after the link, eg a number of worlds
+}
+
+// atlasRow is a world in a listing.
+type atlasRow struct {
+	Href, Hex, Name, UWP, Bases, Remarks, Zone, PBG, Allegiance string
+}
+
+// atlasDigit is a digit of a UWP with its meaning.
+type atlasDigit struct {
+	Name, Code, Meaning string
+}
+
+// atlasPage holds what is shown on a page of the atlas. Each kind of page uses some of the fields.
+type atlasPage struct {
+	Title    string
+	Campaign string
+	Root     string      // The path from the page to the top of the site, eg "../"
+	Crumbs   []atlasLink // The pages above this one
+	Map      template.HTML
+	Links    []atlasLink
+	Rows     []atlasRow
+
+	// For world pages.
+	World       atlasRow
+	Description string
+	Digits      []atlasDigit
+	Details     []atlasDigit // The other details, such as the stars and extensions
+	System      string
+	Notes       []string
+	Neighbours  []atlasLink
+}
+
+// atlasSearchEntry is a world in the search index. The names are short to keep the index small.
+type atlasSearchEntry struct {
+	Name    string `json:"n"`
+	Sector  string `json:"s"`
+	Hex     string `json:"h"`
+	UWP     string `json:"u"`
+	Remarks string `json:"r"`
+	Path    string `json:"p"` // The world's page, from the top of the site
+}
+
+// atlasNeighbourRange is how far away, in parsecs, the worlds listed as a world's neighbours are.
+const atlasNeighbourRange = 2
+
+// atlasTemplates are the templates of the pages of the atlas.
+var atlasTemplates = template.Must(template.New("atlas").Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body data-root="{{.Root}}">
+<header>
+<nav><a href="{{.Root}}index.html">{{.Campaign}}</a>{{range .Crumbs}} &rsaquo; <a href="{{.Href}}">{{.Text}}</a>{{end}}</nav>
+<div class="search"><input id="search" type="search" placeholder="Search worlds" autocomplete="off"><ul id="results"></ul></div>
+</header>
+<main>
+<h1>{{.Title}}</h1>
+{{end}}
+
+{{define "foot"}}</main>
+<script src="{{.Root}}search-index.js"></script>
+<script src="{{.Root}}search.js"></script>
+</body>
+</html>
+{{end}}
+
+{{define "links"}}{{if .}}<ul class="links">{{range .}}<li><a href="{{.Href}}">{{.Text}}</a>{{if .Info}} <span class="info">{{.Info}}</span>{{end}}</li>{{end}}</ul>{{end}}{{end}}
+
+{{define "rows"}}{{if .}}<table class="worlds">
+<tr><th>Hex</th><th>Name</th><th>UWP</th><th>Bases</th><th>Remarks</th><th>Zone</th><th>PBG</th><th>Allegiance</th></tr>
+{{range .}}<tr><td>{{.Hex}}</td><td><a href="{{.Href}}">{{.Name}}</a></td><td class="uwp">{{.UWP}}</td><td>{{.Bases}}</td><td>{{.Remarks}}</td><td>{{.Zone}}</td><td>{{.PBG}}</td><td>{{.Allegiance}}</td></tr>
+{{end}}</table>{{end}}{{end}}
+
+{{define "index"}}{{template "head" .}}<h2>Sectors</h2>
+{{template "links" .Links}}{{template "foot" .}}{{end}}
+
+{{define "sector"}}{{template "head" .}}<div class="map">{{.Map}}</div>
+<h2>Subsectors</h2>
+{{template "links" .Links}}{{template "foot" .}}{{end}}
+
+{{define "subsector"}}{{template "head" .}}<div class="map">{{.Map}}</div>
+<h2>Worlds</h2>
+{{template "rows" .Rows}}
+{{template "links" .Links}}{{template "foot" .}}{{end}}
+
+{{define "world"}}{{template "head" .}}<p class="uwp">{{.World.Hex}} {{.World.UWP}}</p>
+<p>{{.Description}}</p>
+<h2>Universal World Profile</h2>
+<table class="digits">{{range .Digits}}<tr><th>{{.Name}}</th><td class="uwp">{{.Code}}</td><td>{{.Meaning}}</td></tr>{{end}}</table>
+<h2>Details</h2>
+<table class="digits">{{range .Details}}<tr><th>{{.Name}}</th><td colspan="2">{{.Code}}</td></tr>{{end}}</table>
+<h2>System</h2>
+{{if .System}}<pre>{{.System}}</pre>{{else}}<p>No system details have been generated.</p>{{end}}
+{{if .Notes}}<h2>Notes</h2>
+{{range .Notes}}<p>{{.}}</p>
+{{end}}{{end}}{{if .Neighbours}}<h2>Nearby worlds</h2>
+{{template "links" .Neighbours}}{{end}}{{template "foot" .}}{{end}}
+`))
+
+// atlasStyle is the style sheet of the atlas.
+const atlasStyle = `body { font-family: sans-serif; margin: 0; color: #222; background: #fafafa; }
+header { display: flex; justify-content: space-between; align-items: flex-start; padding: 0.5em 1em; background: #1d2733; color: #eee; }
+header a { color: #9cf; }
+main { padding: 1em; }
+.search { position: relative; }
+#results { position: absolute; right: 0; z-index: 1; margin: 0; padding: 0; list-style: none; background: #fff; min-width: 20em; }
+#results li { padding: 0.2em 0.5em; border-bottom: 1px solid #ddd; }
+#results a { color: #036; }
+.map svg { max-width: 100%; height: auto; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 0.15em 0.6em; border-bottom: 1px solid #ddd; }
+.uwp { font-family: monospace; }
+.info { color: #666; }
+pre { background: #eee; padding: 0.5em; overflow-x: auto; }
+`
+
+// atlasSearch is the script that searches the index of worlds as the user types.
+const atlasSearch = `(function () {
+  var box = document.getElementById("search"), list = document.getElementById("results");
+  var root = document.body.getAttribute("data-root");
+  box.addEventListener("input", function () {
+    var q = box.value.trim().toLowerCase();
+    list.innerHTML = "";
+    if (q.length < 2) {
+      return;
+    }
+    for (var i = 0, n = 0; i < atlasIndex.length && n < 50; i++) {
+      var e = atlasIndex[i];
+      if ((e.n + " " + e.s + " " + e.h + " " + e.u + " " + e.r).toLowerCase().indexOf(q) < 0) {
+        continue;
+      }
+      var li = document.createElement("li"), a = document.createElement("a");
+      a.href = root + e.p;
+      a.textContent = e.n + " (" + e.s + " " + e.h + ") " + e.u;
+      li.appendChild(a);
+      list.appendChild(li);
+      n++;
+    }
+  });
+})();
+`
+
+// atlasSlug returns the name of the directory for the sector's pages, eg "Spinward_Marches".
+func atlasSlug(name string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(sectorFilename(name), ".tab"), " ", "_")
+}
+
+// atlasWorldHref returns the name of the world's page, in its sector's directory.
+func atlasWorldHref(w *world) string {
+	return w.hexLoc.String() + ".html"
+}
+
+// atlasSubsectorHref returns the name of the page of the subsector with the index (0 to 15).
+func atlasSubsectorHref(idx int) string {
+	return "subsector-" + ssIndex[idx] + ".html"
+}
+
+// atlasRowFor returns the world as a row of a listing.
+func atlasRowFor(w *world) atlasRow {
+	return atlasRow{Href: atlasWorldHref(w), Hex: w.hexLoc.String(), Name: w.name, UWP: w.uwp.String(), Bases: w.bases,
+		Remarks: w.remarks, Zone: w.zone.Desc(), PBG: w.pbg.String(), Allegiance: w.allegiance}
+}
+
+// atlasDigits breaks down the world's UWP into its digits and their meanings.
+func atlasDigits(w *world) []atlasDigit {
+	u := w.uwp
+	return []atlasDigit{
+		{"Starport", u.starport, tableStarport(u.starport)},
+		{"Size", Ehex(u.sizeInt).String(), tableEntry(tableSizes[:], u.sizeInt)},
+		{"Atmosphere", Ehex(u.atmInt).String(), tableEntry(tableAtmospheres[:], u.atmInt)},
+		{"Hydrographics", Ehex(u.hydInt).String(), tableEntry(tableHydrographics[:], u.hydInt)},
+		{"Population", Ehex(u.popInt).String(), tableEntry(tablePopulations[:], u.popInt)},
+		{"Government", Ehex(u.govInt).String(), tableEntry(tableGovernments[:], u.govInt)},
+		{"Law level", Ehex(u.lawInt).String(), tableEntry(tableLawLevels[:], u.lawInt)},
+		{"Tech level", Ehex(u.techInt).String(), tableEntry(tableTechLevels[:], u.techInt)},
+	}
+}
+
+// atlasDetails lists the world's other details, leaving out those it does not have.
+func atlasDetails(w *world) (ds []atlasDigit) {
+	add := func(name, value string) {
+		if strings.TrimSpace(value) != "" {
+			ds = append(ds, atlasDigit{Name: name, Code: value})
+		}
+	}
+	add("Bases", w.bases)
+	add("Remarks", w.remarks)
+	add("Travel zone", w.zone.Desc())
+	add("PBG", w.pbg.String())
+	add("Allegiance", w.allegiance)
+	if w.genType == WgtT5ss {
+		add("Stars", w.systemStarString())
+		add("Importance", w.importance.String())
+		add("Economics", w.economics.String())
+		add("Culture", w.culture.String())
+		add("Nobility", w.nobility)
+		add("Worlds", fmt.Sprint(w.worlds))
+		add("Resource units", fmt.Sprint(w.ru))
+	}
+	return
+}
+
+// worldNotes reads the referee's notes for the world, from a text file named after its sector and hex, eg
+// "Foreven 0101.txt". Paragraphs are separated by blank lines. There are no notes if there is no file.
+func worldNotes(dir, sectorName string, w *world) (ps []string) {
+	if dir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("%s %s.txt", sectorName, w.hexLoc.String())))
+	if err != nil {
+		return nil
+	}
+	for _, p := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			ps = append(ps, p)
+		}
+	}
+	return
+}
+
+// storedSystem returns the listing of the world's system, if one has been generated and stored for it.
+func storedSystem(w *world) string {
+	if w.id <= 0 {
+		return ""
+	}
+	sys, err := repo.getSystemDetail(context.Background(), w.id)
+	if err != nil {
+		if !errors.Is(err, ErrSystemNotFound) {
+			log.Printf("Unable to get the system of %s: %v", w.name, err)
+		}
+		return ""
+	}
+	return sys.String()
+}
+
+// atlasNeighbours returns links to the worlds of the sector within atlasNeighbourRange parsecs of the world, nearest
+// first.
+func atlasNeighbours(s *sector, w *world) (ls []atlasLink) {
+	type near struct {
+		w *world
+		d int
+	}
+	var ns []near
+	for i := range s.worlds {
+		o := &s.worlds[i]
+		if d := Distance(w.hexLoc, o.hexLoc); d > 0 && d <= atlasNeighbourRange {
+			ns = append(ns, near{o, d})
+		}
+	}
+	sort.Slice(ns, func(i, j int) bool {
+		if ns[i].d != ns[j].d {
+			return ns[i].d < ns[j].d
+		}
+		return ns[i].w.hexLoc.String() < ns[j].w.hexLoc.String()
+	})
+	for _, n := range ns {
+		ls = append(ls, atlasLink{Href: atlasWorldHref(n.w), Text: n.w.name,
+			Info: fmt.Sprintf("%s %s, %d parsec", n.w.hexLoc.String(), n.w.uwp.String(), n.d)})
+		if n.d > 1 {
+			ls[len(ls)-1].Info += "s"
+		}
+	}
+	return
+}
+
+// atlasMap returns the map as inline SVG, with each world's hex linking to its page.
+func atlasMap(m *hexMap) (template.HTML, error) {
+	var b bytes.Buffer
+	if err := m.writeLinkedSVG(&b, atlasWorldHref); err != nil {
+		return "", err
+	}
+	return template.HTML(b.String()), nil
+}
+
+// writeAtlasPage writes the page to the file, with the named template.
+func writeAtlasPage(filename, tmpl string, p *atlasPage) error {
+	var b bytes.Buffer
+	if err := atlasTemplates.ExecuteTemplate(&b, tmpl, p); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, b.Bytes(), 0644)
+}
+
+// writeAtlasSector writes the pages for the sector to its directory, and returns the sector's entries in the search
+// index.
+func writeAtlasSector(opts *atlasOptions, s *sector) (index []atlasSearchEntry, err error) {
+	slug := atlasSlug(s.name)
+	dir := filepath.Join(opts.Dir, slug)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	sectorCrumb := atlasLink{Href: "index.html", Text: s.name + " Sector"}
+
+	// The sector page, with the sector map and the subsectors.
+	page := &atlasPage{Title: s.name + " Sector", Campaign: opts.Campaign, Root: "../"}
+	if page.Map, err = atlasMap(newHexMap(s, scopeSector, 0, opts.Style, 16)); err != nil {
+		return nil, err
+	}
+	subsectorWorlds := make([][]*world, len(ssIndex))
+	for i := range s.worlds {
+		if ss := s.worlds[i].hexLoc.IntIndex(); ss >= 0 && ss < len(ssIndex) {
+			subsectorWorlds[ss] = append(subsectorWorlds[ss], &s.worlds[i])
+		}
+	}
+	for i := range ssIndex {
+		m := newHexMap(s, scopeSubsector, i, opts.Style, 32)
+		page.Links = append(page.Links, atlasLink{Href: atlasSubsectorHref(i), Text: m.title(),
+			Info: fmt.Sprintf("%d worlds", len(subsectorWorlds[i]))})
+	}
+	if err = writeAtlasPage(filepath.Join(dir, "index.html"), "sector", page); err != nil {
+		return nil, err
+	}
+
+	// A page for each subsector, with its map and world listing, linking to the subsectors either side.
+	for i := range ssIndex {
+		m := newHexMap(s, scopeSubsector, i, opts.Style, 32)
+		page := &atlasPage{Title: m.title(), Campaign: opts.Campaign, Root: "../", Crumbs: []atlasLink{sectorCrumb}}
+		if page.Map, err = atlasMap(m); err != nil {
+			return nil, err
+		}
+		ws := subsectorWorlds[i]
+		sort.Slice(ws, func(a, b int) bool { return ws[a].hexLoc.String() < ws[b].hexLoc.String() })
+		for _, w := range ws {
+			page.Rows = append(page.Rows, atlasRowFor(w))
+		}
+		if i > 0 {
+			page.Links = append(page.Links, atlasLink{Href: atlasSubsectorHref(i - 1), Text: "Previous subsector"})
+		}
+		if i < len(ssIndex)-1 {
+			page.Links = append(page.Links, atlasLink{Href: atlasSubsectorHref(i + 1), Text: "Next subsector"})
+		}
+		if err = writeAtlasPage(filepath.Join(dir, atlasSubsectorHref(i)), "subsector", page); err != nil {
+			return nil, err
+		}
+	}
+
+	// A page for each world.
+	for i := range s.worlds {
+		w := &s.worlds[i]
+		ss := w.hexLoc.IntIndex()
+		page := &atlasPage{Title: w.name, Campaign: opts.Campaign, Root: "../", World: atlasRowFor(w),
+			Description: describeWorld(w), Digits: atlasDigits(w), Details: atlasDetails(w), System: storedSystem(w),
+			Notes: worldNotes(opts.NotesDir, s.name, w), Neighbours: atlasNeighbours(s, w)}
+		page.Crumbs = []atlasLink{sectorCrumb}
+		if ss >= 0 && ss < len(ssIndex) {
+			page.Crumbs = append(page.Crumbs, atlasLink{Href: atlasSubsectorHref(ss),
+				Text: newHexMap(s, scopeSubsector, ss, opts.Style, 32).title()})
+		}
+		if err = writeAtlasPage(filepath.Join(dir, atlasWorldHref(w)), "world", page); err != nil {
+			return nil, err
+		}
+		index = append(index, atlasSearchEntry{Name: w.name, Sector: s.name, Hex: w.hexLoc.String(), UWP: w.uwp.String(),
+			Remarks: w.remarks, Path: slug + "/" + atlasWorldHref(w)})
+	}
+	return index, nil
+}
+
+// writeAtlas writes the atlas of the campaign's sectors to the directory in the options, and returns the numbers of
+// sectors and worlds written. Sectors that cannot be loaded are logged and left out, and the rest of the atlas is
+// still written, but ErrAtlasIncomplete is returned naming them.
+func writeAtlas(opts atlasOptions) (sectors, worlds int, err error) {
+	if err = os.MkdirAll(opts.Dir, 0755); err != nil {
+		return 0, 0, err
+	}
+	if opts.Campaign == "" {
+		opts.Campaign = "Atlas"
+	}
+
+	var index []atlasSearchEntry
+	page := &atlasPage{Title: opts.Campaign, Campaign: opts.Campaign, Root: ""}
+	var skipped []string
+	for _, name := range opts.Sectors {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		s, err := loadCampaignSector(name, opts.Campaign)
+		if err == nil {
+			var entries []atlasSearchEntry
+			if entries, err = writeAtlasSector(&opts, s); err == nil {
+				index = append(index, entries...)
+				page.Links = append(page.Links, atlasLink{Href: atlasSlug(s.name) + "/index.html", Text: s.name + " Sector",
+					Info: fmt.Sprintf("%d worlds", len(s.worlds))})
+				continue
+			}
+		}
+		log.Printf("Unable to write the atlas pages for %s: %v", name, err)
+		skipped = append(skipped, name)
+	}
+	if len(page.Links) == 0 {
+		return 0, 0, ErrAtlasEmpty
+	}
+
+	js, err := json.Marshal(index)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{"style.css", []byte(atlasStyle)}, {"search.js", []byte(atlasSearch)},
+		{"search-index.js", append(append([]byte("var atlasIndex = "), js...), ";\n"...)}} {
+		if err = os.WriteFile(filepath.Join(opts.Dir, f.name), f.data, 0644); err != nil {
+			return 0, 0, err
+		}
+	}
+	if err = writeAtlasPage(filepath.Join(opts.Dir, "index.html"), "index", page); err != nil {
+		return 0, 0, err
+	}
+
+	log.Printf("Written %d sectors and %d worlds to %s", len(page.Links), len(index), opts.Dir)
+	if len(skipped) > 0 {
+		err = fmt.Errorf("%w: %s (see the log)", ErrAtlasIncomplete, strings.Join(skipped, ", "))
+	}
+	return len(page.Links), len(index), err
+}
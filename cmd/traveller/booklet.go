@@ -0,0 +1,355 @@
+package main
+
+// booklet.go contains code for writing printable referee booklets, in the style of the classic Traveller little
+// black books, as PDF files. A booklet covers a sector, quadrant or subsector, with a hex map and a world listing in
+// Book 3 format for each subsector, a description of each world with room for notes, and a key to the UWP. See the
+// pdf package for the file format.
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"sort"
+	"strings"
+	"trav2/cmd/traveller/pdf"
+)
+
+// Sizes used to lay out booklet pages, in points.
+const (
+	bookletMargin   = 36 // The margin around each page
+	bookletTitle    = 14 // The size of page titles
+	bookletHeading  = 10 // The size of world names and key headings
+	bookletText     = 8  // The size of descriptions
+	bookletListing  = 7  // The size of the world listing
+	bookletNoteRows = 3  // The number of ruled lines for notes about each world
+)
+
+// bookletInk is the colour text and lines are printed in.
+var bookletInk = color.RGBA{A: 0xff}
+
+// pdfCanvas draws a hex map on a PDF page, scaled and moved to fit a box on the page.
+type pdfCanvas struct {
+	page     *pdf.Page
+	x, y     float64 // The top left of the map on the page
+	scale    float64 // Points per pixel of the map
+	hasFill  bool    // Whether a fill colour has been set
+	fill     color.RGBA
+	hasPen   bool // Whether a stroke colour has been set
+	pen      color.RGBA
+	penWidth float64
+	penDash  bool
+}
+
+// point returns the point of the map on the page.
+func (c *pdfCanvas) point(p mapPoint) (x, y float64) {
+	return c.x + p.X*c.scale, c.y + p.Y*c.scale
+}
+
+// setFill sets the fill colour, if it has changed.
+func (c *pdfCanvas) setFill(col color.RGBA) {
+	if !c.hasFill || col != c.fill {
+		c.page.SetFill(col)
+		c.hasFill, c.fill = true, col
+	}
+}
+
+// setPen sets the stroke colour and width, if they have changed.
+func (c *pdfCanvas) setPen(col color.RGBA, width float64, dashed bool) {
+	width *= c.scale
+	if !c.hasPen || col != c.pen || width != c.penWidth || dashed != c.penDash {
+		c.page.SetStroke(col, width, dashed)
+		c.hasPen, c.pen, c.penWidth, c.penDash = true, col, width, dashed
+	}
+}
+
+func (c *pdfCanvas) polygon(pts []mapPoint, fill, stroke color.RGBA, width float64) {
+	ps := make([][2]float64, len(pts))
+	for i, p := range pts {
+		ps[i][0], ps[i][1] = c.point(p)
+	}
+	if fill.A > 0 {
+		c.setFill(fill)
+	}
+	if stroke.A > 0 {
+		c.setPen(stroke, width, false)
+	}
+	c.page.Polygon(ps, fill.A > 0, stroke.A > 0)
+}
+
+func (c *pdfCanvas) line(a, b mapPoint, col color.RGBA, width float64, dashed bool) {
+	c.setPen(col, width, dashed)
+	x1, y1 := c.point(a)
+	x2, y2 := c.point(b)
+	c.page.Line(x1, y1, x2, y2)
+}
+
+func (c *pdfCanvas) circle(ctr mapPoint, r float64, fill, stroke color.RGBA, width float64) {
+	if fill.A > 0 {
+		c.setFill(fill)
+	}
+	if stroke.A > 0 {
+		c.setPen(stroke, width, false)
+	}
+	x, y := c.point(ctr)
+	c.page.Circle(x, y, r*c.scale, fill.A > 0, stroke.A > 0)
+}
+
+func (c *pdfCanvas) text(p mapPoint, size float64, col color.RGBA, s string) {
+	c.setFill(col)
+	x, y := c.point(p)
+	size *= c.scale
+	c.page.Text(x-pdf.TextWidth(pdf.Helvetica, size, s)/2, y+0.35*size, pdf.Helvetica, size, s)
+}
+
+// booklet is a booklet being written, a page at a time.
+type booklet struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64 // How far down the page has been written, in points
+}
+
+// newPage starts a new page.
+func (b *booklet) newPage() {
+	b.page = b.doc.AddPage(pdf.Digest)
+	b.page.SetFill(bookletInk)
+	b.page.SetStroke(bookletInk, 0.5, false)
+	b.y = bookletMargin
+}
+
+// need starts a new page if there is not the height left on this one.
+func (b *booklet) need(height float64) {
+	if _, h := b.page.Size(); b.y+height > h-bookletMargin {
+		b.newPage()
+	}
+}
+
+// line writes a line of text in the font, starting a new page if need be.
+func (b *booklet) line(f pdf.Font, size float64, s string) {
+	b.need(size * 1.3)
+	b.y += size * 1.3
+	b.page.Text(bookletMargin, b.y, f, size, s)
+}
+
+// paragraph writes the text in the font, wrapped to the width of the page, and leaves a gap after it.
+func (b *booklet) paragraph(f pdf.Font, size float64, s string) {
+	w, _ := b.page.Size()
+	width := w - 2*bookletMargin
+	var line string
+	for _, word := range strings.Fields(s) {
+		next := strings.TrimSpace(line + " " + word)
+		if line != "" && pdf.TextWidth(f, size, next) > width {
+			b.line(f, size, line)
+			next = word
+		}
+		line = next
+	}
+	if line != "" {
+		b.line(f, size, line)
+	}
+	b.y += size / 2
+}
+
+// title starts a new page with the title at the top.
+func (b *booklet) title(s string) {
+	b.newPage()
+	b.line(pdf.HelveticaBold, bookletTitle, s)
+	b.y += bookletTitle / 2
+}
+
+// drawMap draws the map to fill the rest of the page.
+func (b *booklet) drawMap(m *hexMap) {
+	w, h := b.page.Size()
+	mw, mh := m.size()
+	scale := minFloat((w-2*bookletMargin)/mw, (h-bookletMargin-b.y)/mh)
+	m.draw(&pdfCanvas{page: b.page, x: (w - mw*scale) / 2, y: b.y, scale: scale})
+	b.page.SetFill(bookletInk)
+	b.page.SetStroke(bookletInk, 0.5, false)
+	b.y += mh * scale
+}
+
+// minFloat returns the smaller of two floats.
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// bookletListingHeader is the header of the world listing, lined up with bookletListingLine.
+const bookletListingHeader = "Name             Hex  UWP       B  Remarks              Z PBG Al"
+
+// bookletListingLine returns the world as a line of the world listing, in Book 3 format.
+func bookletListingLine(w *world) string {
+	return fmt.Sprintf("%-16.16s %s %s %-2.2s %-20.20s %-1.1s %s %-4.4s", w.name, w.hexLoc.String(), w.uwp.String(),
+		w.bases, w.remarks, w.zone.String(), w.pbg.String(), w.allegiance)
+}
+
+// describeWorld describes the world in words, from its UWP, bases, trade codes and zone.
+func describeWorld(w *world) string {
+	u := w.uwp
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s. %s, %s atmosphere, %s.", tableStarport(u.starport), tableEntry(tableSizes[:], u.sizeInt),
+		strings.ToLower(tableEntry(tableAtmospheres[:], u.atmInt)), strings.ToLower(tableEntry(tableHydrographics[:], u.hydInt)))
+	if u.popInt > 0 {
+		fmt.Fprintf(&sb, " Population in the %s. %s. Law level %s: %s.",
+			strings.ToLower(tableEntry(tablePopulations[:], u.popInt)),
+			tableEntry(tableGovernments[:], u.govInt), Ehex(u.lawInt).String(),
+			strings.ToLower(tableEntry(tableLawLevels[:], u.lawInt)))
+		fmt.Fprintf(&sb, " Tech level %s (%s).", Ehex(u.techInt).String(), strings.ToLower(tableEntry(tableTechLevels[:], u.techInt)))
+	} else {
+		sb.WriteString(" Uninhabited.")
+	}
+	var bases []string
+	for _, r := range w.bases {
+		if d, ok := tableBases[r]; ok {
+			bases = append(bases, d)
+		}
+	}
+	if len(bases) > 0 {
+		fmt.Fprintf(&sb, " %s.", strings.Join(bases, ", "))
+	}
+	var codes []string
+	for _, tc := range strings.Fields(w.remarks) {
+		if d, ok := tableTradeCodes[tc]; ok {
+			codes = append(codes, d)
+		} else {
+			codes = append(codes, tc)
+		}
+	}
+	if len(codes) > 0 {
+		fmt.Fprintf(&sb, " %s.", strings.Join(codes, ", "))
+	}
+	if w.pbg.gasGiants > 0 {
+		fmt.Fprintf(&sb, " Gas giants: %d.", w.pbg.gasGiants)
+	}
+	if w.zone == TzAmber || w.zone == TzRed {
+		fmt.Fprintf(&sb, " %s zone.", w.zone.Desc())
+	}
+	return sb.String()
+}
+
+// subsectorPages writes the pages for a subsector: its map, its world listing, and a description of each world
+// with room for notes.
+func (b *booklet) subsectorPages(s *sector, idx int, style hexMapStyle) {
+	m := newHexMap(s, scopeSubsector, idx, style, 40)
+	var ws []*world
+	for i := range s.worlds {
+		if s.worlds[i].hexLoc.IntIndex() == idx {
+			ws = append(ws, &s.worlds[i])
+		}
+	}
+	sort.Slice(ws, func(i, j int) bool { return ws[i].hexLoc.String() < ws[j].hexLoc.String() })
+
+	b.title(m.title())
+	b.drawMap(m)
+
+	b.title(m.title() + ": Worlds")
+	b.line(pdf.CourierBold, bookletListing, bookletListingHeader)
+	for _, w := range ws {
+		b.line(pdf.Courier, bookletListing, bookletListingLine(w))
+	}
+
+	b.title(m.title() + ": Descriptions")
+	wide, _ := b.page.Size()
+	for _, w := range ws {
+		b.need(bookletHeading*1.3 + bookletText*4 + float64(bookletNoteRows)*bookletText*2)
+		b.line(pdf.HelveticaBold, bookletHeading, fmt.Sprintf("%s (%s) %s", w.name, w.hexLoc.String(), w.uwp.String()))
+		b.paragraph(pdf.Helvetica, bookletText, describeWorld(w))
+		b.line(pdf.HelveticaBold, bookletText, "Notes:")
+		for i := 0; i < bookletNoteRows; i++ {
+			b.y += bookletText * 2
+			b.page.Line(bookletMargin, b.y, wide-bookletMargin, b.y)
+		}
+		b.y += bookletText
+	}
+}
+
+// keyPages writes the key to the UWP.
+func (b *booklet) keyPages() {
+	b.title("Universal World Profile Key")
+	b.paragraph(pdf.Helvetica, bookletText, "A UWP such as A788899-C gives the starport, size, atmosphere, "+
+		"hydrographics, population, government and law level, then the tech level.")
+	// Each section lists the descriptions of a digit, labelled with the codes, or the digits if there are none.
+	section := func(heading string, codes, entries []string) {
+		b.need(bookletHeading*1.3 + 3*bookletText*1.3)
+		b.y += bookletText / 2
+		b.line(pdf.HelveticaBold, bookletHeading, heading)
+		for i, e := range entries {
+			code := Ehex(i).String()
+			if codes != nil {
+				code = codes[i]
+			}
+			b.line(pdf.Helvetica, bookletText, fmt.Sprintf("%s  %s", code, e))
+		}
+	}
+	ports := []string{"A", "B", "C", "D", "E", "X"}
+	var portEntries []string
+	for _, p := range ports {
+		portEntries = append(portEntries, tableStarport(p))
+	}
+	section("Starport", ports, portEntries)
+	section("Size", nil, tableSizes[:])
+	section("Atmosphere", nil, tableAtmospheres[:])
+	section("Hydrographics", nil, tableHydrographics[:])
+	section("Population", nil, tablePopulations[:])
+	section("Government", nil, tableGovernments[:])
+	section("Law Level", nil, tableLawLevels[:])
+	section("Tech Level", nil, tableTechLevels[:])
+}
+
+// writeBooklet writes a booklet for the part of the sector shown by the map, to the named PDF file. There is an
+// overview map first for a sector or quadrant, then the pages of each subsector with worlds, then the UWP key. The
+// maps are drawn in the map's style.
+func writeBooklet(m *hexMap, filename string) error {
+	s := m.sector
+	b := &booklet{doc: pdf.New(m.title())}
+
+	// The cover.
+	worlds := 0
+	for i := range s.worlds {
+		if m.shows(s.worlds[i].hexLoc.x, s.worlds[i].hexLoc.y) {
+			worlds++
+		}
+	}
+	b.newPage()
+	b.y = 200
+	b.line(pdf.HelveticaBold, 2*bookletTitle, m.title())
+	b.y += bookletTitle
+	b.paragraph(pdf.Helvetica, bookletHeading, fmt.Sprintf("Referee's booklet of %d worlds, with maps, "+
+		"world listings, descriptions and notes.", worlds))
+	for _, idx := range m.subsectorIndexes() {
+		if name := s.subsectors[idx].name; name != "" {
+			b.line(pdf.Helvetica, bookletHeading, fmt.Sprintf("%s  %s", ssIndex[idx], name))
+		}
+	}
+
+	if m.scope != scopeSubsector {
+		b.title(m.title())
+		b.drawMap(m)
+	}
+	for _, idx := range m.subsectorIndexes() {
+		hasWorlds := false
+		for i := range s.worlds {
+			if s.worlds[i].hexLoc.IntIndex() == idx {
+				hasWorlds = true
+				break
+			}
+		}
+		if hasWorlds {
+			b.subsectorPages(s, idx, m.style)
+		}
+	}
+	b.keyPages()
+
+	if err := b.doc.WriteFile(filename); err != nil {
+		log.Printf("Unable to write the booklet %s: %v", filename, err)
+		return err
+	}
+	log.Printf("Booklet written to file: %s", filename)
+	return nil
+}
+
+// bookletFilename returns the default filename for a booklet of the map, eg "Foreven subsector A booklet.pdf".
+func bookletFilename(m *hexMap) string {
+	return m.baseName() + " booklet.pdf"
+}
@@ -0,0 +1,505 @@
+package main
+
+// challenge25.go contains a port of the Traveller Sector Generator from Challenge Magazine #25 (1986), an
+// Applesoft BASIC program (see internal/data/sectorgenerator.bas). The port keeps the program's tables and logic,
+// including its quirks (for example hydrographics always being reduced by 4, and A class starports never having
+// naval bases), so that it gives the same distributions as the original. Obvious slips in the printed listing,
+// such as "l" for "1", "S" for "$" and "0" for "O", are read as the letters intended.
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"trav2/cmd/traveller/tools"
+)
+
+// c25Hex is the hexadecimal (extended) digits used to print the UPP, as in the original listing.
+const c25Hex = "0123456789ABCDEFGHJKLJ"
+
+// c25Starports is the starport table. It is indexed from 1 by a 2D roll, so the first "A" is never used.
+const c25Starports = "AAAABBCCDEEX"
+
+// c25VerifyWorlds is the number of worlds generated when verifying the generator.
+const c25VerifyWorlds = 20000
+
+// c25Allegiance is the allegiance given to every world by the original program.
+const c25Allegiance = "IM"
+
+// Travel zones for the Challenge #25 generator.
+const (
+	c25ZoneNone  = 0
+	c25ZoneAmber = 1
+	c25ZoneRed   = 2
+)
+
+// c25World contains a world generated by the Challenge #25 sector generator, with the same fields as the output
+// records of the original program.
+type c25World struct {
+	X, Y          int      // The hex location within the sector
+	Starport      string   // The starport, A to E or X
+	Size          int      // The UPP digits
+	Atmosphere    int      //
+	Hydrographics int      //
+	Population    int      //
+	Government    int      //
+	LawLevel      int      //
+	TechLevel     int      //
+	NavalBase     bool     // Whether there is a naval base
+	ScoutBase     bool     // Whether there is a scout base
+	Trade         []string // The trade classifications, in upper case as printed, eg "AG"
+	Allegiance    string   // The allegiance code
+	Zone          int      // The travel zone, one of the c25Zone constants
+	GasGiant      bool     // Whether there is a gas giant in the system
+}
+
+// c25Roll2D rolls 2D, as FN B in the original program.
+func c25Roll2D(r *rand.Rand) int {
+	return tools.RollD6(r) + tools.RollD6(r)
+}
+
+// c25HasWorld rolls to see whether a hex contains a world (lines 2100 to 2110).
+func c25HasWorld(r *rand.Rand) bool {
+	return tools.RollD6(r) >= 4
+}
+
+// generateC25World generates a world in the hex at x, y, following lines 2120 to 2830 of the original program.
+func generateC25World(r *rand.Rand, x, y int, allegiance string) (w c25World) {
+	w.X, w.Y, w.Allegiance = x, y, allegiance
+
+	// Generate UPP
+	w.Starport = string(c25Starports[c25Roll2D(r)-1])
+	w.Size = c25Roll2D(r) - 2
+	w.Atmosphere = c25Roll2D(r) - 7 + w.Size
+	if w.Size == 0 || w.Atmosphere < 0 {
+		w.Atmosphere = 0
+	}
+	w.Hydrographics = c25Roll2D(r) - 7 + w.Size
+	if w.Size < 2 {
+		w.Hydrographics = 0
+	}
+	// The original tests "AM>2 OR AM<9", which is always true.
+	w.Hydrographics = clampInt(w.Hydrographics-4, 0, 10)
+	w.Population = c25Roll2D(r) - 2
+	w.Government = clampInt(c25Roll2D(r)-7+w.Population, 0, math.MaxInt32)
+	w.LawLevel = clampInt(c25Roll2D(r)-7+w.Government, 0, math.MaxInt32)
+	w.TechLevel = c25TechLevel(w)
+
+	// Gas giant
+	w.GasGiant = c25Roll2D(r) < 10
+
+	// Travel zones
+	if w.Starport == "X" {
+		w.Zone = c25ZoneRed
+	} else if c25Roll2D(r) < 10 {
+		w.Zone = c25ZoneAmber
+	}
+
+	// Bases. A class starports skip the naval base roll, and E and X skip the scout base roll.
+	if w.Starport >= "B" {
+		w.NavalBase = c25Roll2D(r) > 7
+	}
+	if w.Starport <= "D" {
+		if c25Roll2D(r)+c25ScoutDM(w.Starport) < 6 {
+			w.ScoutBase = true
+		}
+	}
+
+	w.Trade = c25TradeClassifications(w)
+	return
+}
+
+// c25TechLevel works out the tech level from the starport and the UPP (lines 2240 to 2370).
+func c25TechLevel(w c25World) (tl int) {
+	switch w.Starport {
+	case "A":
+		tl += 6
+	case "B":
+		tl += 4
+	case "C":
+		tl += 2
+	case "X":
+		tl -= 4
+	}
+	if w.Size < 5 {
+		tl--
+		if w.Size < 2 {
+			tl--
+		}
+	}
+	if w.Atmosphere < 4 {
+		tl++
+	}
+	if w.Atmosphere > 9 {
+		tl++
+	}
+	if w.Hydrographics > 8 {
+		tl++
+		if w.Hydrographics > 9 {
+			tl++
+		}
+	}
+	if w.Population > 0 && w.Population < 6 {
+		tl++
+	}
+	if w.Population > 8 {
+		tl += 2
+		if w.Population > 9 {
+			tl += 2
+		}
+	}
+	if w.Government == 0 || w.Government == 5 {
+		tl++
+	}
+	if w.Government == 13 {
+		tl -= 2
+	}
+	return clampInt(tl, 0, math.MaxInt32)
+}
+
+// c25ScoutDM returns the DM to the scout base roll for the starport.
+func c25ScoutDM(starport string) int {
+	switch starport {
+	case "A", "C":
+		return -3
+	case "B":
+		return -2
+	}
+	return 0
+}
+
+// c25TradeClassifications works out the trade classifications (lines 2700 to 2820). Some of the conditions in the
+// original can never be true, so Ri and Po are never given, and In is only given for atmospheres 0-2, 4 and 7.
+func c25TradeClassifications(w c25World) (tc []string) {
+	am, hy, po, gov := w.Atmosphere, w.Hydrographics, w.Population, w.Government
+	if am > 3 && am < 10 && hy > 3 && hy < 5 && po > 4 && po < 8 {
+		tc = append(tc, "AG")
+	}
+	if am < 4 && hy < 4 && po > 5 {
+		tc = append(tc, "NA")
+	}
+	if (am < 3 || am == 4 || am == 7) && po > 8 {
+		tc = append(tc, "IN")
+	}
+	if po < 7 {
+		tc = append(tc, "NI")
+	}
+	if (am == 6 || am == 8) && po > 5 && po < 9 && gov < 3 && gov > 10 {
+		tc = append(tc, "RI")
+	}
+	if am < 1 && am > 6 && hy < 4 {
+		tc = append(tc, "PO")
+	}
+	if hy == 10 {
+		tc = append(tc, "WA")
+	}
+	if hy == 0 && am > 1 {
+		tc = append(tc, "DE")
+	}
+	if w.Size == 0 {
+		tc = append(tc, "AS")
+	}
+	if am == 0 && w.Size > 0 {
+		tc = append(tc, "VA")
+	}
+	if po == 0 && gov == 0 && w.LawLevel == 0 {
+		tc = append(tc, "BA")
+	}
+	return
+}
+
+// clampInt limits i to between min and max.
+func clampInt(i, min, max int) int {
+	if i < min {
+		return min
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
+
+// c25Digit returns the UPP digit for the value, as MID$(HX$, value+1, 1) in the original.
+func c25Digit(v int) string {
+	return string(c25Hex[clampInt(v, 0, len(c25Hex)-1)])
+}
+
+// bases returns the base code for the world: N for naval, S for scout, A for both, or a space for none.
+func (w c25World) bases() string {
+	switch {
+	case w.NavalBase && w.ScoutBase:
+		return "A"
+	case w.NavalBase:
+		return "N"
+	case w.ScoutBase:
+		return "S"
+	}
+	return " "
+}
+
+// upp returns the UPP for the world, eg "A788899-C".
+func (w c25World) upp() string {
+	return w.Starport + c25Digit(w.Size) + c25Digit(w.Atmosphere) + c25Digit(w.Hydrographics) + c25Digit(w.Population) +
+		c25Digit(w.Government) + c25Digit(w.LawLevel) + "-" + c25Digit(w.TechLevel)
+}
+
+// String returns the world as a record laid out exactly as the original program writes it (lines 3010 to 3160).
+func (w c25World) String() string {
+	tc := " "
+	for _, t := range w.Trade {
+		tc += t + " "
+	}
+	if tc += " "; len(tc) > 15 {
+		tc = tc[:15]
+	}
+	str := fmt.Sprintf(" %02d%02d %s %s %s%s ", w.X, w.Y, w.upp(), w.bases(), tc, w.Allegiance)
+	switch w.Zone {
+	case c25ZoneAmber:
+		str += "A "
+	case c25ZoneRed:
+		str += "R "
+	default:
+		str += " "
+	}
+	if w.GasGiant {
+		return str + "G "
+	}
+	return str + " "
+}
+
+// toWorld converts the Challenge #25 world into a world in the named sector.
+func (w c25World) toWorld(sectorName string) (wld world) {
+	wld.genType = WgtMtBasic
+	wld.name = unnamed
+	wld.hexLoc = HexLoc{x: w.X, y: w.Y, sector: true}
+	wld.subsectorIndex = wld.hexLoc.GetIndex()
+	wld.sector = sectorName
+	wld.sectorAbbrev = getAbbreviationForSector(sectorName)
+	wld.uwp = parseUwp(w.upp())
+	switch {
+	case w.NavalBase && w.ScoutBase:
+		wld.bases = "NS"
+	case w.NavalBase:
+		wld.bases = "N"
+	case w.ScoutBase:
+		wld.bases = "S"
+	}
+	var remarks []string
+	for _, t := range w.Trade {
+		remarks = append(remarks, t[:1]+strings.ToLower(t[1:]))
+	}
+	wld.remarks = strings.Join(remarks, " ")
+	wld.zone = TzGreen
+	switch w.Zone {
+	case c25ZoneAmber:
+		wld.zone = TzAmber
+	case c25ZoneRed:
+		wld.zone = TzRed
+	}
+	if w.GasGiant {
+		wld.pbg.gasGiants = 1
+	}
+	wld.allegiance = w.Allegiance
+	return
+}
+
+// c25Distribution contains the expected and observed frequencies for one field of the generated worlds.
+type c25Distribution struct {
+	Name     string    // The name of the field, eg "Size"
+	Labels   []string  // The label for each value, eg "A"
+	Expected []float64 // The expected probability of each value, from the original tables
+	Observed []float64 // The observed frequency of each value
+}
+
+// c25Dice contains the probabilities of rolling 2 to 12 on 2D, indexed by the roll.
+var c25Dice = [13]float64{0, 0, 1.0 / 36, 2.0 / 36, 3.0 / 36, 4.0 / 36, 5.0 / 36, 6.0 / 36, 5.0 / 36, 4.0 / 36, 3.0 / 36, 2.0 / 36, 1.0 / 36}
+
+// Indexes of the Challenge #25 distributions.
+const (
+	c25dStarport = iota
+	c25dSize
+	c25dAtmosphere
+	c25dHydrographics
+	c25dPopulation
+	c25dGovernment
+	c25dLawLevel
+	c25dTechLevel
+	c25dBases
+	c25dZone
+	c25dGasGiant
+	c25dCount
+)
+
+// newC25Distributions returns the (empty) distributions for the Challenge #25 world fields.
+func newC25Distributions() []c25Distribution {
+	digits := make([]string, len(c25Hex))
+	for i := range digits {
+		digits[i] = fmt.Sprintf("%s (%d)", c25Digit(i), i)
+	}
+	ds := []c25Distribution{
+		{Name: "Starport", Labels: []string{"A", "B", "C", "D", "E", "X"}},
+		{Name: "Size", Labels: digits},
+		{Name: "Atmosphere", Labels: digits},
+		{Name: "Hydrographics", Labels: digits},
+		{Name: "Population", Labels: digits},
+		{Name: "Government", Labels: digits},
+		{Name: "Law Level", Labels: digits},
+		{Name: "Tech Level", Labels: digits},
+		{Name: "Bases", Labels: []string{"None", "N", "S", "A"}},
+		{Name: "Travel Zone", Labels: []string{"None", "Amber", "Red"}},
+		{Name: "Gas Giant", Labels: []string{"No", "Yes"}},
+	}
+	for i := range ds {
+		ds[i].Expected = make([]float64, len(ds[i].Labels))
+		ds[i].Observed = make([]float64, len(ds[i].Labels))
+	}
+	return ds
+}
+
+// c25Index returns the index of a world's value in each of the distributions.
+func (w c25World) c25Index() (idx [c25dCount]int) {
+	idx[c25dStarport] = strings.Index("ABCDEX", w.Starport)
+	idx[c25dSize] = clampInt(w.Size, 0, len(c25Hex)-1)
+	idx[c25dAtmosphere] = clampInt(w.Atmosphere, 0, len(c25Hex)-1)
+	idx[c25dHydrographics] = clampInt(w.Hydrographics, 0, len(c25Hex)-1)
+	idx[c25dPopulation] = clampInt(w.Population, 0, len(c25Hex)-1)
+	idx[c25dGovernment] = clampInt(w.Government, 0, len(c25Hex)-1)
+	idx[c25dLawLevel] = clampInt(w.LawLevel, 0, len(c25Hex)-1)
+	idx[c25dTechLevel] = clampInt(w.TechLevel, 0, len(c25Hex)-1)
+	idx[c25dBases] = strings.Index(" NSA", w.bases())
+	idx[c25dZone] = w.Zone
+	if w.GasGiant {
+		idx[c25dGasGiant] = 1
+	}
+	return
+}
+
+// c25ExpectedDistributions works out the exact probabilities of each value from the original tables, by going
+// through every combination of dice rolls.
+func c25ExpectedDistributions() []c25Distribution {
+	ds := newC25Distributions()
+	add := func(d, i int, p float64) {
+		ds[d].Expected[clampInt(i, 0, len(ds[d].Expected)-1)] += p
+	}
+
+	// The starport, bases and zone depend only on the starport roll.
+	for st := 2; st <= 12; st++ {
+		w := c25World{Starport: string(c25Starports[st-1])}
+		p := c25Dice[st]
+		add(c25dStarport, strings.Index("ABCDEX", w.Starport), p)
+		naval := 0.0
+		if w.Starport >= "B" {
+			naval = 15.0 / 36
+		}
+		scout := 0.0
+		if w.Starport <= "D" {
+			for r := 2; r <= 12; r++ {
+				if r+c25ScoutDM(w.Starport) < 6 {
+					scout += c25Dice[r]
+				}
+			}
+		}
+		add(c25dBases, 0, p*(1-naval)*(1-scout))
+		add(c25dBases, 1, p*naval*(1-scout))
+		add(c25dBases, 2, p*(1-naval)*scout)
+		add(c25dBases, 3, p*naval*scout)
+		if w.Starport == "X" {
+			add(c25dZone, c25ZoneRed, p)
+		} else {
+			add(c25dZone, c25ZoneAmber, p*30/36)
+			add(c25dZone, c25ZoneNone, p*6/36)
+		}
+	}
+	add(c25dGasGiant, 1, 30.0/36)
+	add(c25dGasGiant, 0, 6.0/36)
+
+	// The government and law level depend only on the population.
+	for po := 2; po <= 12; po++ {
+		add(c25dPopulation, po-2, c25Dice[po])
+		for gr := 2; gr <= 12; gr++ {
+			gov := clampInt(gr-7+po-2, 0, math.MaxInt32)
+			add(c25dGovernment, gov, c25Dice[po]*c25Dice[gr])
+			for lr := 2; lr <= 12; lr++ {
+				add(c25dLawLevel, lr-7+gov, c25Dice[po]*c25Dice[gr]*c25Dice[lr])
+			}
+		}
+	}
+
+	// The physical UPP digits, and the tech level which depends on everything but the law level.
+	for si := 2; si <= 12; si++ {
+		add(c25dSize, si-2, c25Dice[si])
+		for ar := 2; ar <= 12; ar++ {
+			for hr := 2; hr <= 12; hr++ {
+				w := c25World{Size: si - 2}
+				w.Atmosphere = clampInt(ar-7+w.Size, 0, math.MaxInt32)
+				if w.Size == 0 {
+					w.Atmosphere = 0
+				}
+				w.Hydrographics = hr - 7 + w.Size
+				if w.Size < 2 {
+					w.Hydrographics = 0
+				}
+				w.Hydrographics = clampInt(w.Hydrographics-4, 0, 10)
+				p := c25Dice[si] * c25Dice[ar] * c25Dice[hr]
+				if hr == 2 {
+					add(c25dAtmosphere, w.Atmosphere, c25Dice[si]*c25Dice[ar])
+				}
+				add(c25dHydrographics, w.Hydrographics, p)
+				for st := 2; st <= 12; st++ {
+					w.Starport = string(c25Starports[st-1])
+					for po := 2; po <= 12; po++ {
+						w.Population = po - 2
+						for gr := 2; gr <= 12; gr++ {
+							w.Government = clampInt(gr-7+w.Population, 0, math.MaxInt32)
+							add(c25dTechLevel, c25TechLevel(w), p*c25Dice[st]*c25Dice[po]*c25Dice[gr])
+						}
+					}
+				}
+			}
+		}
+	}
+	return ds
+}
+
+// verifyChallenge25 generates n worlds with the Challenge #25 generator and compares the frequency of each value
+// with the probabilities from the original tables. Any value more than 4 standard errors from what is expected is
+// flagged. It returns a report for display, and whether every value was within the limit.
+func verifyChallenge25(n int) (report string, ok bool) {
+	if n <= 0 {
+		return "No worlds generated", false
+	}
+	ds := c25ExpectedDistributions()
+	r := tools.RNG()
+	for i := 0; i < n; i++ {
+		idx := generateC25World(r, 1, 1, c25Allegiance).c25Index()
+		for d := range ds {
+			if idx[d] >= 0 {
+				ds[d].Observed[idx[d]]++
+			}
+		}
+	}
+
+	ok = true
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Challenge #25 sector generator: %d worlds\n", n)
+	for _, d := range ds {
+		fmt.Fprintf(&sb, "\n%s\n%-10s %9s %9s\n", d.Name, "Value", "Expected", "Observed")
+		for i, label := range d.Labels {
+			exp, obs := d.Expected[i], d.Observed[i]/float64(n)
+			if exp == 0 && obs == 0 {
+				continue
+			}
+			flag := ""
+			if limit := 4*math.Sqrt(exp*(1-exp)/float64(n)) + 1e-9; math.Abs(obs-exp) > limit {
+				flag, ok = " *", false
+			}
+			fmt.Fprintf(&sb, "%-10s %8.2f%% %8.2f%%%s\n", label, exp*100, obs*100, flag)
+		}
+	}
+	if ok {
+		sb.WriteString("\nAll values are within 4 standard errors of the original tables.\n")
+	} else {
+		sb.WriteString("\nValues marked * are more than 4 standard errors from the original tables.\n")
+	}
+	return sb.String(), ok
+}
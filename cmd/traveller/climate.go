@@ -0,0 +1,204 @@
+package main
+
+// climate.go contains the physical temperature model for worlds. Temperatures are worked out from the
+// luminosity of the star, the orbital distance, and the albedo, greenhouse factor, axial tilt and rotation
+// of the world - the same columns that the campaign system spreadsheets compute by hand.
+
+import (
+	"fmt"
+	"math"
+)
+
+// kelvinOffset converts between Kelvin and Celsius.
+const kelvinOffset = 273.15
+
+// earthBlackbody is the blackbody temperature (in K) of a body at 1 AU from a star of 1 Sol luminosity.
+const earthBlackbody = 279.0
+
+// climateParams contains the inputs for the temperature model.
+type climateParams struct {
+	Luminosity    float64 // The luminosity of the star, in standard (Sol) luminosities, eg 1.0
+	DistanceAU    float64 // The orbital distance from the star in AU, eg 1.0
+	Albedo        float64 // The fraction of light reflected, 0.0 to 1.0, eg 0.3
+	Greenhouse    float64 // The greenhouse factor, a multiplier of the blackbody temperature, eg 1.1
+	AxialTilt     float64 // The axial tilt in degrees, eg 23
+	RotationHours float64 // The rotation period (day length) in hours, 0 if tidally locked, eg 24
+	Pressure      float64 // The surface atmospheric pressure in standard atmospheres, eg 1.0
+	Hydrographics int     // The hydrographics digit of the UWP, eg 7
+}
+
+// worldClimate contains the results of the temperature model. All temperatures are in degrees Celsius.
+type worldClimate struct {
+	Mean         float64 // The base (mean) surface temperature
+	Day          float64 // The base daytime temperature
+	Night        float64 // The base nighttime temperature
+	SummerRise   float64 // The seasonal increase in summer
+	WinterFall   float64 // The seasonal decrease in winter
+	Max          float64 // The upper temperature limit, the daytime temperature at the height of summer
+	Min          float64 // The lower temperature limit, the nighttime temperature in the depth of winter
+	Band         string  // The temperature band for the mean temperature, eg "Temperate"
+	Habitability string  // How habitable the temperatures are for humans, eg "Habitable"
+}
+
+// Constants for the habitability of a world's temperatures.
+const (
+	hbHabitable     = "Habitable"     // The mean and the extremes are survivable.
+	hbMarginal      = "Marginal"      // The mean is survivable, but the extremes are not.
+	hbUninhabitable = "Uninhabitable" // The mean is not survivable.
+)
+
+// defaultClimateParams sets typical climate parameters for a world from its UWP. The albedo, greenhouse factor
+// and pressure depend on the atmosphere and hydrographics. The star and distance still need to be set.
+func defaultClimateParams(uwp worldUwp) climateParams {
+	p := climateParams{AxialTilt: 20, RotationHours: 24, Hydrographics: uwp.hydInt}
+
+	switch uwp.atmInt {
+	case 0:
+		p.Pressure, p.Greenhouse = 0, 1.0
+	case 1:
+		p.Pressure, p.Greenhouse = 0.05, 1.0
+	case 2, 3:
+		p.Pressure, p.Greenhouse = 0.3, 1.0
+	case 4, 5:
+		p.Pressure, p.Greenhouse = 0.6, 1.05
+	case 6, 7:
+		p.Pressure, p.Greenhouse = 1.0, 1.1
+	case 8, 9:
+		p.Pressure, p.Greenhouse = 2.0, 1.15
+	case 10:
+		p.Pressure, p.Greenhouse = 1.0, 1.2
+	case 11:
+		p.Pressure, p.Greenhouse = 5.0, 1.5
+	case 12:
+		p.Pressure, p.Greenhouse = 10.0, 1.9
+	case 13:
+		p.Pressure, p.Greenhouse = 3.0, 1.15
+	case 14:
+		p.Pressure, p.Greenhouse = 0.3, 1.1
+	default:
+		p.Pressure, p.Greenhouse = 1.0, 1.0
+	}
+
+	// Oceans and cloud cover both make a world more reflective.
+	p.Albedo = 0.1 + 0.03*float64(uwp.hydInt)
+	if uwp.atmInt >= 11 && uwp.atmInt <= 12 {
+		p.Albedo += 0.25
+	}
+	if p.Albedo > 0.9 {
+		p.Albedo = 0.9
+	}
+	return p
+}
+
+// calculate works out the temperatures from the climate parameters. Thick atmospheres and oceans buffer
+// the daily and seasonal swings, which grow with the length of the day and the axial tilt.
+func (p climateParams) calculate() (c worldClimate) {
+
+	if p.Luminosity <= 0 || p.DistanceAU <= 0 {
+		c.Mean, c.Day, c.Night, c.Max, c.Min = -kelvinOffset, -kelvinOffset, -kelvinOffset, -kelvinOffset, -kelvinOffset
+		c.Band, c.Habitability = temperatureBand(c.Mean), hbUninhabitable
+		return
+	}
+
+	meanK := earthBlackbody * math.Pow(p.Luminosity, 0.25) / math.Sqrt(p.DistanceAU) *
+		math.Pow(1-p.Albedo, 0.25) * p.Greenhouse
+	buffer := 1 + 2*p.Pressure + 2*float64(p.Hydrographics)/10
+
+	var dayK, nightK float64
+	if p.RotationHours <= 0 {
+		// Tidally locked, so the day side is as hot as the subsolar point and the night side is barely warmed.
+		dayK = meanK * math.Sqrt2
+		nightK = meanK * (0.3 + 0.5*(1-1/buffer))
+	} else {
+		rise := meanK * 0.15 * math.Sqrt(p.RotationHours/24) / buffer
+		dayK = math.Min(meanK+rise, meanK*math.Sqrt2)
+		nightK = math.Max(meanK-1.5*rise, meanK*0.3)
+	}
+	summer := meanK * 0.15 * math.Sin(p.AxialTilt*math.Pi/180) / math.Sqrt(buffer) * 2
+	winter := summer * 1.5
+
+	c.Mean = meanK - kelvinOffset
+	c.Day = dayK - kelvinOffset
+	c.Night = nightK - kelvinOffset
+	c.SummerRise = summer
+	c.WinterFall = winter
+	c.Max = c.Day + summer
+	c.Min = math.Max(c.Night-winter, 3-kelvinOffset)
+	c.Band = temperatureBand(c.Mean)
+
+	switch {
+	case p.Pressure <= 0.1 || c.Mean < -25 || c.Mean > 45:
+		c.Habitability = hbUninhabitable
+	case c.Min < -60 || c.Max > 60:
+		c.Habitability = hbMarginal
+	default:
+		c.Habitability = hbHabitable
+	}
+	return
+}
+
+// temperatureBand returns the name of the band that a temperature (in degrees Celsius) falls in.
+func temperatureBand(t float64) string {
+	switch {
+	case t < -50:
+		return "Frozen"
+	case t < 0:
+		return "Cold"
+	case t < 30:
+		return "Temperate"
+	case t < 60:
+		return "Hot"
+	case t < 100:
+		return "Boiling"
+	default:
+		return "Scorching"
+	}
+}
+
+// climateFromHabZoneVar gives a quick climate for a mainworld from its habitable zone variance alone, as if it
+// orbited a star like Sol (whose habitable zone is orbit 3). Use this when the star and orbit are not known.
+func climateFromHabZoneVar(uwp worldUwp, hzVar int) worldClimate {
+	p := defaultClimateParams(uwp)
+	p.Luminosity = 1.0
+	p.DistanceAU = orbitToAU(float64(3 + hzVar))
+	return p.calculate()
+}
+
+// calculateClimate works out the climate for a world around its primary star, using the habitable zone of
+// the star and the habitable zone variance of the world. If the star cannot be found, the quick climate is used.
+func (w world) calculateClimate() (worldClimate, error) {
+	if len(w.stars) == 0 {
+		return climateFromHabZoneVar(w.uwp, w.habZoneVar), nil
+	}
+	sm, err := w.stars[0].model()
+	if err != nil {
+		return climateFromHabZoneVar(w.uwp, w.habZoneVar), err
+	}
+	p := defaultClimateParams(w.uwp)
+	p.Luminosity = sm.Luminosity
+	p.DistanceAU = orbitToAU(float64(w.stars[0].habitableZoneOrbit() + w.habZoneVar))
+	return p.calculate(), nil
+}
+
+// bodyClimate works out the climate for a body in a generated system, from the star it orbits and its UWP.
+// Gas giants are given the climate at the top of their atmosphere. Satellites share the orbit of their host.
+func (s *starSystem) bodyClimate(b systemBody) (worldClimate, error) {
+	if b.Star < 0 || b.Star >= len(s.Stars) {
+		return worldClimate{}, fmt.Errorf("%w: body %s orbits star %d", ErrSystemInconsistent, b.Name, b.Star)
+	}
+	var p climateParams
+	if uwp := parseUwp(b.UWP); uwp.sizeInt >= 0 {
+		p = defaultClimateParams(uwp)
+	} else {
+		p = climateParams{Albedo: 0.5, Greenhouse: 1.0, Pressure: 100, RotationHours: 10, AxialTilt: 10}
+	}
+	p.Luminosity = s.Stars[b.Star].Luminosity
+	p.DistanceAU = orbitToAU(float64(b.Orbit))
+	return p.calculate(), nil
+}
+
+// String returns the climate as a short multi-line description.
+func (c worldClimate) String() string {
+	return fmt.Sprintf("Mean %.0f°C (%s), day %.0f°C, night %.0f°C\nSummer +%.0f°C, winter -%.0f°C, range %.0f°C to %.0f°C\n%s",
+		c.Mean, c.Band, c.Day, c.Night, c.SummerRise, c.WinterFall, c.Min, c.Max, c.Habitability)
+}
@@ -52,6 +52,10 @@ type configuration struct {
 	SectorOutputFile string // Output tab file for sectors generated
 	WorldGenNumber   int    // Number of worlds generated in Auto mode
 	ForevenFile      string // Output worlds tab file for Foreven sector
+
+	HabitableZoneMethod string // The habitable zone method for the campaign: Book6, MegaTraveller, T5 or Luminosity
+	Campaign            string // The name of the campaign, which overlays on canonical sectors are saved for
+	NotesDir            string // The directory of the referee's notes on worlds, one text file per world
 }
 
 // config is the global configuration item.
@@ -3,7 +3,7 @@ package main
 // database.go contains code for accessing the Sqlite database.
 
 import (
-	"strings"
+	"log"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -46,6 +46,7 @@ type stellarDto struct {
 	habitableZone   int
 	minOrbit        int
 	mass            float64
+	solarLuminosity float64 // The luminosity in standard (Sol) luminosities
 }
 
 // initDb initialises the Database connection. Filename is in the dbFile string. It panics if not available.
@@ -444,63 +445,38 @@ func clearWorldStaging() {
 
 */
 
-// checkErr checks an error on the database, optional string arguments can be provided. Returns true if OK, false if an error.
-func checkErr(err error, args ...string) bool {
-	if err != nil {
-		//errString := fmt.Sprintf("Error\n%q: %s\n", err, args)
-		//logPane.Log(errString)
-		return false
+// convertToWorld converts a worldDto data transfer object to a World struct. It expects a fully completed object.
+// It returns the new world struct.
+func (d worldDto) convertToWorld() (w world) {
+
+	// First convert the easy
+	w.genType = WgtT5ss
+	w.id = d.id
+	w.name = d.name
+	w.sectorAbbrev = d.sectorNameAbbr
+	w.sector = d.sector
+	w.subsector = d.subsector
+	w.subsectorIndex = d.subsectorIndex
+	if h := NewHexLoc(d.hexLoc, true); h != nil {
+		w.hexLoc = *h
 	}
-	return true
-}
-
-// dbSanitise dDoes a basic database sanitise on a text string prior to presenting to the database in a query. It returns the "clean" string.
-func dbSanitise(text string) (ret string) {
-
-	ret = strings.ReplaceAll(text, "%", "")
-	ret = strings.ReplaceAll(ret, ";", "")
-	ret = strings.ReplaceAll(ret, "?", "")
-	ret = strings.ReplaceAll(ret, "*", "")
-	ret = strings.ReplaceAll(ret, "_", "")
-	ret = strings.ReplaceAll(ret, "&", "")
-	ret = strings.ReplaceAll(ret, "$", "")
-	ret = strings.ReplaceAll(ret, "#", "")
-	ret = strings.ReplaceAll(ret, "@", "")
-	ret = strings.ReplaceAll(ret, "!", "")
-	ret = strings.ReplaceAll(ret, "'", "''")
+	w.uwp = parseUwp(d.uwp)
+	w.bases = d.bases
+	w.remarks = d.remarks
+	// Ignore TravelZone errors. They'll just be converted to TzUnknown anyway
+	var err error
+	if w.zone, err = ZoneFromString(d.zone); err != nil {
+		log.Print(err.Error())
+	}
+	w.pbg = parsePbg(d.pbg)
+	w.allegiance = d.allegiance
+	w.stars = parseStars(d.stars)
+	w.importance = parseImportanceExt(d.importance)
+	w.economics = parseEconomicEx(d.economics)
+	w.culture = parseCultureEx(d.culture)
+	w.nobility = d.nobility
+	w.worlds = d.worlds
+	w.ru = d.ru
 
 	return
 }
-
-// convertToWorld converts a worldDto data transfer object to a World struct. It expects a fully completed object.
-// It returns the new world struct.
-// func (d worldDto) convertToWorld() (w world) {
-
-// 	// First convert the easy
-// 	w.id = d.id
-// 	w.name = d.name
-// 	w.sectorAbbrev = d.sectorNameAbbr
-// 	w.sector = d.sector
-// 	w.subsector = d.subsector
-// 	w.subsectorIndex = d.subsectorIndex
-// 	w.hexLoc = *NewHexLoc(d.hexLoc, true)
-// 	w.uwp = parseUwp(d.uwp)
-// 	w.bases = d.bases
-// 	w.remarks = d.remarks
-// 	// Ignore TravelZone errors. They'll just be converted to TzUnknown anyway
-// 	var err error
-// 	if w.zone, err = ZoneFromString(d.zone); err != nil {
-// 		log.Printf(err.Error())
-// 	}
-// 	w.pbg = parsePbg(d.pbg)
-// 	w.allegiance = d.allegiance
-// 	w.stars = parseStars(d.stars)
-// 	w.importance = parseImportanceExt(d.importance)
-// 	w.economics = parseEconomicEx(d.economics)
-// 	w.culture = parseCultureEx(d.culture)
-// 	w.nobility = d.nobility
-// 	w.worlds = d.worlds
-// 	w.ru = d.ru
-
-// 	return
-// }
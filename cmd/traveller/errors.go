@@ -7,3 +7,71 @@ type StringError string
 func (err StringError) Error() string {
 	return string(err)
 }
+
+// Errors returned when looking up or generating objects.
+const (
+	// ErrWorldNotFound is returned when a world cannot be found in the database.
+	ErrWorldNotFound StringError = "world not found"
+	// ErrStarNotFound is returned when a star cannot be found in the stellar_detail table.
+	ErrStarNotFound StringError = "star not found"
+	// ErrSystemNotFound is returned when no generated system detail is stored for a world.
+	ErrSystemNotFound StringError = "system detail not found"
+	// ErrSystemInconsistent is returned when a generated system contradicts the canonical world data.
+	ErrSystemInconsistent StringError = "system inconsistent with world data"
+	// ErrInvalidStars is returned when part of a stars string cannot be parsed.
+	ErrInvalidStars StringError = "invalid stars"
+	// ErrSectorCanonical is returned when asked to overwrite a sector that was not generated by this program.
+	ErrSectorCanonical StringError = "sector is canonical"
+	// ErrInvalidLanguageMix is returned when a language mix cannot be parsed or names a language with no generator.
+	ErrInvalidLanguageMix StringError = "invalid language mix"
+	// ErrSectorNotFound is returned when a sector has no worlds in the database.
+	ErrSectorNotFound StringError = "sector not found"
+	// ErrMetadataNoName is returned when sector metadata being imported has no sector name.
+	ErrMetadataNoName StringError = "metadata has no sector name"
+	// ErrInvalidTabFile is returned when a sector file has no header line with a Hex column.
+	ErrInvalidTabFile StringError = "invalid sector file: no Hex column"
+	// ErrUnknownFormat is returned when the format of a sector file cannot be detected or chosen from its extension.
+	ErrUnknownFormat StringError = "unknown sector file format"
+	// ErrInvalidHex is returned when a world read from a file has no valid hex location.
+	ErrInvalidHex StringError = "invalid hex"
+	// ErrInvalidUWP is returned when a world read from a file has a UWP of the wrong form.
+	ErrInvalidUWP StringError = "invalid UWP"
+	// ErrTooFewColumns is returned when a line of a delimited sector file has too few columns to be a world.
+	ErrTooFewColumns StringError = "too few columns"
+	// ErrInvalidSECLine is returned when a line of a legacy SEC file is not a world in its fixed columns.
+	ErrInvalidSECLine StringError = "not a SEC world line"
+	// ErrInvalidZone is returned when a world being imported has a travel zone that is not green, amber or red.
+	ErrInvalidZone StringError = "invalid travel zone"
+	// ErrInvalidPBG is returned when a world being imported has a PBG that is not three digits.
+	ErrInvalidPBG StringError = "invalid PBG"
+	// ErrSubsectorMismatch is returned when a world being imported is in a hex outside its subsector.
+	ErrSubsectorMismatch StringError = "hex not in subsector"
+	// ErrDuplicateHex is returned when a file being imported has more than one world in a hex.
+	ErrDuplicateHex StringError = "duplicate hex"
+)
+
+// Errors returned when opening or migrating the database.
+const (
+	// ErrDatabaseTooNew is returned when the database has a newer schema than the program's migrations.
+	ErrDatabaseTooNew StringError = "database schema is newer than this program"
+	// ErrMigrationName is returned when an embedded migration's file name does not start with its version.
+	ErrMigrationName StringError = "migration name has no version"
+	// ErrMigrationMissing is returned when the versions of the embedded migrations have a gap.
+	ErrMigrationMissing StringError = "migration missing"
+)
+
+// Errors returned when exporting objects.
+const (
+	// ErrUnknownImageFormat is returned when asked to save an image in a format that is not supported.
+	ErrUnknownImageFormat StringError = "unknown image format"
+	// ErrAtlasEmpty is returned when none of the sectors asked for could be written to the atlas.
+	ErrAtlasEmpty StringError = "no sectors were written to the atlas"
+	// ErrAtlasIncomplete is returned when the atlas was written without some of the sectors asked for.
+	ErrAtlasIncomplete StringError = "sectors left out of the atlas"
+)
+
+// Errors returned when searching.
+const (
+	// ErrEmptySearch is returned when a search has no terms to search for.
+	ErrEmptySearch StringError = "nothing to search for"
+)
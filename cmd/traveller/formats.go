@@ -0,0 +1,823 @@
+package main
+
+// formats.go contains the registry of file formats that sectors and lists of worlds can be imported from and exported
+// to. Each format has a detector, a reader and a writer. The formats work on worldRecords, the fields of a world as
+// text, so that adding a format does not need any knowledge of how worlds are stored. Reading a file detects its
+// format from its first lines, and reports the lines that could not be read rather than stopping at the first.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// worldRecord holds the fields of a world as text, in the form they have in a sector file.
+type worldRecord struct {
+	Sector     string `json:"Sector,omitempty"`
+	SS         string `json:"SS,omitempty"`
+	Hex        string `json:"Hex"`
+	Name       string `json:"Name"`
+	UWP        string `json:"UWP"`
+	Bases      string `json:"Bases,omitempty"`
+	Remarks    string `json:"Remarks,omitempty"`
+	Zone       string `json:"Zone,omitempty"`
+	PBG        string `json:"PBG,omitempty"`
+	Allegiance string `json:"Allegiance,omitempty"`
+	Stars      string `json:"Stars,omitempty"`
+	Ix         string `json:"Ix,omitempty"`
+	Ex         string `json:"Ex,omitempty"`
+	Cx         string `json:"Cx,omitempty"`
+	Nobility   string `json:"Nobility,omitempty"`
+	W          int    `json:"W,omitempty"`
+	RU         int    `json:"RU,omitempty"`
+}
+
+// recordColumns are the names of the columns of a worldRecord, in the order of a T5 tab file.
+var recordColumns = []string{"Sector", "SS", "Hex", "Name", "UWP", "Bases", "Remarks", "Zone", "PBG", "Allegiance",
+	"Stars", "{Ix}", "(Ex)", "[Cx]", "Nobility", "W", "RU"}
+
+// columnAliases maps the other names that files use for columns to the names in recordColumns.
+var columnAliases = map[string]string{"Stellar": "Stars", "B": "Bases", "Z": "Zone", "N": "Nobility",
+	"A": "Allegiance", "Ix": "{Ix}", "Ex": "(Ex)", "Cx": "[Cx]", "[Cx[]": "[Cx]", "[Cx[": "[Cx]"}
+
+// canonicalColumn returns the name in recordColumns for a column name read from a file.
+func canonicalColumn(c string) string {
+	c = strings.TrimSpace(c)
+	if a, ok := columnAliases[c]; ok {
+		return a
+	}
+	return c
+}
+
+// field returns the value of the named column of the record, or "" for an unknown column.
+func (r *worldRecord) field(col string) string {
+	switch col {
+	case "Sector":
+		return r.Sector
+	case "SS":
+		return r.SS
+	case "Hex":
+		return r.Hex
+	case "Name":
+		return r.Name
+	case "UWP":
+		return r.UWP
+	case "Bases":
+		return r.Bases
+	case "Remarks":
+		return r.Remarks
+	case "Zone":
+		return r.Zone
+	case "PBG":
+		return r.PBG
+	case "Allegiance":
+		return r.Allegiance
+	case "Stars":
+		return r.Stars
+	case "{Ix}":
+		return r.Ix
+	case "(Ex)":
+		return r.Ex
+	case "[Cx]":
+		return r.Cx
+	case "Nobility":
+		return r.Nobility
+	case "W":
+		return strconv.Itoa(r.W)
+	case "RU":
+		return strconv.Itoa(r.RU)
+	}
+	return ""
+}
+
+// setField sets the named column of the record from its text. Unknown columns are ignored.
+func (r *worldRecord) setField(col, v string) {
+	v = strings.TrimSpace(v)
+	switch col {
+	case "Sector":
+		r.Sector = v
+	case "SS":
+		r.SS = v
+	case "Hex":
+		r.Hex = v
+	case "Name":
+		r.Name = v
+	case "UWP":
+		r.UWP = v
+	case "Bases":
+		r.Bases = v
+	case "Remarks":
+		r.Remarks = v
+	case "Zone":
+		r.Zone = v
+	case "PBG":
+		r.PBG = v
+	case "Allegiance":
+		r.Allegiance = v
+	case "Stars":
+		r.Stars = v
+	case "{Ix}":
+		r.Ix = v
+	case "(Ex)":
+		r.Ex = v
+	case "[Cx]":
+		r.Cx = v
+	case "Nobility":
+		r.Nobility = v
+	case "W":
+		r.W, _ = strconv.Atoi(v)
+	case "RU":
+		r.RU, _ = strconv.Atoi(v)
+	}
+}
+
+// recordFromWorld returns the fields of the world as text.
+func recordFromWorld(w *world) worldRecord {
+	r := worldRecord{Sector: w.sectorAbbrev, SS: w.subsectorIndex, Hex: w.hexLoc.String(), Name: w.name,
+		UWP: w.uwp.String(), Bases: w.bases, Remarks: w.remarks, Zone: w.zone.String()}
+	if w.genType == WgtCt03 {
+		return r
+	}
+	r.PBG, r.Allegiance = w.pbg.String(), w.allegiance
+	if w.genType == WgtMtBasic {
+		return r
+	}
+	r.Stars, r.Ix, r.Ex, r.Cx = w.systemStarString(), w.importance.String(), w.economics.String(), w.culture.String()
+	r.Nobility, r.W, r.RU = w.nobility, w.worlds, w.ru
+	return r
+}
+
+// toWorld converts the record to a world in the named sector, generated the given way. It returns ErrInvalidHex or
+// ErrInvalidUWP if the world has no usable location or profile.
+func (r worldRecord) toWorld(sectorName string, gt WorldGenType) (world, error) {
+	if NewHexLoc(r.Hex, true) == nil {
+		return world{}, fmt.Errorf("%w %q", ErrInvalidHex, r.Hex)
+	}
+	if len(r.UWP) != 9 || r.UWP[7] != '-' {
+		return world{}, fmt.Errorf("%w %q", ErrInvalidUWP, r.UWP)
+	}
+	d := worldDto{sector: sectorName, sectorNameAbbr: r.Sector, subsectorIndex: r.SS, hexLoc: r.Hex, name: r.Name,
+		uwp: r.UWP, bases: r.Bases, remarks: r.Remarks, zone: r.Zone, pbg: r.PBG, allegiance: r.Allegiance,
+		stars: r.Stars, importance: r.Ix, economics: r.Ex, culture: r.Cx, nobility: r.Nobility, worlds: r.W, ru: r.RU}
+	w := d.convertToWorld()
+	w.genType = gt
+	if w.subsectorIndex == "" {
+		w.subsectorIndex = w.hexLoc.GetIndex()
+	}
+	return w, nil
+}
+
+// recordsGenType works out how worlds read from a file were generated, from the fields that the file has.
+func recordsGenType(rs []worldRecord) WorldGenType {
+	gt := WgtCt03
+	for _, r := range rs {
+		if r.Stars != "" || r.Ix != "" || r.Ex != "" || r.Cx != "" || r.Nobility != "" || r.W != 0 || r.RU != 0 {
+			return WgtT5ss
+		}
+		if r.PBG != "" || r.Allegiance != "" {
+			gt = WgtMtBasic
+		}
+	}
+	return gt
+}
+
+// worldColumns returns the columns written for the sector's worlds, which depend on how they were generated.
+func worldColumns(s *sector) []string {
+	if len(s.worlds) > 0 {
+		return genTypeColumns(s.worlds[0].genType)
+	}
+	return genTypeColumns(WgtMtBasic)
+}
+
+// genTypeColumns returns the columns that worlds generated the given way have.
+func genTypeColumns(gt WorldGenType) []string {
+	switch gt {
+	case WgtCt03:
+		return recordColumns[:8]
+	case WgtT5ss:
+		return recordColumns
+	}
+	return recordColumns[:10]
+}
+
+// lineError is an error found in one line or entry of a file being read.
+type lineError struct {
+	line int    // The line (or entry) number, from 1.
+	what string // What the number counts, eg "line".
+	err  error
+}
+
+// Error returns the error, with the line it was found on.
+func (e lineError) Error() string {
+	return fmt.Sprintf("%s %d: %v", e.what, e.line, e.err)
+}
+
+// Unwrap returns the error found on the line.
+func (e lineError) Unwrap() error {
+	return e.err
+}
+
+// formatResult is what a format reads from a file.
+type formatResult struct {
+	name    string        // The name of the sector, if the file has it
+	abbrev  string        // The abbreviation of the sector, if the file has it
+	records []worldRecord // The worlds read
+	lines   []int         // The line of each world
+	what    string        // What the line numbers count, eg "line"
+	errs    []lineError   // The lines that could not be read
+}
+
+// addRecord adds the world read from the line to the result.
+func (fr *formatResult) addRecord(line int, r worldRecord) {
+	fr.records = append(fr.records, r)
+	fr.lines = append(fr.lines, line)
+}
+
+// addError adds an error found on the line to the result.
+func (fr *formatResult) addError(line int, err error) {
+	fr.errs = append(fr.errs, lineError{line: line, what: fr.what, err: err})
+}
+
+// sectorFormat is a file format that sectors can be read from and written to.
+type sectorFormat struct {
+	name       string                                  // The name shown to the user, eg "Tab delimited"
+	extensions []string                                // The file extensions, the first being the one written
+	detect     func(lines []string) bool               // Reports whether the first lines of a file are in the format
+	read       func(data []byte) (formatResult, error) // Reads the worlds from a file
+	write      func(w io.Writer, s *sector) error      // Writes the worlds of the sector
+}
+
+// sectorFormats are the registered formats, in the order they are tried when detecting the format of a file.
+var sectorFormats []*sectorFormat
+
+// registerSectorFormat adds a format to the registry.
+func registerSectorFormat(f *sectorFormat) {
+	sectorFormats = append(sectorFormats, f)
+}
+
+func init() {
+	registerSectorFormat(&sectorFormat{name: "JSON", extensions: []string{".json"}, detect: detectJSON, read: readJSON, write: writeJSON})
+	registerSectorFormat(&sectorFormat{name: "Tab delimited", extensions: []string{".tab", ".tsv"}, detect: detectTab, read: readTab, write: writeTab})
+	registerSectorFormat(&sectorFormat{name: "CSV", extensions: []string{".csv"}, detect: detectCSV, read: readCSV, write: writeCSV})
+	registerSectorFormat(&sectorFormat{name: "T5 Column (Second Survey)", extensions: []string{".txt"}, detect: detectColumn, read: readColumn, write: writeColumn})
+	registerSectorFormat(&sectorFormat{name: "Legacy SEC", extensions: []string{".sec"}, detect: detectSEC, read: readSEC, write: writeSEC})
+}
+
+// formatNames returns the names of the registered formats, for choosing one in the user interface.
+func formatNames() []string {
+	names := make([]string, len(sectorFormats))
+	for i, f := range sectorFormats {
+		names[i] = f.name
+	}
+	return names
+}
+
+// formatExtensions returns the file extensions of the registered formats.
+func formatExtensions() (exts []string) {
+	for _, f := range sectorFormats {
+		exts = append(exts, f.extensions...)
+	}
+	return
+}
+
+// formatForFile returns the format that files with the name's extension are written in, or nil if there is none.
+func formatForFile(filename string) *sectorFormat {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, f := range sectorFormats {
+		for _, e := range f.extensions {
+			if e == ext {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// splitLines splits the file into lines, without their line endings.
+func splitLines(data []byte) []string {
+	return strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+}
+
+// detectContent returns the format of the file detected from its first lines, or nil if no format recognises them.
+func detectContent(data []byte) *sectorFormat {
+	lines := splitLines(data)
+	if len(lines) > 20 {
+		lines = lines[:20]
+	}
+	for _, f := range sectorFormats {
+		if f.detect(lines) {
+			return f
+		}
+	}
+	return nil
+}
+
+// detectFormat returns the format of the file, detected from its first lines, or from the extension of its name
+// if no format recognises them. It returns nil if the format is unknown.
+func detectFormat(data []byte, filename string) *sectorFormat {
+	if f := detectContent(data); f != nil {
+		return f
+	}
+	return formatForFile(filename)
+}
+
+// readSector reads the sector from the file's data, in the format detected from it. The sector is named from the
+// file if the file does not hold its name. It returns the lines that could not be read as well as the sector.
+func readSector(data []byte, filename string) (*sector, []lineError, error) {
+	f := detectFormat(data, filename)
+	if f == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownFormat, filename)
+	}
+	fr, err := f.read(data)
+	if err != nil {
+		return nil, fr.errs, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	name := fr.name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+	s := &sector{id: -1, name: name, abbrev: fr.abbrev, saved: true}
+	gt := recordsGenType(fr.records)
+	for i, r := range fr.records {
+		w, err := r.toWorld(name, gt)
+		if err != nil {
+			fr.addError(fr.lines[i], err)
+			continue
+		}
+		s.worlds = append(s.worlds, w)
+	}
+	if s.abbrev == "" && len(s.worlds) > 0 {
+		s.abbrev = s.worlds[0].sectorAbbrev
+	}
+	if s.abbrev == "" {
+		s.abbrev = getAbbreviationForSector(name)
+	}
+	for _, e := range fr.errs {
+		log.Printf("%s: %v", filename, e)
+	}
+	log.Printf("Read %d worlds of %s sector from %s as %s", len(s.worlds), name, filename, f.name)
+	return s, fr.errs, nil
+}
+
+// writeSector writes the sector's worlds in the format.
+func writeSector(w io.Writer, s *sector, f *sectorFormat) error {
+	bw := bufio.NewWriter(w)
+	if err := f.write(bw, s); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeSectorFile writes the sector to the named file, in the format chosen by the file's extension, and marks it
+// as saved.
+func writeSectorFile(s *sector, filename string) error {
+	f := formatForFile(filename)
+	if f == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, filename)
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		log.Printf("Unable to open file %s: %v", filename, err)
+		return err
+	}
+	if err = writeSector(file, s, f); err != nil {
+		file.Close()
+		log.Printf("Write error: %v", err)
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	log.Printf("Sector %s written to file %s as %s", s.name, filename, f.name)
+	s.saved = true
+	return nil
+}
+
+// exportWorlds writes the worlds, such as the results of a search, to the named file as if they were a sector with
+// the name, in the format chosen by the file's extension.
+func exportWorlds(name string, ws []world, filename string) error {
+	return writeSectorFile(&sector{id: -1, name: name, worlds: ws}, filename)
+}
+
+// headerColumns returns the canonical names of the columns in a header line split into fields.
+func headerColumns(fields []string) []string {
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = canonicalColumn(f)
+	}
+	return cols
+}
+
+// hasColumn reports whether the columns include the one named.
+func hasColumn(cols []string, name string) bool {
+	for _, c := range cols {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// firstLine returns the first line that is not blank or a comment starting with '#', and its index.
+func firstLine(lines []string) (int, string) {
+	for i, l := range lines {
+		if t := strings.TrimSpace(l); t != "" && !strings.HasPrefix(t, "#") {
+			return i, l
+		}
+	}
+	return -1, ""
+}
+
+// recordFromFields makes a record from the fields of a line, under the columns.
+func recordFromFields(cols, fields []string) (r worldRecord) {
+	for i, c := range cols {
+		if i < len(fields) {
+			r.setField(c, fields[i])
+		}
+	}
+	return
+}
+
+// recordFields returns the values of the record's columns.
+func recordFields(r *worldRecord, cols []string) []string {
+	fields := make([]string, len(cols))
+	for i, c := range cols {
+		fields[i] = r.field(c)
+	}
+	return fields
+}
+
+// detectTab recognises a tab delimited file, whose header line has a Hex column.
+func detectTab(lines []string) bool {
+	_, l := firstLine(lines)
+	return strings.Contains(l, "\t") && hasColumn(headerColumns(strings.Split(l, "\t")), "Hex")
+}
+
+// readTab reads a tab delimited file, such as one downloaded from travellermap.com. The columns are found from the
+// header line.
+func readTab(data []byte) (fr formatResult, err error) {
+	fr.what = "line"
+	lines := splitLines(data)
+	first, header := firstLine(lines)
+	cols := headerColumns(strings.Split(header, "\t"))
+	if first < 0 || !hasColumn(cols, "Hex") {
+		return fr, ErrInvalidTabFile
+	}
+	return readDelimited(lines[first+1:], first+1, cols), nil
+}
+
+// readDelimited reads worlds from tab delimited lines whose fields are under the columns. The first of the lines is
+// the given (0-based) line of the file.
+func readDelimited(lines []string, first int, cols []string) (fr formatResult) {
+	fr.what = "line"
+	for i, l := range lines {
+		if t := strings.TrimSpace(l); t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		fields := strings.Split(l, "\t")
+		if len(fields) < 5 {
+			fr.addError(first+i+1, fmt.Errorf("%w: %d columns", ErrTooFewColumns, len(fields)))
+			continue
+		}
+		fr.addRecord(first+i+1, recordFromFields(cols, fields))
+	}
+	return
+}
+
+// writeTab writes a tab delimited file with a header line, as read by travellermap.com.
+func writeTab(w io.Writer, s *sector) error {
+	cols := worldColumns(s)
+	if _, err := fmt.Fprintln(w, strings.Join(cols, "\t")); err != nil {
+		return err
+	}
+	for i := range s.worlds {
+		r := recordFromWorld(&s.worlds[i])
+		if _, err := fmt.Fprintln(w, strings.Join(recordFields(&r, cols), "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detectCSV recognises a comma separated file, whose header line has a Hex column.
+func detectCSV(lines []string) bool {
+	_, l := firstLine(lines)
+	if !strings.Contains(l, ",") {
+		return false
+	}
+	fields, err := csv.NewReader(strings.NewReader(l)).Read()
+	return err == nil && hasColumn(headerColumns(fields), "Hex")
+}
+
+// readCSV reads a comma separated file with a header line.
+func readCSV(data []byte) (fr formatResult, err error) {
+	fr.what = "line"
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+	header, err := cr.Read()
+	if err != nil {
+		return fr, err
+	}
+	cols := headerColumns(header)
+	if !hasColumn(cols, "Hex") {
+		return fr, ErrInvalidTabFile
+	}
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// FieldPos can only be used for a record that was read, so the line comes from the error.
+			line := 0
+			if pe, ok := err.(*csv.ParseError); ok {
+				line = pe.Line
+			}
+			fr.addError(line, err)
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		if len(fields) < 5 {
+			fr.addError(line, fmt.Errorf("%w: %d columns", ErrTooFewColumns, len(fields)))
+			continue
+		}
+		fr.addRecord(line, recordFromFields(cols, fields))
+	}
+	return fr, nil
+}
+
+// writeCSV writes a comma separated file with a header line.
+func writeCSV(w io.Writer, s *sector) error {
+	cols := worldColumns(s)
+	cw := csv.NewWriter(w)
+	cw.Write(cols)
+	for i := range s.worlds {
+		r := recordFromWorld(&s.worlds[i])
+		cw.Write(recordFields(&r, cols))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// columnRule matches the line of dashes under the header of a T5 column file, which gives the columns' widths.
+var columnRule = regexp.MustCompile(`^-+( +-+)+ *$`)
+
+// columnEmpty are the columns of a T5 column file that have "-" when they are empty.
+var columnEmpty = map[string]bool{"Bases": true, "Zone": true, "Nobility": true, "{Ix}": true, "(Ex)": true,
+	"[Cx]": true, "Stars": true}
+
+// detectColumn recognises a T5 column file, from its line of dashes under a header with a Hex column.
+func detectColumn(lines []string) bool {
+	for i := 1; i < len(lines); i++ {
+		if columnRule.MatchString(lines[i]) {
+			return strings.Contains(lines[i-1], "Hex")
+		}
+	}
+	return false
+}
+
+// columnSpans returns the start and end of each run of dashes in the rule line.
+func columnSpans(rule string) (spans [][2]int) {
+	start := -1
+	for i, r := range rule + " " {
+		switch {
+		case r == '-' && start < 0:
+			start = i
+		case r != '-' && start >= 0:
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	return
+}
+
+// spanField returns the part of the line in the span, trimmed.
+func spanField(line []rune, span [2]int) string {
+	if span[0] >= len(line) {
+		return ""
+	}
+	return strings.TrimSpace(string(line[span[0]:minInt(span[1], len(line))]))
+}
+
+// readColumn reads a T5 column file, as used by the Second Survey. The widths of the columns are given by the line
+// of dashes under the header.
+func readColumn(data []byte) (fr formatResult, err error) {
+	fr.what = "line"
+	lines := splitLines(data)
+	rule := -1
+	for i := 1; i < len(lines) && rule < 0; i++ {
+		if columnRule.MatchString(lines[i]) {
+			rule = i
+		}
+	}
+	if rule < 0 {
+		return fr, ErrInvalidTabFile
+	}
+	spans := columnSpans(lines[rule])
+	header := []rune(lines[rule-1])
+	cols := make([]string, len(spans))
+	for i, sp := range spans {
+		// The last column's heading may be longer than its dashes.
+		if i == len(spans)-1 {
+			sp[1] = len(header)
+		}
+		cols[i] = canonicalColumn(spanField(header, sp))
+	}
+	if !hasColumn(cols, "Hex") {
+		return fr, ErrInvalidTabFile
+	}
+	for i := rule + 1; i < len(lines); i++ {
+		if t := strings.TrimSpace(lines[i]); t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		line := []rune(lines[i])
+		fields := make([]string, len(spans))
+		for j, sp := range spans {
+			if j == len(spans)-1 {
+				sp[1] = len(line)
+			}
+			fields[j] = spanField(line, sp)
+			if fields[j] == "-" && columnEmpty[cols[j]] {
+				fields[j] = ""
+			}
+		}
+		fr.addRecord(i+1, recordFromFields(cols, fields))
+	}
+	return
+}
+
+// writeColumn writes a T5 column file, with each column as wide as its widest value.
+func writeColumn(w io.Writer, s *sector) error {
+	cols := worldColumns(s)
+	rows := make([][]string, len(s.worlds))
+	widths := make([]int, len(cols))
+	for i, c := range cols {
+		widths[i] = len([]rune(c))
+	}
+	for i := range s.worlds {
+		r := recordFromWorld(&s.worlds[i])
+		rows[i] = recordFields(&r, cols)
+		for j, f := range rows[i] {
+			if f == "" && columnEmpty[cols[j]] {
+				rows[i][j] = "-"
+			}
+			widths[j] = maxInt(widths[j], len([]rune(rows[i][j])))
+		}
+	}
+	writeRow := func(fields []string) error {
+		padded := make([]string, len(fields))
+		for i, f := range fields {
+			padded[i] = f + strings.Repeat(" ", widths[i]-len([]rune(f)))
+		}
+		_, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(padded, " "), " "))
+		return err
+	}
+	rule := make([]string, len(cols))
+	for i := range cols {
+		rule[i] = strings.Repeat("-", widths[i])
+	}
+	if err := writeRow(cols); err != nil {
+		return err
+	}
+	if err := writeRow(rule); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secLine matches a world line of a legacy SEC file: a name of 14 characters, the hex and the UWP.
+var secLine = regexp.MustCompile(`^.{14}[0-9]{4} [A-HXY?][0-9A-Z?]{6}-[0-9A-Z?]`)
+
+// secLegacyBases are the single letters that legacy SEC files use for pairs of bases.
+var secLegacyBases = map[string]string{"NS": "A", "NW": "B", "SN": "A", "WN": "B"}
+
+// secBases are the pairs of bases that the single letters of legacy SEC files stand for.
+var secBases = map[string]string{"A": "NS", "B": "NW"}
+
+// detectSEC recognises a legacy SEC file from its first world line.
+func detectSEC(lines []string) bool {
+	for _, l := range lines {
+		if t := strings.TrimSpace(l); t == "" || strings.HasPrefix(t, "#") || strings.HasPrefix(t, "@") {
+			continue
+		}
+		return secLine.MatchString(l)
+	}
+	return false
+}
+
+// readSEC reads a legacy SEC file, whose fields are in fixed columns. Lines starting with '#' or '@' are comments.
+// The single letters for pairs of bases are read as the pairs.
+func readSEC(data []byte) (fr formatResult, err error) {
+	fr.what = "line"
+	spans := []struct {
+		col  string
+		span [2]int
+	}{{"Name", [2]int{0, 14}}, {"Hex", [2]int{14, 18}}, {"UWP", [2]int{19, 28}}, {"Bases", [2]int{30, 31}},
+		{"Remarks", [2]int{32, 47}}, {"Zone", [2]int{48, 49}}, {"PBG", [2]int{51, 54}},
+		{"Allegiance", [2]int{55, 57}}, {"Stars", [2]int{58, 1 << 16}}}
+	for i, l := range splitLines(data) {
+		if t := strings.TrimSpace(l); t == "" || strings.HasPrefix(t, "#") || strings.HasPrefix(t, "@") {
+			continue
+		}
+		if !secLine.MatchString(l) {
+			fr.addError(i+1, ErrInvalidSECLine)
+			continue
+		}
+		line := []rune(l)
+		var r worldRecord
+		for _, sp := range spans {
+			r.setField(sp.col, spanField(line, sp.span))
+		}
+		if b, ok := secBases[r.Bases]; ok {
+			r.Bases = b
+		}
+		fr.addRecord(i+1, r)
+	}
+	return
+}
+
+// writeSEC writes a legacy SEC file. Pairs of bases are written as their single letter, and allegiances are cut to
+// two characters, as the format has no room for more.
+func writeSEC(w io.Writer, s *sector) error {
+	if _, err := fmt.Fprintf(w, "# %s\n#\n#--------1---------2---------3---------4---------5---------6---\n"+
+		"#PlanetName   Loc. UPP Code   B   Notes         Z  PBG Al LRX *\n"+
+		"#----------   ---- ---------  - --------------- -  --- -- ------\n", s.name); err != nil {
+		return err
+	}
+	for i := range s.worlds {
+		r := recordFromWorld(&s.worlds[i])
+		bases := r.Bases
+		if b, ok := secLegacyBases[bases]; ok {
+			bases = b
+		}
+		if _, err := fmt.Fprintf(w, "%-14.14s%-4.4s %-9.9s  %-1.1s %-15.15s %-1.1s  %-3.3s %-2.2s %s\n", r.Name, r.Hex,
+			r.UWP, bases, r.Remarks, r.Zone, r.PBG, r.Allegiance, r.Stars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonSector is a sector as written to a JSON file.
+type jsonSector struct {
+	Name         string
+	Abbreviation string `json:",omitempty"`
+	Worlds       []worldRecord
+}
+
+// detectJSON recognises a JSON file, which starts with an object or a list of worlds.
+func detectJSON(lines []string) bool {
+	for _, l := range lines {
+		if t := strings.TrimSpace(l); t != "" {
+			return strings.HasPrefix(t, "{") || strings.HasPrefix(t, "[")
+		}
+	}
+	return false
+}
+
+// readJSON reads a JSON file holding a sector, or just a list of worlds. The errors are numbered by world, as the
+// worlds need not be on lines of their own.
+func readJSON(data []byte) (fr formatResult, err error) {
+	fr.what = "world"
+	var js jsonSector
+	if t := bytes.TrimSpace(data); len(t) > 0 && t[0] == '[' {
+		err = json.Unmarshal(data, &js.Worlds)
+	} else {
+		err = json.Unmarshal(data, &js)
+	}
+	if err != nil {
+		return fr, err
+	}
+	fr.name, fr.abbrev = js.Name, js.Abbreviation
+	for i, r := range js.Worlds {
+		fr.addRecord(i+1, r)
+	}
+	return
+}
+
+// writeJSON writes a JSON file holding the sector's name and its worlds.
+func writeJSON(w io.Writer, s *sector) error {
+	js := jsonSector{Name: s.name, Abbreviation: s.abbrev, Worlds: make([]worldRecord, len(s.worlds))}
+	for i := range s.worlds {
+		js.Worlds[i] = recordFromWorld(&s.worlds[i])
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(js)
+}
@@ -0,0 +1,300 @@
+package main
+
+// habitableZone.go contains the habitable zone calculations. The method used is selectable per campaign (in the
+// configuration file), from the Classic Traveller Book 6 table, the MegaTraveller table, the T5 table, or a continuous
+// calculation from the luminosity of the star.
+
+import (
+	"math"
+	"strings"
+)
+
+// hzMethod is a method of calculating the habitable zone of a star.
+type hzMethod int
+
+// Constants for the habitable zone methods.
+const (
+	HzBook6 hzMethod = iota
+	HzMegaTraveller
+	HzTraveller5
+	HzLuminosity
+)
+
+// String returns a string describing the habitable zone method.
+func (m hzMethod) String() string {
+	return [...]string{"Book6", "MegaTraveller", "T5", "Luminosity"}[m]
+}
+
+// parseHzMethod returns the habitable zone method named in the string (case insensitive), or HzBook6 if it is not recognised.
+func parseHzMethod(s string) hzMethod {
+	for m := HzBook6; m <= HzLuminosity; m++ {
+		if strings.EqualFold(s, m.String()) {
+			return m
+		}
+	}
+	return HzBook6
+}
+
+// Limits of the continuous habitable zone, as light received compared to Earth.
+const (
+	hzInnerFlux = 1.1
+	hzOuterFlux = 0.53
+)
+
+// habitableZone contains the zones around a star. Orbits inside the habitable zone are in the inner zone, and those
+// outside are in the outer zone.
+type habitableZone struct {
+	Method     string  // The method used, eg "Book6"
+	Orbit      int     // The habitable zone orbit number. -1 if the star has no habitable zone.
+	OrbitExact float64 // The habitable zone orbit, fractional for the luminosity method
+	InnerAU    float64 // The inner boundary of the habitable zone, in AU
+	OuterAU    float64 // The outer boundary of the habitable zone, in AU
+}
+
+// configuredHzMethod returns the habitable zone method chosen for the campaign in the configuration.
+func configuredHzMethod() hzMethod {
+	return parseHzMethod(config.HabitableZoneMethod)
+}
+
+// habitableZoneFor works out the habitable zone for a star using the method. If the star has a companion, the pair is treated
+// as one: the luminosity method adds their luminosities, and the table methods use the brighter star.
+func habitableZoneFor(s *starDetail, method hzMethod) (z habitableZone, err error) {
+
+	sm, err := s.model()
+	if err != nil {
+		return z, err
+	}
+	brightest, luminosity := *s, sm.Luminosity
+	if s.companion != nil {
+		cm, err := s.companion.model()
+		if err != nil {
+			return z, err
+		}
+		if cm.Luminosity > sm.Luminosity {
+			brightest, sm = *s.companion, cm
+		}
+		luminosity += cm.Luminosity
+	}
+
+	z.Method = method.String()
+	switch method {
+	case HzMegaTraveller:
+		z.Orbit = mtHabitableZone(brightest)
+	case HzTraveller5:
+		z.Orbit = t5HabitableZone(brightest)
+	case HzLuminosity:
+		z.OrbitExact = auToOrbit(math.Sqrt(luminosity))
+		z.Orbit = int(math.Round(z.OrbitExact))
+		z.InnerAU = math.Sqrt(luminosity / hzInnerFlux)
+		z.OuterAU = math.Sqrt(luminosity / hzOuterFlux)
+		if z.Orbit < 0 {
+			z.Orbit = -1
+		}
+		return z, nil
+	default:
+		z.Orbit = sm.HabitableZone
+	}
+
+	if z.Orbit < 0 || (brightest.spectralType == "BD" && method != HzBook6) {
+		z.Orbit = -1
+		return z, nil
+	}
+	z.OrbitExact = float64(z.Orbit)
+	z.InnerAU = orbitToAU(z.OrbitExact - 0.5)
+	z.OuterAU = orbitToAU(z.OrbitExact + 0.5)
+	return z, nil
+}
+
+// habitableZonesForSystem works out the habitable zone of each of the (top-level) stars of a system, with companions treated as
+// part of the star they accompany. Companions close to their star share its zones, while the close, near and far stars have their own.
+func habitableZonesForSystem(ss []*starDetail, method hzMethod) (zones []habitableZone, err error) {
+	for _, s := range ss {
+		z, err := habitableZoneFor(s, method)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, z)
+	}
+	return
+}
+
+// zoneOf returns the zone that the orbit is in: negative for the inner zone, zero for the habitable zone and positive for the
+// outer zone. This is the habitable zone variance of a world in the orbit.
+func (z habitableZone) zoneOf(orbit float64) int {
+	au := orbitToAU(orbit)
+	switch {
+	case z.Orbit < 0 || au > z.OuterAU:
+		return int(math.Max(1, math.Round(orbit-z.OrbitExact)))
+	case au < z.InnerAU:
+		return int(math.Min(-1, math.Round(orbit-z.OrbitExact)))
+	default:
+		return 0
+	}
+}
+
+// habitableZoneOrbit returns the habitable zone orbit of the star using the campaign's method, or 0 if it cannot be worked out.
+func (s *starDetail) habitableZoneOrbit() int {
+	z, err := habitableZoneFor(s, configuredHzMethod())
+	if err != nil || z.Orbit < 0 {
+		return 0
+	}
+	return z.Orbit
+}
+
+// mtHabitableZone returns the Orbit number for the Habitable zone of a star from the MegaTraveller table, based on its Type
+// (Spectral Class) and Size (Luminosity).
+func mtHabitableZone(s starDetail) int {
+
+	// Brown Dwarfs do not have a habitable zone
+	if s.spectralType == "BD" {
+		return 0
+	}
+	// The other easy case is the Dwarf.
+	if s.size == "D" {
+		if s.spectralType == "O" {
+			return 1
+		}
+		return 0
+	}
+
+	// All other spectral types have a habitable zone somewhere.
+	switch s.spectralType {
+	case "O":
+		switch s.size {
+		case "II":
+			return 14
+		case "III":
+			return 13
+		case "IV":
+			return 12
+		case "V":
+			return 11
+		default:
+			return 15
+		}
+	case "B":
+		switch s.size {
+		case "II":
+			return 12
+		case "III":
+			return 11
+		case "IV":
+			return 10
+		case "V":
+			return 9
+		default:
+			return 13
+		}
+	case "A":
+		switch s.size {
+		case "Ia":
+			return 12
+		case "Ib":
+			return 11
+		case "II":
+			return 9
+		default:
+			return 7
+		}
+	case "F":
+		switch s.size {
+		case "Ia":
+			return 11
+		case "Ib":
+			return 10
+		case "II":
+			return 9
+		case "V":
+			return 5
+		case "VI":
+			return 3
+		default:
+			return 6
+		}
+	case "G":
+		switch s.size {
+		case "Ia":
+			return 12
+		case "Ib":
+			return 10
+		case "II":
+			return 9
+		case "III":
+			return 7
+		case "IV":
+			return 5
+		case "V":
+			return 3
+		default:
+			return 2
+		}
+	case "K":
+		switch s.size {
+		case "Ia":
+			return 12
+		case "Ib":
+			return 10
+		case "II":
+			return 9
+		case "III":
+			return 8
+		case "IV":
+			return 5
+		case "V":
+			return 2
+		default:
+			return 1
+		}
+	default:
+		// Only "M" left
+		switch s.size {
+		case "Ia":
+			return 12
+		case "Ib":
+			return 11
+		case "II":
+			return 10
+		case "III":
+			return 9
+		default:
+			return 0
+		}
+	}
+}
+
+// t5HabitableZones are the habitable zone orbits of the T5 table, by size, for the spectral types O0, O5, B0, B5, A0,
+// A5, F0, F5, G0, G5, K0, K5, M0, M5 and M9. Stars of a type and size that the table has no orbit for are -1.
+var t5HabitableZones = map[string][15]int{
+	"Ia":  {15, 15, 14, 13, 12, 11, 11, 11, 12, 12, 12, 12, 12, 12, 12},
+	"Ib":  {15, 15, 14, 13, 12, 11, 10, 10, 10, 10, 10, 10, 11, 11, 11},
+	"II":  {14, 14, 13, 12, 11, 10, 9, 9, 9, 9, 9, 9, 10, 10, 10},
+	"III": {13, 13, 12, 11, 10, 9, 8, 7, 7, 7, 8, 8, 9, 9, 9},
+	"IV":  {12, 12, 11, 10, 9, 8, 7, 6, 5, 5, 5, 5, -1, -1, -1},
+	"V":   {11, 11, 10, 9, 8, 7, 6, 5, 3, 3, 2, 2, 0, 0, 0},
+	"VI":  {-1, -1, -1, -1, -1, -1, -1, 3, 2, 2, 1, 1, 0, 0, 0},
+}
+
+// t5HabitableZone returns the Orbit number for the Habitable zone of a star from the T5 table, based on its Type
+// (Spectral Class) and decimal, and its Size. The decimal is rounded down to the table's column, 0 or 5 (or 9 for M9).
+// Dwarfs have their habitable zone in orbit 0, and stars of a size the table has no orbit for use the main sequence.
+func t5HabitableZone(s starDetail) int {
+
+	class := strings.Index("OBAFGKM", s.spectralType)
+	if s.spectralType == "BD" || class < 0 {
+		return -1
+	}
+	if s.size == "D" {
+		return 0
+	}
+	col := class * 2
+	switch {
+	case class == 6 && s.spectralDecimal == 9:
+		col = 14
+	case s.spectralDecimal >= 5:
+		col++
+	}
+	if zs, ok := t5HabitableZones[s.size]; ok && zs[col] >= 0 {
+		return zs[col]
+	}
+	return t5HabitableZones["V"][col]
+}
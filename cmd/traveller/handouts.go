@@ -0,0 +1,405 @@
+package main
+
+// handouts.go contains code for writing one-page sheets about a world, in Markdown, HTML and PDF. The player handout
+// has only what a visiting traveller would know: the starport, the law level's restrictions, the trade codes, any
+// travel zone warning and a short description. The referee sheet adds the system, bases, nobility, extensions, the
+// referee's notes and adventure hooks. The sheets are made from templates, which the user can customise by putting
+// their own versions in the templates directory (see writeDefaultHandoutTemplates).
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"trav2/cmd/traveller/pdf"
+)
+
+// handoutKind is the kind of sheet written for a world.
+type handoutKind int
+
+// The kinds of sheet.
+const (
+	handoutPlayer handoutKind = iota
+	handoutReferee
+)
+
+// String returns the name of the kind of sheet, which is also the start of its template's filename.
+func (k handoutKind) String() string {
+	return [...]string{"player", "referee"}[k]
+}
+
+// handoutZoneWarnings are the warnings given to travellers about amber and red zones.
+var handoutZoneWarnings = map[TravelZone]string{
+	TzAmber: "AMBER ZONE: travellers are advised to use caution on this world.",
+	TzRed:   "RED ZONE: this world is interdicted. Travel to it is forbidden.",
+}
+
+// handoutHooks are adventure hooks for worlds with the trade codes.
+var handoutHooks = map[string]string{
+	"Ag": "A blight threatens the harvest, and the growers' co-operative is hiring off-worlders who ask no questions.",
+	"As": "A prospector's claim in the belt has gone silent, and the claim's backers want to know why.",
+	"Ba": "Survey records show a structure on the surface that was not there at the last survey.",
+	"De": "A water merchant has cornered the market, and someone wants the cartel broken.",
+	"Fl": "An offworld firm wants samples from the fluid oceans, without the government finding out.",
+	"Hi": "A missing person is somewhere among the billions, and the local police have stopped looking.",
+	"Ht": "A research firm is recruiting couriers to move prototypes past export controls.",
+	"Ic": "A research station under the ice has stopped reporting.",
+	"In": "Industrial espionage: a rival wants the plans of a new manufacturing process.",
+	"Lo": "The few inhabitants are hiding something, and do not welcome questions.",
+	"Ni": "The world needs manufactured goods badly, and will pay well for a cargo delivered on time.",
+	"Po": "Locals offer everything they have for passage offworld.",
+	"Ri": "A wealthy patron needs discreet bodyguards for a family visit to the capital.",
+	"Va": "A pressure failure at a dome settlement needs an emergency crew at once.",
+	"Wa": "A sunken wreck holds a cargo that its insurers want recovered.",
+}
+
+// handoutExtraHooks are adventure hooks for other things about a world, tested in order.
+var handoutExtraHooks = []struct {
+	applies func(w *world) bool
+	hook    string
+}{
+	{func(w *world) bool { return w.zone == TzAmber }, "The reason for the amber zone is not what the official notice says."},
+	{func(w *world) bool { return w.zone == TzRed }, "Someone offers a great deal of money to be landed on the world, quietly."},
+	{func(w *world) bool { return w.uwp.lawInt >= 9 }, "A traveller's routine cargo is seized as contraband under local law."},
+	{func(w *world) bool { return w.uwp.starport == "X" }, "A ship in distress has put down here, far from any help."},
+	{func(w *world) bool { return strings.ContainsAny(w.bases, "NKA") }, "The naval base is quietly recruiting civilian ships for a patrol."},
+	{func(w *world) bool { return strings.ContainsAny(w.bases, "SWAB") }, "The scouts need a message carried to a survey team that is overdue."},
+}
+
+// handoutData holds what the templates show about a world. The fields after Zone are for the referee sheet.
+type handoutData struct {
+	Name, Sector, Subsector, Hex, UWP string
+	StarportCode, Starport            string
+	Description                       string
+	LawLevel, LawRestrictions         string
+	TradeCodes                        []string // The trade codes with their meanings, eg "Ag (Agricultural)"
+	Zone, ZoneWarning                 string
+
+	Bases                                    []string
+	PBG, Allegiance, Stars                   string
+	Extended                                 bool // Whether the world has the T5 extensions
+	Importance, Economics, Culture, Nobility string
+	Worlds, RU                               int
+	System                                   string
+	SystemGenerated                          bool // Whether the system was generated for the sheet
+	Notes, Hooks                             []string
+}
+
+// playerDescription describes the world in words, as a visiting traveller would see it.
+func playerDescription(w *world) string {
+	u := w.uwp
+	s := fmt.Sprintf("A world of %s diameter, with %s atmosphere and %s.", tableEntry(tableSizes[:], u.sizeInt),
+		strings.ToLower(tableEntry(tableAtmospheres[:], u.atmInt)), strings.ToLower(tableEntry(tableHydrographics[:], u.hydInt)))
+	if u.popInt <= 0 {
+		return s + " There are no permanent inhabitants."
+	}
+	return s + fmt.Sprintf(" The population is in the %s, under a %s. Technology is at the level of %s.",
+		strings.ToLower(tableEntry(tablePopulations[:], u.popInt)), strings.ToLower(tableEntry(tableGovernments[:], u.govInt)),
+		strings.ToLower(tableEntry(tableTechLevels[:], u.techInt)))
+}
+
+// worldHooks returns adventure hooks for the world, from its trade codes and other features.
+func worldHooks(w *world) (hs []string) {
+	for _, tc := range strings.Fields(w.remarks) {
+		if h, ok := handoutHooks[tc]; ok {
+			hs = append(hs, h)
+		}
+	}
+	for _, e := range handoutExtraHooks {
+		if e.applies(w) {
+			hs = append(hs, e.hook)
+		}
+	}
+	return
+}
+
+// newHandoutData collects what the sheets show about the world in the sector. The system is the one stored for the
+// world if there is one, or else one is generated for the sheet (and not stored).
+func newHandoutData(w *world, sectorName, notesDir string) *handoutData {
+	d := &handoutData{Name: w.name, Sector: sectorName, Subsector: w.subsector, Hex: w.hexLoc.String(),
+		UWP: w.uwp.String(), StarportCode: w.uwp.starport, Starport: tableStarport(w.uwp.starport),
+		Description: playerDescription(w), LawLevel: Ehex(w.uwp.lawInt).String(),
+		LawRestrictions: tableEntry(tableLawLevels[:], w.uwp.lawInt), Zone: w.zone.Desc(),
+		ZoneWarning: handoutZoneWarnings[w.zone], PBG: w.pbg.String(), Allegiance: w.allegiance,
+		Stars: w.systemStarString(), Extended: w.genType == WgtT5ss, Importance: w.importance.String(),
+		Economics: w.economics.String(), Culture: w.culture.String(), Nobility: w.nobility, Worlds: w.worlds, RU: w.ru,
+		Notes: worldNotes(notesDir, sectorName, w), Hooks: worldHooks(w)}
+	for _, tc := range strings.Fields(w.remarks) {
+		if desc, ok := tableTradeCodes[tc]; ok {
+			tc = fmt.Sprintf("%s (%s)", tc, desc)
+		}
+		d.TradeCodes = append(d.TradeCodes, tc)
+	}
+	for _, b := range w.bases {
+		if desc, ok := tableBases[b]; ok {
+			d.Bases = append(d.Bases, fmt.Sprintf("%c (%s)", b, desc))
+		}
+	}
+	if d.System = storedSystem(w); d.System == "" && w.stars != nil {
+		if sys, err := generateCanonSystem(*w); err == nil {
+			d.System, d.SystemGenerated = sys.String(), true
+		}
+	}
+	return d
+}
+
+// The default templates of the sheets. The Markdown templates are also used to lay out the PDF sheets.
+const (
+	playerMarkdown = `# {{.Name}}
+{{.Sector}} {{.Hex}}{{if .Subsector}}, {{.Subsector}} subsector{{end}}. UWP {{.UWP}}.
+{{if .ZoneWarning}}
+**{{.ZoneWarning}}**
+{{end}}
+## Starport
+{{.StarportCode}}: {{.Starport}}.
+
+## Law
+Law level {{.LawLevel}}: {{.LawRestrictions}}.
+
+## Trade codes
+{{range .TradeCodes}}- {{.}}
+{{else}}None.
+{{end}}
+## Description
+{{.Description}}
+`
+	refereeMarkdown = `# {{.Name}} (referee)
+{{.Sector}} {{.Hex}}{{if .Subsector}}, {{.Subsector}} subsector{{end}}. UWP {{.UWP}}. {{.Zone}} zone.
+
+{{.Description}} Starport {{.StarportCode}}: {{.Starport}}. Law level {{.LawLevel}}: {{.LawRestrictions}}.
+
+## Details
+- Trade codes: {{range $i, $t := .TradeCodes}}{{if $i}}, {{end}}{{$t}}{{else}}none{{end}}
+- Bases: {{range $i, $b := .Bases}}{{if $i}}, {{end}}{{$b}}{{else}}none{{end}}
+- PBG {{.PBG}}, allegiance {{or .Allegiance "none"}}
+{{if .Extended}}- Importance {{.Importance}}, economics {{.Economics}}, culture {{.Culture}}
+- Nobility: {{or .Nobility "none"}}. Worlds: {{.Worlds}}. RU: {{.RU}}
+{{end}}
+## System{{if .SystemGenerated}} (generated for this sheet){{end}}
+{{if .System}}` + "```" + `
+{{.System}}` + "```" + `
+{{else}}Stars: {{or .Stars "unknown"}}.
+{{end}}
+## Notes
+{{range .Notes}}{{.}}
+
+{{else}}No notes.
+
+{{end}}## Hooks
+{{range .Hooks}}- {{.}}
+{{else}}None.
+{{end}}`
+	playerHTML = `<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8"><title>{{.Name}}</title>
+<style>body { font-family: sans-serif; max-width: 40em; margin: 2em auto; } .warning { color: #b00; font-weight: bold; }</style>
+</head><body>
+<h1>{{.Name}}</h1>
+<p>{{.Sector}} {{.Hex}}{{if .Subsector}}, {{.Subsector}} subsector{{end}}. UWP <code>{{.UWP}}</code>.</p>
+{{if .ZoneWarning}}<p class="warning">{{.ZoneWarning}}</p>{{end}}
+<h2>Starport</h2><p>{{.StarportCode}}: {{.Starport}}.</p>
+<h2>Law</h2><p>Law level {{.LawLevel}}: {{.LawRestrictions}}.</p>
+<h2>Trade codes</h2>{{if .TradeCodes}}<ul>{{range .TradeCodes}}<li>{{.}}</li>{{end}}</ul>{{else}}<p>None.</p>{{end}}
+<h2>Description</h2><p>{{.Description}}</p>
+</body></html>
+`
+	refereeHTML = `<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8"><title>{{.Name}} (referee)</title>
+<style>body { font-family: sans-serif; max-width: 40em; margin: 2em auto; } pre { background: #eee; padding: 0.5em; }</style>
+</head><body>
+<h1>{{.Name}} (referee)</h1>
+<p>{{.Sector}} {{.Hex}}{{if .Subsector}}, {{.Subsector}} subsector{{end}}. UWP <code>{{.UWP}}</code>. {{.Zone}} zone.</p>
+<p>{{.Description}} Starport {{.StarportCode}}: {{.Starport}}. Law level {{.LawLevel}}: {{.LawRestrictions}}.</p>
+<h2>Details</h2>
+<ul>
+<li>Trade codes: {{range $i, $t := .TradeCodes}}{{if $i}}, {{end}}{{$t}}{{else}}none{{end}}</li>
+<li>Bases: {{range $i, $b := .Bases}}{{if $i}}, {{end}}{{$b}}{{else}}none{{end}}</li>
+<li>PBG {{.PBG}}, allegiance {{or .Allegiance "none"}}</li>
+{{if .Extended}}<li>Importance {{.Importance}}, economics {{.Economics}}, culture {{.Culture}}</li>
+<li>Nobility: {{or .Nobility "none"}}. Worlds: {{.Worlds}}. RU: {{.RU}}</li>{{end}}
+</ul>
+<h2>System{{if .SystemGenerated}} (generated for this sheet){{end}}</h2>
+{{if .System}}<pre>{{.System}}</pre>{{else}}<p>Stars: {{or .Stars "unknown"}}.</p>{{end}}
+<h2>Notes</h2>{{range .Notes}}<p>{{.}}</p>{{else}}<p>No notes.</p>{{end}}
+<h2>Hooks</h2>{{if .Hooks}}<ul>{{range .Hooks}}<li>{{.}}</li>{{end}}</ul>{{else}}<p>None.</p>{{end}}
+</body></html>
+`
+)
+
+// handoutTemplateDir returns the directory where the user's own templates are looked for.
+func handoutTemplateDir() string {
+	return filepath.Join(config.DataDir, "templates")
+}
+
+// handoutTemplate returns the template for the kind of sheet in the format ("md" or "html"): the user's own from the
+// templates directory, eg "player.md", or else the default.
+func handoutTemplate(k handoutKind, format string) string {
+	if data, err := os.ReadFile(filepath.Join(handoutTemplateDir(), k.String()+"."+format)); err == nil {
+		return string(data)
+	}
+	defaults := map[string][2]string{"md": {playerMarkdown, refereeMarkdown}, "html": {playerHTML, refereeHTML}}
+	return defaults[format][k]
+}
+
+// writeDefaultHandoutTemplates writes the default templates to the templates directory for the user to customise.
+// Templates already there are kept. It returns a status message for display.
+func writeDefaultHandoutTemplates() string {
+	dir := handoutTemplateDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Sprintf("Unable to create %s: %v", dir, err)
+	}
+	var written []string
+	for _, t := range []struct {
+		name, text string
+	}{{"player.md", playerMarkdown}, {"referee.md", refereeMarkdown}, {"player.html", playerHTML},
+		{"referee.html", refereeHTML}} {
+		fn := filepath.Join(dir, t.name)
+		if _, err := os.Stat(fn); err == nil {
+			continue
+		}
+		if err := os.WriteFile(fn, []byte(t.text), 0644); err != nil {
+			return fmt.Sprintf("Unable to write %s: %v", fn, err)
+		}
+		written = append(written, t.name)
+	}
+	if len(written) == 0 {
+		return "The templates in " + dir + " are already there"
+	}
+	return fmt.Sprintf("Written %s to %s", strings.Join(written, ", "), dir)
+}
+
+// renderHandout fills in the template of the kind of sheet in the format ("md" or "html") with the world's data.
+func renderHandout(k handoutKind, format string, d *handoutData) (string, error) {
+	var b bytes.Buffer
+	if format == "html" {
+		t, err := htmltemplate.New(k.String()).Parse(handoutTemplate(k, format))
+		if err != nil {
+			return "", err
+		}
+		err = t.Execute(&b, d)
+		return b.String(), err
+	}
+	t, err := template.New(k.String()).Parse(handoutTemplate(k, format))
+	if err != nil {
+		return "", err
+	}
+	err = t.Execute(&b, d)
+	return b.String(), err
+}
+
+// Sizes for the PDF sheets, in points, before they are scaled down to fit on the page.
+const (
+	handoutMargin  = 50.0
+	handoutTitle   = 20.0
+	handoutHeading = 13.0
+	handoutText    = 10.5
+)
+
+// layoutMarkdownPage lays out simple Markdown (headings, bullets, fenced code blocks, bold and paragraphs) on the
+// page, with its sizes scaled. It returns whether it all fitted on the page.
+func layoutMarkdownPage(p *pdf.Page, md string, scale float64) bool {
+	width, height := p.Size()
+	y := handoutMargin
+	text := func(f pdf.Font, size, indent float64, s string) {
+		size *= scale
+		words := strings.Fields(s)
+		for len(words) > 0 {
+			n := 1
+			for n < len(words) && pdf.TextWidth(f, size, strings.Join(words[:n+1], " ")) <= width-2*handoutMargin-indent {
+				n++
+			}
+			y += size * 1.3
+			p.Text(handoutMargin+indent, y, f, size, strings.Join(words[:n], " "))
+			words = words[n:]
+		}
+	}
+	code := false
+	for _, line := range strings.Split(md, "\n") {
+		switch {
+		case strings.HasPrefix(line, "```"):
+			code = !code
+		case code:
+			y += handoutText * scale * 1.2
+			p.Text(handoutMargin, y, pdf.Courier, handoutText*scale*0.9, line)
+		case strings.TrimSpace(line) == "":
+			y += handoutText * scale * 0.5
+		case strings.HasPrefix(line, "# "):
+			text(pdf.HelveticaBold, handoutTitle, 0, line[2:])
+			y += handoutText * scale * 0.3
+		case strings.HasPrefix(line, "## "):
+			y += handoutText * scale * 0.3
+			text(pdf.HelveticaBold, handoutHeading, 0, line[3:])
+		case strings.HasPrefix(line, "- "):
+			p.Text(handoutMargin, y+handoutText*scale*1.3, pdf.Helvetica, handoutText*scale, "-")
+			text(pdf.Helvetica, handoutText, 12*scale, line[2:])
+		case strings.HasPrefix(line, "**") && strings.HasSuffix(line, "**"):
+			text(pdf.HelveticaBold, handoutText, 0, strings.Trim(line, "*"))
+		default:
+			text(pdf.Helvetica, handoutText, 0, strings.ReplaceAll(line, "**", ""))
+		}
+	}
+	return y <= height-handoutMargin
+}
+
+// handoutPDF lays out the Markdown sheet as a one page PDF, shrinking the text until it fits.
+func handoutPDF(title, md string) *pdf.Document {
+	for scale := 1.0; ; scale -= 0.05 {
+		doc := pdf.New(title)
+		if layoutMarkdownPage(doc.AddPage(pdf.A4), md, scale) || scale <= 0.5 {
+			return doc
+		}
+	}
+}
+
+// handoutFilename returns the name of the file for the kind of sheet for the world, eg "Regina 1910 player.md".
+func handoutFilename(dir string, w *world, k handoutKind, ext string) string {
+	name := strings.TrimSuffix(sectorFilename(fmt.Sprintf("%s %s %s", w.name, w.hexLoc.String(), k)), ".tab")
+	return filepath.Join(dir, name+"."+ext)
+}
+
+// writeHandouts writes the player handout and referee sheet for the world in the sector to the directory, each as
+// Markdown, HTML and PDF.
+func writeHandouts(w *world, sectorName, dir, notesDir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	d := newHandoutData(w, sectorName, notesDir)
+	for _, k := range []handoutKind{handoutPlayer, handoutReferee} {
+		var md string
+		for _, format := range []string{"md", "html"} {
+			out, err := renderHandout(k, format, d)
+			if err != nil {
+				return fmt.Errorf("unable to fill in the %s %s template: %w", k, format, err)
+			}
+			if format == "md" {
+				md = out
+			}
+			if err = os.WriteFile(handoutFilename(dir, w, k, format), []byte(out), 0644); err != nil {
+				return err
+			}
+		}
+		if err := handoutPDF(fmt.Sprintf("%s (%s)", w.name, k), md).WriteFile(handoutFilename(dir, w, k, "pdf")); err != nil {
+			return err
+		}
+	}
+	log.Printf("Handouts for %s written to %s", w.name, dir)
+	return nil
+}
+
+// findSectorWorld loads the named sector, with the campaign's overlay, and returns its world in the hex. It returns
+// ErrWorldNotFound if there is no world in the hex.
+func findSectorWorld(sectorName, hex string) (*world, error) {
+	s, err := loadCampaignSector(sectorName, config.Campaign)
+	if err != nil {
+		return nil, err
+	}
+	for i := range s.worlds {
+		if s.worlds[i].hexLoc.String() == hex {
+			return &s.worlds[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrWorldNotFound, sectorName, hex)
+}
@@ -0,0 +1,630 @@
+package main
+
+// hexMap.go contains code for drawing sector, quadrant and subsector hex maps, in the manner of the maps on
+// travellermap.com, and saving them as SVG or PNG images. A map shows each world's glyph, starport, bases, gas
+// giants, travel zone and name, with the allegiance borders and routes. Maps are drawn from the sector alone, so
+// they work offline for generated and edited sectors as well as canon ones.
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// hexMapScope is how much of a sector a hex map shows.
+type hexMapScope int
+
+// The scopes of a hex map.
+const (
+	scopeSector    hexMapScope = iota // The whole sector, 32 by 40 hexes
+	scopeQuadrant                     // A quadrant of four subsectors, 16 by 20 hexes
+	scopeSubsector                    // A single subsector, 8 by 10 hexes
+)
+
+// String returns the name of the scope, eg "Quadrant".
+func (sc hexMapScope) String() string {
+	return [...]string{"Sector", "Quadrant", "Subsector"}[sc]
+}
+
+// quadrantNames are the names of the quadrants of a sector, from top left to bottom right.
+var quadrantNames = [...]string{"Alpha", "Beta", "Gamma", "Delta"}
+
+// hexMapStyle is the look of a hex map.
+type hexMapStyle int
+
+// The styles of a hex map.
+const (
+	stylePrint  hexMapStyle = iota // Black on white, for printing
+	stylePoster                    // Bright on black, like travellermap.com
+	styleAtlas                     // Muted colours on parchment, like a published atlas
+)
+
+// String returns the name of the style, eg "Poster".
+func (st hexMapStyle) String() string {
+	return [...]string{"Print", "Poster", "Atlas"}[st]
+}
+
+// mapPalette holds the colours a hex map is drawn in.
+type mapPalette struct {
+	background, grid, text, hexNumber color.RGBA
+	wet, dry                          color.RGBA // The world glyphs, with and without water
+	amber, red                        color.RGBA // The travel zones
+	xboat, major, minor               color.RGBA // The routes
+	base                              color.RGBA // The base symbols
+	borders                           []color.RGBA
+}
+
+// rgb returns the opaque colour.
+func rgb(r, g, b uint8) color.RGBA {
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}
+}
+
+// noColour is used to draw shapes without a fill or outline.
+var noColour = color.RGBA{}
+
+// mapPalettes contains the palette of each style. The border colours are given to the allegiances in turn.
+var mapPalettes = [...]mapPalette{
+	stylePrint: {background: rgb(0xff, 0xff, 0xff), grid: rgb(0xa0, 0xa0, 0xa0), text: rgb(0, 0, 0),
+		hexNumber: rgb(0x80, 0x80, 0x80), wet: rgb(0x20, 0x60, 0xc0), dry: rgb(0, 0, 0), amber: rgb(0xe0, 0xa0, 0),
+		red: rgb(0xd0, 0, 0), xboat: rgb(0x60, 0x60, 0x60), major: rgb(0xc0, 0x80, 0), minor: rgb(0xa0, 0x80, 0x40),
+		base: rgb(0, 0, 0), borders: []color.RGBA{rgb(0xc0, 0, 0), rgb(0, 0x40, 0xc0), rgb(0, 0x80, 0),
+			rgb(0x80, 0, 0x80), rgb(0xe0, 0x60, 0), rgb(0, 0x80, 0x80)}},
+	stylePoster: {background: rgb(0, 0, 0), grid: rgb(0x60, 0x60, 0x60), text: rgb(0xff, 0xff, 0xff),
+		hexNumber: rgb(0x90, 0x90, 0x90), wet: rgb(0, 0xbf, 0xff), dry: rgb(0xff, 0xff, 0xff), amber: rgb(0xff, 0xcc, 0),
+		red: rgb(0xff, 0x30, 0x30), xboat: rgb(0x90, 0x90, 0x90), major: rgb(0xff, 0xa0, 0), minor: rgb(0xc0, 0x90, 0x40),
+		base: rgb(0xff, 0xff, 0xff), borders: []color.RGBA{rgb(0xff, 0, 0xff), rgb(0, 0xff, 0xff), rgb(0x40, 0xff, 0x40),
+			rgb(0xff, 0x80, 0), rgb(0xff, 0xff, 0x40), rgb(0x80, 0x80, 0xff)}},
+	styleAtlas: {background: rgb(0xf5, 0xf0, 0xe1), grid: rgb(0x9a, 0xb4, 0xc8), text: rgb(0x20, 0x20, 0x40),
+		hexNumber: rgb(0x70, 0x80, 0x90), wet: rgb(0x2a, 0x6f, 0xb0), dry: rgb(0x5a, 0x4a, 0x3a), amber: rgb(0xe0, 0x90, 0),
+		red: rgb(0xc0, 0x20, 0x20), xboat: rgb(0x60, 0x60, 0x90), major: rgb(0xc0, 0x80, 0), minor: rgb(0xb0, 0x90, 0x50),
+		base: rgb(0x20, 0x20, 0x40), borders: []color.RGBA{rgb(0xa0, 0x30, 0x30), rgb(0x30, 0x50, 0x90),
+			rgb(0x40, 0x70, 0x30), rgb(0x70, 0x40, 0x80), rgb(0xb0, 0x70, 0x20), rgb(0x30, 0x70, 0x70)}},
+}
+
+// mapCanvas is something a hex map can be drawn on. Shapes drawn with noColour for the fill or outline are not
+// filled or outlined. Text is centred on the point.
+type mapCanvas interface {
+	polygon(pts []mapPoint, fill, stroke color.RGBA, width float64)
+	line(a, b mapPoint, col color.RGBA, width float64, dashed bool)
+	circle(c mapPoint, r float64, fill, stroke color.RGBA, width float64)
+	text(p mapPoint, size float64, col color.RGBA, s string)
+}
+
+// hexMap is a hex map of a sector, or part of one.
+type hexMap struct {
+	sector  *sector
+	scope   hexMapScope
+	index   int         // The quadrant (0 to 3) or subsector (0 to 15) shown
+	style   hexMapStyle // The look of the map
+	radius  float64     // The radius of a hex, in pixels
+	x0, y0  int         // The top left hex shown
+	columns int         // The number of columns of hexes shown
+	rows    int         // The number of rows of hexes shown
+}
+
+// newHexMap returns a hex map of the scope of the sector, with hexes of the radius in pixels. The index is the
+// quadrant or subsector to show, and is ignored for the whole sector.
+func newHexMap(s *sector, scope hexMapScope, index int, style hexMapStyle, radius float64) *hexMap {
+	m := &hexMap{sector: s, scope: scope, style: style, radius: radius, x0: 1, y0: 1, columns: sectorWidth,
+		rows: sectorHeight}
+	switch scope {
+	case scopeQuadrant:
+		m.index = minInt(maxInt(index, 0), len(quadrantNames)-1)
+		m.x0, m.y0, m.columns, m.rows = 1+16*(m.index%2), 1+20*(m.index/2), 16, 20
+	case scopeSubsector:
+		m.index = minInt(maxInt(index, 0), len(ssIndex)-1)
+		m.x0, m.y0, m.columns, m.rows = 1+8*(m.index%4), 1+10*(m.index/4), 8, 10
+	}
+	return m
+}
+
+// title returns the title of the map, eg "Foreven Sector" or "Alpha Quadrant, Foreven Sector".
+func (m *hexMap) title() string {
+	switch m.scope {
+	case scopeQuadrant:
+		return fmt.Sprintf("%s Quadrant, %s Sector", quadrantNames[m.index], m.sector.name)
+	case scopeSubsector:
+		if name := m.sector.subsectors[m.index].name; name != "" {
+			return fmt.Sprintf("%s Subsector (%s), %s Sector", name, ssIndex[m.index], m.sector.name)
+		}
+		return fmt.Sprintf("Subsector %s, %s Sector", ssIndex[m.index], m.sector.name)
+	}
+	return m.sector.name + " Sector"
+}
+
+// halfHeight returns half the height of a hex, from its centre to the middle of its top side.
+func (m *hexMap) halfHeight() float64 {
+	return m.radius * math.Sqrt(3) / 2
+}
+
+// size returns the size of the map, in pixels. There is a margin of a hex radius around the hexes, and room for
+// the title above them.
+func (m *hexMap) size() (width, height float64) {
+	r := m.radius
+	return r*(1.5*float64(m.columns)+0.5) + 2*r, m.halfHeight()*float64(2*m.rows+1) + 4*r
+}
+
+// centre returns the centre of the hex at x and y in the sector, in pixels. Even columns are half a hex lower.
+func (m *hexMap) centre(x, y int) mapPoint {
+	r, h := m.radius, m.halfHeight()
+	c := mapPoint{X: 2*r + 1.5*r*float64(x-m.x0), Y: 3*r + h + 2*h*float64(y-m.y0)}
+	if x%2 == 0 {
+		c.Y += h
+	}
+	return c
+}
+
+// corners returns the six corners of the hex at x and y in the sector, in pixels.
+func (m *hexMap) corners(x, y int) []mapPoint {
+	ctr, r := m.centre(x, y), m.radius
+	corners := make([]mapPoint, 6)
+	for k := range corners {
+		a := float64(k) * math.Pi / 3
+		corners[k] = mapPoint{ctr.X + r*math.Cos(a), ctr.Y + r*math.Sin(a)}
+	}
+	return corners
+}
+
+// shows returns whether the hex at x and y in the sector is on the map.
+func (m *hexMap) shows(x, y int) bool {
+	return x >= m.x0 && x < m.x0+m.columns && y >= m.y0 && y < m.y0+m.rows
+}
+
+// hexNeighbours returns the hexes next to the hex at x and y, which may be outside the sector.
+func hexNeighbours(x, y int) [6][2]int {
+	if x%2 == 0 {
+		return [6][2]int{{x, y - 1}, {x, y + 1}, {x - 1, y}, {x - 1, y + 1}, {x + 1, y}, {x + 1, y + 1}}
+	}
+	return [6][2]int{{x, y - 1}, {x, y + 1}, {x - 1, y - 1}, {x - 1, y}, {x + 1, y - 1}, {x + 1, y}}
+}
+
+// claimsTerritory returns whether worlds of the allegiance are inside a border. Non-aligned and unknown worlds
+// are not.
+func claimsTerritory(allegiance string) bool {
+	return allegiance != "" && !strings.HasPrefix(allegiance, "Na") && !strings.HasPrefix(allegiance, "--")
+}
+
+// territories works out the allegiance of each hex of the sector that is inside a border. Each world claims its
+// own hex and the hexes next to it, and a hex next to worlds of different allegiances goes to the first of them.
+func (m *hexMap) territories() map[[2]int]string {
+	owner := make(map[[2]int]string)
+	for _, w := range m.sector.worlds {
+		if claimsTerritory(w.allegiance) {
+			owner[[2]int{w.hexLoc.x, w.hexLoc.y}] = w.allegiance
+		}
+	}
+	for _, w := range m.sector.worlds {
+		if !claimsTerritory(w.allegiance) {
+			continue
+		}
+		for _, n := range hexNeighbours(w.hexLoc.x, w.hexLoc.y) {
+			if _, ok := owner[n]; !ok && n[0] >= 1 && n[0] <= sectorWidth && n[1] >= 1 && n[1] <= sectorHeight {
+				owner[n] = w.allegiance
+			}
+		}
+	}
+	return owner
+}
+
+// draw draws the map on the canvas.
+func (m *hexMap) draw(c mapCanvas) {
+	p := mapPalettes[m.style]
+	r := m.radius
+	width, height := m.size()
+	c.polygon([]mapPoint{{0, 0}, {width, 0}, {width, height}, {0, height}}, p.background, noColour, 0)
+
+	// The grid, with the hex numbers at the top of each hex.
+	for x := m.x0; x < m.x0+m.columns; x++ {
+		for y := m.y0; y < m.y0+m.rows; y++ {
+			ctr := m.centre(x, y)
+			c.polygon(m.corners(x, y), noColour, p.grid, r/30)
+			c.text(mapPoint{ctr.X, ctr.Y - 0.7*r}, 0.18*r, p.hexNumber, fmt.Sprintf("%02d%02d", x, y))
+		}
+	}
+
+	// The borders, drawn just inside each edge between hexes of different allegiances.
+	owner := m.territories()
+	var codes []string
+	colours := make(map[string]color.RGBA)
+	for _, a := range owner {
+		if _, ok := colours[a]; !ok {
+			colours[a] = noColour
+			codes = append(codes, a)
+		}
+	}
+	sort.Strings(codes)
+	for i, a := range codes {
+		colours[a] = p.borders[i%len(p.borders)]
+	}
+	for x := m.x0; x < m.x0+m.columns; x++ {
+		for y := m.y0; y < m.y0+m.rows; y++ {
+			a, ok := owner[[2]int{x, y}]
+			if !ok {
+				continue
+			}
+			c1 := m.centre(x, y)
+			for _, n := range hexNeighbours(x, y) {
+				if owner[n] == a {
+					continue
+				}
+				c2 := m.centre(n[0], n[1])
+				dx, dy := (c2.X-c1.X)/(2*m.halfHeight()), (c2.Y-c1.Y)/(2*m.halfHeight())
+				mid := mapPoint{(c1.X+c2.X)/2 - 0.06*r*dx, (c1.Y+c2.Y)/2 - 0.06*r*dy}
+				c.line(mapPoint{mid.X - dy*r/2, mid.Y + dx*r/2}, mapPoint{mid.X + dy*r/2, mid.Y - dx*r/2}, colours[a],
+					r/10, false)
+			}
+		}
+	}
+
+	// The routes, stopping short of the worlds at each end.
+	for _, rt := range m.sector.routes {
+		if !m.shows(rt.start.x, rt.start.y) && !m.shows(rt.end.x, rt.end.y) {
+			continue
+		}
+		a, b := m.centre(rt.start.x, rt.start.y), m.centre(rt.end.x, rt.end.y)
+		length := math.Hypot(b.X-a.X, b.Y-a.Y)
+		if length == 0 {
+			continue
+		}
+		gap := 0.35 * r / length
+		a, b = mapPoint{a.X + (b.X-a.X)*gap, a.Y + (b.Y-a.Y)*gap}, mapPoint{b.X - (b.X-a.X)*gap, b.Y - (b.Y-a.Y)*gap}
+		switch rt.kind {
+		case routeXboat:
+			c.line(a, b, p.xboat, r/12, false)
+		case routeMajorTrade:
+			c.line(a, b, p.major, r/14, false)
+		default:
+			c.line(a, b, p.minor, r/18, true)
+		}
+	}
+
+	for i := range m.sector.worlds {
+		w := &m.sector.worlds[i]
+		if m.shows(w.hexLoc.x, w.hexLoc.y) {
+			m.drawWorld(c, w, p)
+		}
+	}
+	// The title is made smaller if it is too long to fit across the map.
+	title := m.title()
+	c.text(mapPoint{width / 2, 1.5 * r}, math.Min(0.6*r, width/float64(len(title)+2)), p.text, title)
+}
+
+// drawWorld draws the world in its hex: the travel zone, the world glyph, the starport above it, the bases to its
+// left, a gas giant to its right and the name below it.
+func (m *hexMap) drawWorld(c mapCanvas, w *world, p mapPalette) {
+	r := m.radius
+	ctr := m.centre(w.hexLoc.x, w.hexLoc.y)
+	u := w.uwp
+
+	switch w.zone {
+	case TzAmber:
+		c.circle(ctr, 0.5*r, noColour, p.amber, r/20)
+	case TzRed:
+		c.circle(ctr, 0.5*r, noColour, p.red, r/20)
+	}
+
+	// Asteroid belts are a ring of rocks, worlds with water are filled, and dry worlds are hollow.
+	switch {
+	case u.sizeInt == 0:
+		for k := 0; k < 6; k++ {
+			a := float64(k) * math.Pi / 3
+			c.circle(mapPoint{ctr.X + 0.12*r*math.Cos(a), ctr.Y + 0.12*r*math.Sin(a)}, 0.035*r, p.dry, noColour, 0)
+		}
+	case u.hydInt > 0:
+		c.circle(ctr, 0.16*r, p.wet, noColour, 0)
+	default:
+		c.circle(ctr, 0.16*r, p.background, p.dry, r/25)
+	}
+	if w.pbg.gasGiants > 0 {
+		c.circle(mapPoint{ctr.X + 0.45*r, ctr.Y - 0.25*r}, 0.07*r, p.text, noColour, 0)
+	}
+	c.text(mapPoint{ctr.X, ctr.Y - 0.38*r}, 0.3*r, p.text, u.starport)
+
+	// The bases are stacked down the left of the hex.
+	for i, b := range w.bases {
+		bc := mapPoint{ctr.X - 0.45*r, ctr.Y - 0.25*r + 0.22*r*float64(i)}
+		s := 0.08 * r
+		switch b {
+		case 'N':
+			star := make([]mapPoint, 10)
+			for k := range star {
+				a := -math.Pi/2 + float64(k)*math.Pi/5
+				d := s
+				if k%2 == 1 {
+					d = s * 0.4
+				}
+				star[k] = mapPoint{bc.X + d*math.Cos(a), bc.Y + d*math.Sin(a)}
+			}
+			c.polygon(star, p.base, noColour, 0)
+		case 'S', 'W':
+			tri := []mapPoint{{bc.X, bc.Y - s}, {bc.X + s, bc.Y + s*0.8}, {bc.X - s, bc.Y + s*0.8}}
+			if b == 'W' {
+				c.polygon(tri, p.base, noColour, 0)
+			} else {
+				c.polygon(tri, noColour, p.base, r/40)
+			}
+		case 'D':
+			c.polygon([]mapPoint{{bc.X - s, bc.Y - s}, {bc.X + s, bc.Y - s}, {bc.X + s, bc.Y + s}, {bc.X - s, bc.Y + s}},
+				p.base, noColour, 0)
+		default:
+			c.text(bc, 0.2*r, p.base, string(b))
+		}
+	}
+
+	// Worlds with a population of a billion or more are named in capitals.
+	name := w.name
+	if u.popInt >= 9 {
+		name = strings.ToUpper(name)
+	}
+	c.text(mapPoint{ctr.X, ctr.Y + 0.5*r}, 0.22*r, p.text, name)
+}
+
+// svgColour returns the colour as an SVG colour, eg "#ff8000", or "none".
+func svgColour(c color.RGBA) string {
+	if c.A == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// svgCanvas draws a hex map as SVG elements.
+type svgCanvas struct {
+	sb strings.Builder
+}
+
+func (c *svgCanvas) polygon(pts []mapPoint, fill, stroke color.RGBA, width float64) {
+	c.sb.WriteString("<polygon points=\"")
+	for i, pt := range pts {
+		if i > 0 {
+			c.sb.WriteString(" ")
+		}
+		fmt.Fprintf(&c.sb, "%.2f,%.2f", pt.X, pt.Y)
+	}
+	fmt.Fprintf(&c.sb, "\" fill=\"%s\" stroke=\"%s\" stroke-width=\"%.2f\"/>\n", svgColour(fill), svgColour(stroke), width)
+}
+
+func (c *svgCanvas) line(a, b mapPoint, col color.RGBA, width float64, dashed bool) {
+	dash := ""
+	if dashed {
+		dash = fmt.Sprintf(" stroke-dasharray=\"%.2f\"", 4*width)
+	}
+	fmt.Fprintf(&c.sb, "<line x1=\"%.2f\" y1=\"%.2f\" x2=\"%.2f\" y2=\"%.2f\" stroke=\"%s\" stroke-width=\"%.2f\" stroke-linecap=\"round\"%s/>\n",
+		a.X, a.Y, b.X, b.Y, svgColour(col), width, dash)
+}
+
+func (c *svgCanvas) circle(ctr mapPoint, r float64, fill, stroke color.RGBA, width float64) {
+	fmt.Fprintf(&c.sb, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"%.2f\" fill=\"%s\" stroke=\"%s\" stroke-width=\"%.2f\"/>\n",
+		ctr.X, ctr.Y, r, svgColour(fill), svgColour(stroke), width)
+}
+
+func (c *svgCanvas) text(p mapPoint, size float64, col color.RGBA, s string) {
+	fmt.Fprintf(&c.sb, "<text x=\"%.2f\" y=\"%.2f\" font-family=\"sans-serif\" font-size=\"%.2f\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"%s\">%s</text>\n",
+		p.X, p.Y, size, svgColour(col), html.EscapeString(s))
+}
+
+// pngCanvas draws a hex map on an image, without anti-aliasing. Text is drawn in a small built in bitmap font,
+// so no font files are needed.
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+// pixels calls set for each pixel in the rectangle, clipped to the image.
+func (c *pngCanvas) pixels(minX, minY, maxX, maxY float64, set func(x, y int, px, py float64)) {
+	b := c.img.Bounds()
+	x0, y0 := maxInt(int(math.Floor(minX)), b.Min.X), maxInt(int(math.Floor(minY)), b.Min.Y)
+	x1, y1 := minInt(int(math.Ceil(maxX)), b.Max.X-1), minInt(int(math.Ceil(maxY)), b.Max.Y-1)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			set(x, y, float64(x)+0.5, float64(y)+0.5)
+		}
+	}
+}
+
+func (c *pngCanvas) polygon(pts []mapPoint, fill, stroke color.RGBA, width float64) {
+	if fill.A > 0 {
+		minX, minY, maxX, maxY := pts[0].X, pts[0].Y, pts[0].X, pts[0].Y
+		for _, pt := range pts {
+			minX, minY, maxX, maxY = math.Min(minX, pt.X), math.Min(minY, pt.Y), math.Max(maxX, pt.X), math.Max(maxY, pt.Y)
+		}
+		// A pixel is inside if a ray from it crosses the edges an odd number of times.
+		c.pixels(minX, minY, maxX, maxY, func(x, y int, px, py float64) {
+			in := false
+			for i := range pts {
+				a, b := pts[i], pts[(i+1)%len(pts)]
+				if (a.Y > py) != (b.Y > py) && px < a.X+(py-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+					in = !in
+				}
+			}
+			if in {
+				c.img.SetRGBA(x, y, fill)
+			}
+		})
+	}
+	if stroke.A > 0 {
+		for i := range pts {
+			c.line(pts[i], pts[(i+1)%len(pts)], stroke, width, false)
+		}
+	}
+}
+
+func (c *pngCanvas) line(a, b mapPoint, col color.RGBA, width float64, dashed bool) {
+	half := math.Max(width/2, 0.5)
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length2 := dx*dx + dy*dy
+	dash := 4 * math.Max(width, 1)
+	c.pixels(math.Min(a.X, b.X)-half, math.Min(a.Y, b.Y)-half, math.Max(a.X, b.X)+half, math.Max(a.Y, b.Y)+half,
+		func(x, y int, px, py float64) {
+			t := 0.0
+			if length2 > 0 {
+				t = math.Max(0, math.Min(1, ((px-a.X)*dx+(py-a.Y)*dy)/length2))
+			}
+			if math.Hypot(px-a.X-t*dx, py-a.Y-t*dy) > half {
+				return
+			}
+			if dashed && int(t*math.Sqrt(length2)/dash)%2 == 1 {
+				return
+			}
+			c.img.SetRGBA(x, y, col)
+		})
+}
+
+func (c *pngCanvas) circle(ctr mapPoint, r float64, fill, stroke color.RGBA, width float64) {
+	half := math.Max(width/2, 0.5)
+	reach := r + half
+	c.pixels(ctr.X-reach, ctr.Y-reach, ctr.X+reach, ctr.Y+reach, func(x, y int, px, py float64) {
+		d := math.Hypot(px-ctr.X, py-ctr.Y)
+		if stroke.A > 0 && math.Abs(d-r) <= half {
+			c.img.SetRGBA(x, y, stroke)
+		} else if fill.A > 0 && d <= math.Max(r, 0.5) {
+			c.img.SetRGBA(x, y, fill)
+		}
+	})
+}
+
+func (c *pngCanvas) text(p mapPoint, size float64, col color.RGBA, s string) {
+	runes := []rune(strings.ToUpper(s))
+	scale := maxInt(1, int(size/fontHeight))
+	x := int(p.X) - (len(runes)*(fontWidth+1)-1)*scale/2
+	y := int(p.Y) - fontHeight*scale/2
+	for _, ch := range runes {
+		glyph, ok := mapFont[ch]
+		if !ok {
+			glyph = mapFont['?']
+		}
+		for i, bit := range glyph {
+			if bit != '1' {
+				continue
+			}
+			gx, gy := x+(i%fontWidth)*scale, y+(i/fontWidth)*scale
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					c.img.SetRGBA(gx+dx, gy+dy, col)
+				}
+			}
+		}
+		x += (fontWidth + 1) * scale
+	}
+}
+
+// The size of the characters of the bitmap font, in pixels.
+const (
+	fontWidth  = 3
+	fontHeight = 5
+)
+
+// mapFont is the bitmap font used for text on PNG maps. Each character is fontWidth by fontHeight pixels, given row
+// by row. Lower case letters are drawn in upper case, and characters missing from the font as a question mark.
+var mapFont = map[rune]string{
+	' ': "000000000000000", '?': "111001010000010", '-': "000000111000000", '.': "000000000000010",
+	'\'': "010010000000000", ',': "000000000010100", '(': "010100100100010", ')': "010001001001010",
+	'0': "111101101101111", '1': "010110010010111", '2': "111001111100111", '3': "111001111001111",
+	'4': "101101111001001", '5': "111100111001111", '6': "111100111101111", '7': "111001001010010",
+	'8': "111101111101111", '9': "111101111001111",
+	'A': "010101111101101", 'B': "110101110101110", 'C': "011100100100011", 'D': "110101101101110",
+	'E': "111100110100111", 'F': "111100110100100", 'G': "011100101101011", 'H': "101101111101101",
+	'I': "111010010010111", 'J': "001001001101010", 'K': "101101110101101", 'L': "100100100100111",
+	'M': "101111111101101", 'N': "110101101101101", 'O': "010101101101010", 'P': "110101110100100",
+	'Q': "010101101110011", 'R': "110101110101101", 'S': "011100010001110", 'T': "111010010010010",
+	'U': "101101101101111", 'V': "101101101101010", 'W': "101101111111101", 'X': "101101010101101",
+	'Y': "101101010010010", 'Z': "111001010100111",
+}
+
+// writeSVG writes the map as an SVG image.
+func (m *hexMap) writeSVG(w io.Writer) error {
+	return m.writeLinkedSVG(w, nil)
+}
+
+// writeLinkedSVG writes the map as an SVG image in which each world's hex links to the address that link returns
+// for it. Worlds for which link returns "" are not linked, nor are any if link is nil.
+func (m *hexMap) writeLinkedSVG(w io.Writer, link func(*world) string) error {
+	width, height := m.size()
+	c := &svgCanvas{}
+	fmt.Fprintf(&c.sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\">\n",
+		width, height, width, height)
+	fmt.Fprintf(&c.sb, "<title>%s</title>\n", html.EscapeString(m.title()))
+	m.draw(c)
+	for i := range m.sector.worlds {
+		wd := &m.sector.worlds[i]
+		href := ""
+		if link != nil && m.shows(wd.hexLoc.x, wd.hexLoc.y) {
+			href = link(wd)
+		}
+		if href == "" {
+			continue
+		}
+		fmt.Fprintf(&c.sb, "<a href=\"%s\" pointer-events=\"all\"><title>%s</title>", html.EscapeString(href), html.EscapeString(wd.name))
+		c.polygon(m.corners(wd.hexLoc.x, wd.hexLoc.y), noColour, noColour, 0)
+		c.sb.WriteString("</a>\n")
+	}
+	c.sb.WriteString("</svg>\n")
+	_, err := io.WriteString(w, c.sb.String())
+	return err
+}
+
+// writePNG writes the map as a PNG image.
+func (m *hexMap) writePNG(w io.Writer) error {
+	width, height := m.size()
+	c := &pngCanvas{img: image.NewRGBA(image.Rect(0, 0, int(math.Ceil(width)), int(math.Ceil(height))))}
+	m.draw(c)
+	return png.Encode(w, c.img)
+}
+
+// save saves the map to the named file, as a PNG or SVG image depending on the file extension.
+func (m *hexMap) save(filename string) error {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext != ".png" && ext != ".svg" {
+		return fmt.Errorf("%w: %s", ErrUnknownImageFormat, filename)
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if ext == ".png" {
+		err = m.writePNG(f)
+	} else {
+		err = m.writeSVG(f)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// baseName returns the start of the names of files made from the map, eg "Foreven sector", "Foreven Alpha quadrant"
+// or "Foreven subsector A".
+func (m *hexMap) baseName() string {
+	base := strings.TrimSuffix(sectorFilename(m.sector.name), ".tab")
+	switch m.scope {
+	case scopeQuadrant:
+		return fmt.Sprintf("%s %s quadrant", base, quadrantNames[m.index])
+	case scopeSubsector:
+		return fmt.Sprintf("%s subsector %s", base, ssIndex[m.index])
+	}
+	return base + " sector"
+}
+
+// filename returns the default filename for the map, eg "Foreven sector map.svg", "Foreven Alpha quadrant map.png"
+// or "Foreven subsector A map.png".
+func (m *hexMap) filename(ext string) string {
+	return m.baseName() + " map." + ext
+}
+
+// subsectorIndexes returns the indexes (0 to 15) of the subsectors on the map.
+func (m *hexMap) subsectorIndexes() (idx []int) {
+	for i := range ssIndex {
+		x, y := 1+8*(i%4), 1+10*(i/4)
+		if m.shows(x, y) {
+			idx = append(idx, i)
+		}
+	}
+	return
+}
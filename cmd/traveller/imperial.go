@@ -0,0 +1,99 @@
+package main
+
+// imperial.go contains code for generating a new, long settled Imperial sector that is not part of the Official
+// Traveller Universe. The worlds are generated with the Imperial allegiance (so with bases by the Imperial rules),
+// then biased toward higher populations and tech levels. The sector is given subsector and sector capitals, an
+// xboat network with scout way stations at its stations, and Imperial nobility.
+
+import (
+	"math/rand"
+	"strings"
+	"trav2/cmd/traveller/tools"
+)
+
+// imperialAllegiance is the allegiance code given to the worlds of a new Imperial sector.
+const imperialAllegiance = "ImXX"
+
+// imperialMaxTech is the highest tech level that a new Imperial world is raised to.
+const imperialMaxTech = 15
+
+// imperialise biases a newly generated world toward the higher population and tech level of a long settled Imperial
+// world, and gives it the Imperial allegiance, T5 extensions and nobility. Unpopulated worlds are left as they are.
+func (w *world) imperialise(r *rand.Rand) {
+	u := &w.uwp
+	if u.popInt > 0 {
+		if u.popInt < 10 && tools.RollD6(r) >= 4 {
+			u.popInt++
+		}
+		if u.techInt < imperialMaxTech {
+			u.techInt++
+			if tools.RollD6(r) == 6 && u.techInt < imperialMaxTech {
+				u.techInt++
+			}
+		}
+	}
+	w.allegiance = imperialAllegiance
+	w.remarks = w.determineTradeClassifications()
+	w.extendWorld(r)
+}
+
+// betterCapital returns whether world a makes a better capital than world b: the more important, then the more
+// populous, then the higher tech level.
+func betterCapital(a, b *world) bool {
+	if a.importance.Importance != b.importance.Importance {
+		return a.importance.Importance > b.importance.Importance
+	}
+	if a.uwp.popInt != b.uwp.popInt {
+		return a.uwp.popInt > b.uwp.popInt
+	}
+	return a.uwp.techInt > b.uwp.techInt
+}
+
+// markCapitals makes the best populated world of each subsector its capital, with a "Cp" remark, and the best of
+// those the sector capital, with a "Cs" remark instead.
+func (s *sector) markCapitals() {
+	sectorCapital := -1
+	for ss := range ssIndex {
+		capital := -1
+		for i := range s.worlds {
+			w := &s.worlds[i]
+			if w.hexLoc.IntIndex() != ss || w.uwp.popInt == 0 {
+				continue
+			}
+			if capital < 0 || betterCapital(w, &s.worlds[capital]) {
+				capital = i
+			}
+		}
+		if capital < 0 {
+			continue
+		}
+		s.worlds[capital].remarks = strings.TrimSpace(s.worlds[capital].remarks + " Cp")
+		if sectorCapital < 0 || betterCapital(&s.worlds[capital], &s.worlds[sectorCapital]) {
+			sectorCapital = capital
+		}
+	}
+	if sectorCapital >= 0 {
+		s.worlds[sectorCapital].remarks = strings.TrimSuffix(s.worlds[sectorCapital].remarks, "Cp") + "Cs"
+	}
+}
+
+// addWayStations puts a scout way station ("W") on every xboat station of the sector, in place of any scout base,
+// and works out the importance and nobility of those worlds again. The routes must be generated first.
+func (s *sector) addWayStations() {
+	stations := make(map[string]bool)
+	for _, r := range s.routes {
+		if r.kind == routeXboat {
+			stations[r.start.String()] = true
+			stations[r.end.String()] = true
+		}
+	}
+	for i := range s.worlds {
+		w := &s.worlds[i]
+		if !stations[w.hexLoc.String()] || strings.Contains(w.bases, "W") {
+			continue
+		}
+		w.bases = strings.Replace(w.bases, "S", "", 1) + "W"
+		w.determineImportanceExtension()
+		w.getNobility()
+	}
+}
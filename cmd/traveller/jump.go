@@ -0,0 +1,190 @@
+package main
+
+// jump.go contains code for working out jump point distances (the 100 diameter limit) and in-system
+// travel times. Travel is brachistochrone: constant acceleration to the midpoint, then constant deceleration.
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// kmPerAU is the number of kilometres in an Astronomical Unit.
+const kmPerAU = 149597870.7
+
+// standardGravity is 1G, in metres per second per second.
+const standardGravity = 9.80665
+
+// jumpDiameters is the number of diameters from a body that a ship must be before it can jump.
+const jumpDiameters = 100
+
+// maxDriveG is the highest drive rating that travel times are given for.
+const maxDriveG = 6
+
+// solarDiameterKm is the diameter of Sol in km.
+const solarDiameterKm = 1392700
+
+// Diameters (in km) for bodies that do not have a UWP size.
+const (
+	diameterLargeGasGiant = 140000
+	diameterSmallGasGiant = 60000
+	diameterIceGiant      = 50000
+	diameterPlanetoid     = 200
+)
+
+// jumpPoint contains the jump point details for a body.
+type jumpPoint struct {
+	DiameterKm     float64                  // The diameter of the body, in km
+	BodyLimitKm    float64                  // The 100 diameter limit of the body, in km from its centre
+	MaskedBy       string                   // What masks the body's own limit, if anything: the star or the host of a satellite
+	MaskingLimitKm float64                  // The 100 diameter limit of the star (or host) that masks the body, if masked
+	DistanceKm     float64                  // The distance from the body to the nearest point where jump is possible
+	Times          [maxDriveG]time.Duration // The time taken to get to the jump point at 1G to 6G
+}
+
+// bodyDiameterKm returns the diameter of a body with the given type and UWP, in km.
+func bodyDiameterKm(bodyType, uwp string) float64 {
+	switch bodyType {
+	case btLargeGasGiant:
+		return diameterLargeGasGiant
+	case btSmallGasGiant:
+		return diameterSmallGasGiant
+	case btIceGiant:
+		return diameterIceGiant
+	}
+	u := parseUwp(uwp)
+	if u.sizeInt <= 0 {
+		return diameterPlanetoid
+	}
+	return float64(u.sizeInt) * 1600
+}
+
+// orbitalDistanceKm returns the distance from the star to the orbit number, in km.
+func orbitalDistanceKm(orbit float64) float64 {
+	return orbitToAU(orbit) * kmPerAU
+}
+
+// satelliteDistanceKm returns the distance of a satellite from its host (of the given diameter), in km.
+// It returns 0 if the satellite orbit designator is unknown.
+func satelliteDistanceKm(satOrbit string, hostDiameterKm float64) float64 {
+	for i, name := range satelliteOrbit {
+		if strings.EqualFold(name, satOrbit) {
+			return float64(satelliteOrbitMultiplier[i]) * hostDiameterKm
+		}
+	}
+	return 0
+}
+
+// travelTime returns the brachistochrone travel time over the distance (in km) at the acceleration (in G).
+func travelTime(distanceKm, g float64) time.Duration {
+	if distanceKm <= 0 || g <= 0 {
+		return 0
+	}
+	seconds := 2 * math.Sqrt(distanceKm*1000/(g*standardGravity))
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// travelTimes returns the travel times over the distance (in km) at 1G to 6G.
+func travelTimes(distanceKm float64) (times [maxDriveG]time.Duration) {
+	for g := 1; g <= maxDriveG; g++ {
+		times[g-1] = travelTime(distanceKm, float64(g))
+	}
+	return
+}
+
+// orbitTravelDistances returns the shortest and longest distances (in km) between two orbits around the
+// same star, when the bodies are on the same side and on opposite sides of the star.
+func orbitTravelDistances(orbit1, orbit2 float64) (shortest, longest float64) {
+	d1, d2 := orbitalDistanceKm(orbit1), orbitalDistanceKm(orbit2)
+	return math.Abs(d1 - d2), d1 + d2
+}
+
+// calculateJumpPoint works out the jump point for a body of the given diameter, orbiting at the distance
+// from a star of the given diameter (all in km). If the star's limit extends past the body's orbit, the
+// body's own limit is masked and the jump point is at the edge of the star's limit.
+func calculateJumpPoint(diameterKm, orbitKm, starDiameterKm float64) (jp jumpPoint) {
+	jp.DiameterKm = diameterKm
+	jp.BodyLimitKm = diameterKm * jumpDiameters
+	jp.DistanceKm = jp.BodyLimitKm
+	starLimit := starDiameterKm * jumpDiameters
+	if starLimit-orbitKm > jp.BodyLimitKm {
+		jp.MaskedBy = "Star"
+		jp.MaskingLimitKm = starLimit
+		jp.DistanceKm = starLimit - orbitKm
+	}
+	jp.Times = travelTimes(jp.DistanceKm)
+	return
+}
+
+// jumpPointForBody works out the jump point for a body in a generated system. For a satellite, the body
+// is the host and sat the satellite; the host's limit may mask the satellite's. Otherwise sat should be nil.
+func (s *starSystem) jumpPointForBody(b systemBody, sat *systemBody) (jumpPoint, error) {
+	if b.Star < 0 || b.Star >= len(s.Stars) {
+		return jumpPoint{}, fmt.Errorf("%w: body %s orbits star %d", ErrSystemInconsistent, b.Name, b.Star)
+	}
+	starDiameter, err := starDiameterKm(s.Stars[b.Star].Spectral)
+	if err != nil {
+		return jumpPoint{}, err
+	}
+	orbitKm := orbitalDistanceKm(float64(b.Orbit))
+	hostDiameter := bodyDiameterKm(b.Type, b.UWP)
+	if sat == nil {
+		return calculateJumpPoint(hostDiameter, orbitKm, starDiameter), nil
+	}
+
+	// A satellite is masked by the star or its host, whichever limit reaches further past it.
+	jp := calculateJumpPoint(bodyDiameterKm(sat.Type, sat.UWP), orbitKm, starDiameter)
+	hostLimit := hostDiameter * jumpDiameters
+	satDistance := satelliteDistanceKm(sat.SatOrbit, hostDiameter)
+	if hostLimit-satDistance > jp.DistanceKm {
+		jp.MaskedBy = "Host"
+		jp.MaskingLimitKm = hostLimit
+		jp.DistanceKm = hostLimit - satDistance
+		jp.Times = travelTimes(jp.DistanceKm)
+	}
+	return jp, nil
+}
+
+// jumpPointForWorld works out the jump point for a mainworld from its size, its primary star and its
+// habitable zone variance, for use when no generated system is available.
+func (w world) jumpPointForWorld() (jumpPoint, error) {
+	if len(w.stars) == 0 {
+		return jumpPoint{}, ErrStarNotFound
+	}
+	sm, err := w.stars[0].model()
+	if err != nil {
+		return jumpPoint{}, err
+	}
+	orbit := float64(w.stars[0].habitableZoneOrbit() + w.habZoneVar)
+	return calculateJumpPoint(bodyDiameterKm(wtMainworld, w.uwp.String()), orbitalDistanceKm(orbit), sm.Radius*solarDiameterKm), nil
+}
+
+// formatTravelTime formats a travel time in the same way as the campaign spreadsheets, eg "2h 33m 43s".
+func formatTravelTime(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	h, m, s := d/time.Hour, (d%time.Hour)/time.Minute, (d%time.Minute)/time.Second
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm %ds", days, h, m, s)
+	case h > 0:
+		return fmt.Sprintf("%dh %dm %ds", h, m, s)
+	default:
+		return fmt.Sprintf("%dm %ds", m, s)
+	}
+}
+
+// String returns the jump point details as a short multi-line description.
+func (jp jumpPoint) String() (str string) {
+	str = fmt.Sprintf("Diameter %.0f km, 100D limit %.0f km\n", jp.DiameterKm, jp.BodyLimitKm)
+	if jp.MaskedBy != "" {
+		str += fmt.Sprintf("Masked by %s (limit %.0f km)\n", strings.ToLower(jp.MaskedBy), jp.MaskingLimitKm)
+	}
+	str += fmt.Sprintf("Jump point distance %.0f km\n", jp.DistanceKm)
+	for g, t := range jp.Times {
+		str += fmt.Sprintf("%dG: %s; ", g+1, formatTravelTime(t))
+	}
+	return str + "\n"
+}
@@ -3,6 +3,7 @@ package main
 // languages.go contains code for foreign/alien language word generation.
 
 import (
+	"math/rand"
 	"strings"
 	"trav2/cmd/traveller/tools"
 )
@@ -29,35 +30,35 @@ func (l Language) String() string {
 }
 
 // GenFunc returns the function that generates a random word in the language.
-func (l Language) GenFunc() func() (string, string) {
+func (l Language) GenFunc() func(*rand.Rand) (string, string) {
 	if l == LanguageNone {
 		return nil
 	}
-	return [...]func() (string, string){generateAslanWord, generateDarrianWord, generateDroyneWord,
+	return [...]func(*rand.Rand) (string, string){generateAslanWord, generateDarrianWord, generateDroyneWord,
 		generateKkreeWord, generateSolomaniWord, generateVargrWord, generateVilaniWord, generateZhodaniWord}[l]
 }
 
-// GenWord generates a word and pattern for the language.
-func (l Language) GenWord() (word string, pattern string) {
+// GenWord generates a word and pattern for the language, using the RNG.
+func (l Language) GenWord(r *rand.Rand) (word string, pattern string) {
 	if l == LanguageNone {
 		return
 	}
-	word, pattern = (l.GenFunc())()
+	word, pattern = (l.GenFunc())(r)
 	return
 }
 
-// GenerateWord generates a word and pattern for the language.
-func GenerateWord(lang int) (word string, pattern string) {
+// GenerateWord generates a word and pattern for the language, using the RNG.
+func GenerateWord(r *rand.Rand, lang int) (word string, pattern string) {
 	if Language(lang) == LanguageNone {
 		return
 	}
 	l := Language(lang)
-	return l.GenWord()
+	return l.GenWord(r)
 }
 
 // generateZhodaniWord generates a single Zhodani word and returns a string containing the word and a string showing the structure
-func generateZhodaniWord() (finalWord string, structure string) {
-	syll := tools.D6()
+func generateZhodaniWord(r *rand.Rand) (finalWord string, structure string) {
+	syll := tools.RollD6(r)
 
 	useAlternate := false
 
@@ -66,44 +67,44 @@ func generateZhodaniWord() (finalWord string, structure string) {
 
 		if !useAlternate {
 			// Basic structure table
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 
 			if roll <= 3 {
-				finalWord += getZhodVowel()
+				finalWord += getZhodVowel(r)
 				structure += "[V]"
 				useAlternate = true
 			} else if roll <= 6 {
-				finalWord += getZhodInitCons() + getZhodVowel()
+				finalWord += getZhodInitCons(r) + getZhodVowel(r)
 				structure += "[CV]"
 				useAlternate = true
 			} else if roll <= 15 {
-				finalWord += getZhodVowel() + getZhodFinalCons()
+				finalWord += getZhodVowel(r) + getZhodFinalCons(r)
 				structure += "[VC]"
 				useAlternate = false
 			} else {
-				finalWord += getZhodInitCons() + getZhodVowel() + getZhodFinalCons()
+				finalWord += getZhodInitCons(r) + getZhodVowel(r) + getZhodFinalCons(r)
 				structure += "[CVC]"
 				useAlternate = false
 			}
 
 		} else {
 			// Alternate structure table
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 
 			if roll <= 6 {
-				finalWord += getZhodVowel()
+				finalWord += getZhodVowel(r)
 				structure += "[a:V]"
 				useAlternate = true
 			} else if roll <= 12 {
-				finalWord += getZhodInitCons() + getZhodVowel()
+				finalWord += getZhodInitCons(r) + getZhodVowel(r)
 				structure += "[a:CV]"
 				useAlternate = true
 			} else if roll <= 18 {
-				finalWord += getZhodVowel() + getZhodFinalCons()
+				finalWord += getZhodVowel(r) + getZhodFinalCons(r)
 				structure += "[a:VC]"
 				useAlternate = false
 			} else {
-				finalWord += getZhodInitCons() + getZhodVowel() + getZhodFinalCons()
+				finalWord += getZhodInitCons(r) + getZhodVowel(r) + getZhodFinalCons(r)
 				structure += "[a:CVC]"
 				useAlternate = false
 			}
@@ -114,8 +115,8 @@ func generateZhodaniWord() (finalWord string, structure string) {
 }
 
 // getZhodInitCons returns an Zhodani initial consonant based on the frequency table.
-func getZhodInitCons() string {
-	roll := tools.Dice(127)
+func getZhodInitCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 127)
 
 	if roll <= 3 {
 		return "b"
@@ -203,8 +204,8 @@ func getZhodInitCons() string {
 }
 
 // getZhodVowel returns a Zhodani vowel based on the frequency table.
-func getZhodVowel() string {
-	roll := tools.Dice(31)
+func getZhodVowel(r *rand.Rand) string {
+	roll := tools.RollDice(r, 31)
 
 	if roll <= 7 {
 		return "a"
@@ -225,8 +226,8 @@ func getZhodVowel() string {
 }
 
 // getZhodFinalCons returns a Zhodani final consonant based on the frequency table.
-func getZhodFinalCons() string {
-	roll := tools.Dice(122)
+func getZhodFinalCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 122)
 
 	if roll <= 1 {
 		return "b"
@@ -317,9 +318,9 @@ func getZhodFinalCons() string {
 }
 
 // generateVilaniWord generates a single Vilani word and its structure.
-func generateVilaniWord() (finalWord string, structure string) {
+func generateVilaniWord(r *rand.Rand) (finalWord string, structure string) {
 	// Generate a word
-	syll := tools.D6()
+	syll := tools.RollD6(r)
 
 	//	var finalWord string
 	//	var structure string
@@ -330,36 +331,36 @@ func generateVilaniWord() (finalWord string, structure string) {
 
 		if !useAlternate {
 			// Basic structure table
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 
 			if roll <= 6 {
-				finalWord += getVilVowel()
+				finalWord += getVilVowel(r)
 				structure += "[V]"
 				useAlternate = true
 			} else if roll <= 21 {
-				finalWord += getVilInitCons() + getVilVowel()
+				finalWord += getVilInitCons(r) + getVilVowel(r)
 				structure += "[CV]"
 				useAlternate = true
 			} else if roll <= 29 {
-				finalWord += getVilVowel() + getVilFinalCons()
+				finalWord += getVilVowel(r) + getVilFinalCons(r)
 				structure += "[VC]"
 				useAlternate = false
 			} else {
-				finalWord += getVilInitCons() + getVilVowel() + getVilFinalCons()
+				finalWord += getVilInitCons(r) + getVilVowel(r) + getVilFinalCons(r)
 				structure += "[CVC]"
 				useAlternate = false
 			}
 
 		} else {
 			// Alternate structure table
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 
 			if roll <= 21 {
-				finalWord += getVilInitCons() + getVilVowel()
+				finalWord += getVilInitCons(r) + getVilVowel(r)
 				structure += "[a:CV]"
 				useAlternate = false
 			} else {
-				finalWord += getVilInitCons() + getVilVowel() + getVilFinalCons()
+				finalWord += getVilInitCons(r) + getVilVowel(r) + getVilFinalCons(r)
 				structure += "[a:CVC]"
 				useAlternate = true
 			}
@@ -370,8 +371,8 @@ func generateVilaniWord() (finalWord string, structure string) {
 }
 
 // getVilInitCons generates a Vilani initial consonant based on the frequency table.
-func getVilInitCons() string {
-	roll := tools.Dice(216)
+func getVilInitCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 216)
 
 	if roll <= 39 {
 		return "k"
@@ -403,8 +404,8 @@ func getVilInitCons() string {
 }
 
 // getVilVowel gets a Vilani vowel based on the frequency table.
-func getVilVowel() string {
-	roll := tools.Dice(216)
+func getVilVowel(r *rand.Rand) string {
+	roll := tools.RollDice(r, 216)
 
 	if roll <= 67 {
 		return "a"
@@ -424,8 +425,8 @@ func getVilVowel() string {
 }
 
 // getVilFinalCons gets a Vilani final consonant based on the frequency table.
-func getVilFinalCons() string {
-	roll := tools.Dice(216)
+func getVilFinalCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 216)
 
 	if roll <= 76 {
 		return "r"
@@ -450,9 +451,9 @@ func getVilFinalCons() string {
 }
 
 // generateVargrWord generates a single Vargr word based on the published frequency tables.
-func generateVargrWord() (finalWord string, structure string) {
+func generateVargrWord(r *rand.Rand) (finalWord string, structure string) {
 	// Generate a word
-	syll := tools.D6()
+	syll := tools.RollD6(r)
 
 	useAlternate := false
 
@@ -461,35 +462,35 @@ func generateVargrWord() (finalWord string, structure string) {
 
 		if !useAlternate {
 			// Basic structure table
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 
 			if roll <= 6 {
-				finalWord += getVargrVowel()
+				finalWord += getVargrVowel(r)
 				structure += "[V]"
 				useAlternate = true
 			} else if roll <= 18 {
-				finalWord += getVargrVowel() + getVargrFinalCons()
+				finalWord += getVargrVowel(r) + getVargrFinalCons(r)
 				structure += "[VC]"
 				useAlternate = false
 			} else if roll <= 22 {
-				finalWord += getVargrInitCons() + getVargrVowel()
+				finalWord += getVargrInitCons(r) + getVargrVowel(r)
 				structure += "[CV]"
 				useAlternate = true
 			} else {
-				finalWord += getVargrInitCons() + getVargrVowel() + getVargrFinalCons()
+				finalWord += getVargrInitCons(r) + getVargrVowel(r) + getVargrFinalCons(r)
 				structure += "[CVC]"
 				useAlternate = false
 			}
 		} else {
 			// Alternate structure table
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 
 			if roll <= 18 {
-				finalWord += getVargrInitCons() + getVargrVowel()
+				finalWord += getVargrInitCons(r) + getVargrVowel(r)
 				structure += "[a:CV]"
 				useAlternate = true
 			} else {
-				finalWord += getVargrInitCons() + getVargrVowel() + getVargrFinalCons()
+				finalWord += getVargrInitCons(r) + getVargrVowel(r) + getVargrFinalCons(r)
 				structure += "[a:CVC]"
 				useAlternate = false
 			}
@@ -501,8 +502,8 @@ func generateVargrWord() (finalWord string, structure string) {
 }
 
 // getVargrInitCons generates a Vargr initial consonant based on the frequency table.
-func getVargrInitCons() string {
-	roll := tools.Dice(26)
+func getVargrInitCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 26)
 
 	if roll <= 5 {
 		return "d"
@@ -560,8 +561,8 @@ func getVargrInitCons() string {
 }
 
 // getVargrVowel gets a Vargr vowel based on the frequency table.
-func getVargrVowel() string {
-	roll := tools.Dice(26)
+func getVargrVowel(r *rand.Rand) string {
+	roll := tools.RollDice(r, 26)
 
 	if roll <= 5 {
 		return "a"
@@ -585,8 +586,8 @@ func getVargrVowel() string {
 }
 
 // getVargrFinalCons gets a Vargr final consonant based on the frequency table.
-func getVargrFinalCons() string {
-	roll := tools.Dice(43)
+func getVargrFinalCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 43)
 
 	if roll <= 1 {
 		return "dh"
@@ -640,9 +641,9 @@ func getVargrFinalCons() string {
 }
 
 // generateAslanWord generates a single Aslan word based on the published frequency tables.
-func generateAslanWord() (finalWord string, structure string) {
+func generateAslanWord(r *rand.Rand) (finalWord string, structure string) {
 	// Generate a word
-	syll := tools.D6()
+	syll := tools.RollD6(r)
 
 	useAlternate := false
 
@@ -651,35 +652,35 @@ func generateAslanWord() (finalWord string, structure string) {
 
 		if !useAlternate {
 			// Basic structure table
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 
 			if roll <= 13 {
-				finalWord += getAslanVowel()
+				finalWord += getAslanVowel(r)
 				structure += "[V]"
 				useAlternate = false
 			} else if roll <= 22 {
-				finalWord += getAslanInitCons() + getAslanVowel()
+				finalWord += getAslanInitCons(r) + getAslanVowel(r)
 				structure += "[CV]"
 				useAlternate = false
 			} else if roll <= 30 {
-				finalWord += getAslanVowel() + getAslanFinalCons()
+				finalWord += getAslanVowel(r) + getAslanFinalCons(r)
 				structure += "[VC]"
 				useAlternate = true
 			} else {
-				finalWord += getAslanInitCons() + getAslanVowel() + getAslanFinalCons()
+				finalWord += getAslanInitCons(r) + getAslanVowel(r) + getAslanFinalCons(r)
 				structure += "[CV]"
 				useAlternate = true
 			}
 		} else {
 			// Alternate structure table
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 
 			if roll <= 15 {
-				finalWord += getAslanVowel()
+				finalWord += getAslanVowel(r)
 				structure += "[a:V]"
 				useAlternate = false
 			} else {
-				finalWord += getAslanVowel() + getAslanFinalCons()
+				finalWord += getAslanVowel(r) + getAslanFinalCons(r)
 				structure += "[a:VC]"
 			}
 		}
@@ -689,8 +690,8 @@ func generateAslanWord() (finalWord string, structure string) {
 }
 
 // getAslanInitCons gets an Aslan initial consonant based on the frequency table.
-func getAslanInitCons() string {
-	roll := tools.Dice(87)
+func getAslanInitCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 87)
 
 	if roll <= 5 {
 		return "f"
@@ -749,8 +750,8 @@ func getAslanInitCons() string {
 }
 
 // getAslanVowel gets an Aslan vowel based on the frequency table.
-func getAslanVowel() string {
-	roll := tools.Dice(216)
+func getAslanVowel(r *rand.Rand) string {
+	roll := tools.RollDice(r, 216)
 
 	if roll <= 41 {
 		return "a"
@@ -796,8 +797,8 @@ func getAslanVowel() string {
 }
 
 // getAslanFinalCons gets an Aslan final consonant based on the frequency table.
-func getAslanFinalCons() string {
-	roll := tools.Dice(47)
+func getAslanFinalCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 47)
 
 	if roll <= 10 {
 		return "h"
@@ -821,9 +822,9 @@ func getAslanFinalCons() string {
 }
 
 // generateDarrianWord generates a single Darrian word based on the published frequency tables.
-func generateDarrianWord() (finalWord string, structure string) {
+func generateDarrianWord(r *rand.Rand) (finalWord string, structure string) {
 	// Generate a word
-	syll := tools.D6()
+	syll := tools.RollD6(r)
 
 	useAlternate := false
 
@@ -832,27 +833,27 @@ func generateDarrianWord() (finalWord string, structure string) {
 
 		if !useAlternate {
 			// Basic structure table
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 
 			if roll <= 27 {
-				finalWord += getDarrianInitCons() + getDarrianVowel() + getDarrianFinalCons()
+				finalWord += getDarrianInitCons(r) + getDarrianVowel(r) + getDarrianFinalCons(r)
 				structure += "[CVC]"
 				useAlternate = true
 			} else {
-				finalWord += getDarrianInitCons() + getDarrianVowel()
+				finalWord += getDarrianInitCons(r) + getDarrianVowel(r)
 				structure += "[CV]"
 				useAlternate = false
 			}
 		} else {
 			// Alternate structure table
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 
 			if roll <= 27 {
-				finalWord += getDarrianVowel() + getDarrianFinalCons()
+				finalWord += getDarrianVowel(r) + getDarrianFinalCons(r)
 				structure += "[a:VC]"
 				useAlternate = true
 			} else {
-				finalWord += getDarrianVowel()
+				finalWord += getDarrianVowel(r)
 				structure += "[a:V]"
 				useAlternate = false
 			}
@@ -864,8 +865,8 @@ func generateDarrianWord() (finalWord string, structure string) {
 }
 
 // getDarrianInitCons gets an initial Darrian syllable consonant.
-func getDarrianInitCons() string {
-	roll := tools.Dice(209)
+func getDarrianInitCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 209)
 
 	if roll <= 17 {
 		return "b"
@@ -919,8 +920,8 @@ func getDarrianInitCons() string {
 }
 
 // getDarrianVowel gets a Darrian vowel.
-func getDarrianVowel() string {
-	roll := tools.Dice(45)
+func getDarrianVowel(r *rand.Rand) string {
+	roll := tools.RollDice(r, 45)
 
 	if roll <= 8 {
 		return "a"
@@ -940,8 +941,8 @@ func getDarrianVowel() string {
 }
 
 // getDarrianFinalCons gets a final Darrian consonant.
-func getDarrianFinalCons() string {
-	roll := tools.Dice(216)
+func getDarrianFinalCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 216)
 
 	if roll <= 9 {
 		return "bh"
@@ -989,8 +990,8 @@ func getDarrianFinalCons() string {
 }
 
 // generateKkreeWord generates a single K'kree word based on the published frequency tables.
-func generateKkreeWord() (finalWord string, structure string) {
-	syll := tools.D6()
+func generateKkreeWord(r *rand.Rand) (finalWord string, structure string) {
+	syll := tools.RollD6(r)
 
 	useTable := 1
 
@@ -999,46 +1000,46 @@ func generateKkreeWord() (finalWord string, structure string) {
 
 		switch useTable {
 		case 1:
-			roll := tools.Dice(13)
+			roll := tools.RollDice(r, 13)
 			switch {
 			case roll == 1:
-				finalWord += getKkreeVowel()
+				finalWord += getKkreeVowel(r)
 				structure += "[V]"
 				useTable = 3
 			case roll <= 7:
-				finalWord += getKkreeInitCons() + getKkreeVowel()
+				finalWord += getKkreeInitCons(r) + getKkreeVowel(r)
 				structure += "[CV]"
 				useTable = 3
 			case roll <= 9:
-				finalWord += getKkreeVowel() + getKkreeFinalCons()
+				finalWord += getKkreeVowel(r) + getKkreeFinalCons(r)
 				structure += "[VC]"
 				useTable = 2
 			default:
-				finalWord += getKkreeInitCons() + getKkreeVowel() + getKkreeFinalCons()
+				finalWord += getKkreeInitCons(r) + getKkreeVowel(r) + getKkreeFinalCons(r)
 				structure += "[CVC]"
 				m = syll
 			}
 		case 2:
-			roll := tools.Dice(3)
+			roll := tools.RollDice(r, 3)
 			switch {
 			case roll == 1:
-				finalWord += getKkreeVowel()
+				finalWord += getKkreeVowel(r)
 				structure += "[V]"
 				useTable = 3
 			default:
-				finalWord += getKkreeVowel() + getKkreeFinalCons()
+				finalWord += getKkreeVowel(r) + getKkreeFinalCons(r)
 				structure += "[VC]"
 				useTable = 2
 			}
 		default:
-			roll := tools.Dice(5)
+			roll := tools.RollDice(r, 5)
 			switch {
 			case roll <= 3:
-				finalWord += getKkreeInitCons() + getKkreeVowel()
+				finalWord += getKkreeInitCons(r) + getKkreeVowel(r)
 				structure += "[CV]"
 				useTable = 3
 			default:
-				finalWord += getKkreeInitCons() + getKkreeVowel() + getKkreeFinalCons()
+				finalWord += getKkreeInitCons(r) + getKkreeVowel(r) + getKkreeFinalCons(r)
 				structure += "[CVC]"
 				m = syll
 			}
@@ -1049,9 +1050,9 @@ func generateKkreeWord() (finalWord string, structure string) {
 }
 
 // getKkreeVowel generates a K'kree vowel.
-func getKkreeVowel() string {
+func getKkreeVowel(r *rand.Rand) string {
 
-	roll := tools.Dice(60)
+	roll := tools.RollDice(r, 60)
 
 	if roll <= 19 {
 		return "a"
@@ -1085,8 +1086,8 @@ func getKkreeVowel() string {
 }
 
 // getKkreeInitCons generates a Kkree initial consonant.
-func getKkreeInitCons() string {
-	roll := tools.Dice(98)
+func getKkreeInitCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 98)
 
 	if roll <= 1 {
 		return "b"
@@ -1139,8 +1140,8 @@ func getKkreeInitCons() string {
 }
 
 // getKkreeFinalCons returns a K'kree final consonant.
-func getKkreeFinalCons() string {
-	roll := tools.Dice(42)
+func getKkreeFinalCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 42)
 
 	if roll <= 1 {
 		return "b"
@@ -1179,9 +1180,9 @@ func getKkreeFinalCons() string {
 }
 
 // generateDroyneWord generates a single Droyne word based on the published frequency tables.
-func generateDroyneWord() (finalWord string, structure string) {
+func generateDroyneWord(r *rand.Rand) (finalWord string, structure string) {
 	// Generate a word
-	syll := tools.D6()
+	syll := tools.RollD6(r)
 
 	useTable := 1
 
@@ -1190,42 +1191,42 @@ func generateDroyneWord() (finalWord string, structure string) {
 
 		switch useTable {
 		case 1:
-			roll := tools.Dice(36)
+			roll := tools.RollDice(r, 36)
 			switch {
 			case roll <= 7:
-				finalWord += getDroyneVowel()
+				finalWord += getDroyneVowel(r)
 				structure += "[V]"
 				useTable = 1
 			case roll <= 18:
-				finalWord += getDroyneInitCons() + getDroyneVowel()
+				finalWord += getDroyneInitCons(r) + getDroyneVowel(r)
 				structure += "[CV]"
 				useTable = 1
 			case roll <= 29:
-				finalWord += getDroyneVowel() + getDroyneFinalCons()
+				finalWord += getDroyneVowel(r) + getDroyneFinalCons(r)
 				structure += "[VC]"
 				useTable = 2
 			default:
-				finalWord += getDroyneInitCons() + getDroyneVowel() + getDroyneFinalCons()
+				finalWord += getDroyneInitCons(r) + getDroyneVowel(r) + getDroyneFinalCons(r)
 				structure += "[CVC]"
 				useTable = 2
 			}
 		default:
-			roll := tools.D6()
+			roll := tools.RollD6(r)
 			switch {
 			case roll == 1:
-				finalWord += getDroyneVowel()
+				finalWord += getDroyneVowel(r)
 				structure += "[V]"
 				useTable = 1
 			case roll == 2:
-				finalWord += getDroyneInitCons() + getDroyneVowel()
+				finalWord += getDroyneInitCons(r) + getDroyneVowel(r)
 				structure += "[CV]"
 				useTable = 1
 			case roll == 3:
-				finalWord += getDroyneVowel() + getDroyneFinalCons()
+				finalWord += getDroyneVowel(r) + getDroyneFinalCons(r)
 				structure += "[VC]"
 				useTable = 2
 			default:
-				finalWord += getDroyneInitCons() + getDroyneVowel() + getDroyneFinalCons()
+				finalWord += getDroyneInitCons(r) + getDroyneVowel(r) + getDroyneFinalCons(r)
 				structure += "[CVC]"
 				useTable = 2
 			}
@@ -1236,9 +1237,9 @@ func generateDroyneWord() (finalWord string, structure string) {
 }
 
 // getDroyneVowel gets a Droyne Vowel.
-func getDroyneVowel() string {
+func getDroyneVowel(r *rand.Rand) string {
 
-	roll := tools.Dice(58)
+	roll := tools.RollDice(r, 58)
 
 	if roll <= 7 {
 		return "a"
@@ -1264,8 +1265,8 @@ func getDroyneVowel() string {
 }
 
 // getDroyneInitCons returns a Droyne initial consonant.
-func getDroyneInitCons() string {
-	roll := tools.Dice(216)
+func getDroyneInitCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 216)
 
 	if roll <= 8 {
 		return "b"
@@ -1318,8 +1319,8 @@ func getDroyneInitCons() string {
 }
 
 // getDroyneFinalCons returns a Droyne final consonant.
-func getDroyneFinalCons() string {
-	roll := tools.Dice(216)
+func getDroyneFinalCons(r *rand.Rand) string {
+	roll := tools.RollDice(r, 216)
 
 	if roll <= 6 {
 		return "b"
@@ -1397,7 +1398,7 @@ func getDroyneFinalCons() string {
 
 }
 
-func generateSolomaniWord() (finalWord string, structure string) {
+func generateSolomaniWord(r *rand.Rand) (finalWord string, structure string) {
 
 	finalWord = "Not Implemented"
 	structure = "[CVC] [VC][CV][CVC][CVC]"
@@ -4,6 +4,7 @@ package main
 // main.go contains the main function and associated basic functions for the traveller program.
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -46,12 +47,23 @@ func main() {
 	log.Printf("Setting database file to %s", config.DatabaseFile)
 	SetDbFile(config.DatabaseFile) // This is the new version.
 
+	// A search takes any number of words, so it is handled before the commands with a fixed number of args.
+	if len(args) > 2 && (strings.EqualFold(args[1], "--search") || strings.EqualFold(args[1], "--export-search")) {
+		openDatabase()
+		if err = searchCommand(args); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// TODO: Swap this code out for flags, as command-line argument is likely to get more complicated in the future
 	switch len(args) {
 	case 1:
 		// Single arg would be the command itself, so run interactively.
+		openDatabase()
 	case 2:
-		// Two args would be either help or verion or error.
+		// Two args would be either help or verion, a rebuild of the search index, or error.
 		switch strings.ToLower(args[1]) {
 		case "--help":
 			// Print help information.
@@ -59,6 +71,14 @@ func main() {
 		case "--version":
 			// Print out program version information.
 			displayVersion()
+		case "--search-index":
+			openDatabase()
+			n, err := rebuildSearchIndex(context.Background(), config.Campaign, config.NotesDir)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fmt.Printf("Indexed %d worlds\n", n)
 		default:
 			// Any case that hasn't been accounted for now is incorrect.
 			fmt.Println("Incorrect command-line parameters")
@@ -67,6 +87,64 @@ func main() {
 		}
 		// In any case we exit immediately
 		os.Exit(0)
+	case 3, 4:
+		// Three or four args are an import, an export or conversion of a sector, an atlas or a world's handouts.
+		var err error
+		switch strings.ToLower(args[1]) {
+		case "--import", "--import-replace":
+			sector := ""
+			if len(args) == 4 {
+				sector = args[3]
+			}
+			openDatabase()
+			err = importSectorFile(args[2], sector, strings.ToLower(args[1]) == "--import-replace")
+		case "--export":
+			if len(args) == 4 {
+				openDatabase()
+				err = exportSector(args[2], args[3])
+			} else {
+				usage(config.program)
+			}
+		case "--atlas":
+			if len(args) == 4 {
+				openDatabase()
+				var n, worlds int
+				n, worlds, err = writeAtlas(atlasOptions{Campaign: config.Campaign, Sectors: strings.Split(args[2], ","),
+					Dir: args[3], NotesDir: config.NotesDir, Style: stylePoster})
+				if n > 0 {
+					fmt.Printf("Written %d sectors and %d worlds to %s\n", n, worlds, args[3])
+				}
+			} else {
+				usage(config.program)
+			}
+		case "--handouts":
+			if len(args) == 4 {
+				openDatabase()
+				var w *world
+				if w, err = findSectorWorld(args[2], args[3]); err == nil {
+					if err = writeHandouts(w, args[2], "handouts", config.NotesDir); err == nil {
+						fmt.Printf("Written the handouts for %s to handouts\n", w.name)
+					}
+				}
+			} else {
+				usage(config.program)
+			}
+		case "--convert":
+			if len(args) == 4 {
+				openDatabase()
+				err = convertSectorFile(args[2], args[3])
+			} else {
+				usage(config.program)
+			}
+		default:
+			fmt.Println("Incorrect command-line parameters")
+			usage(config.program)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
 	default:
 		// Any other
 		usage(config.program)
@@ -95,6 +173,7 @@ func main() {
 
 	// Run the main show.
 	Run(platform, renderer)
+	repo.close()
 
 	// Finish the program
 	fmt.Println()
@@ -102,9 +181,70 @@ func main() {
 
 }
 
+// openDatabase creates the database, or brings it up to the schema this version of the program uses, and exits if it
+// cannot. It is only called for the commands that use the database, so that --help and --version never touch it.
+func openDatabase() {
+	if err := repo.migrate(context.Background()); err != nil {
+		log.Print(err)
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
 // usage prints out a usage statement, taking the program name as an argument.
 func usage(prog string) {
 	fmt.Printf("Usage: %s [--help|--version]\n", prog)
+	fmt.Printf("       %s --import|--import-replace <sector file> [<sector>]\n", prog)
+	fmt.Printf("       %s --export <sector> <file>\n", prog)
+	fmt.Printf("       %s --convert <sector file> <file>\n", prog)
+	fmt.Printf("       %s --atlas <sector>[,<sector>...] <directory>\n", prog)
+	fmt.Printf("       %s --handouts <sector> <hex>\n", prog)
+	fmt.Printf("       %s --search <words> | --search-index\n", prog)
+	fmt.Printf("       %s --export-search <file> <words>\n", prog)
+	fmt.Printf("The format of the file written is chosen by its extension: %s\n", strings.Join(formatExtensions(), ", "))
+}
+
+// searchCommand runs a search from the command line, printing the worlds found or, for --export-search, writing
+// them to the file.
+func searchCommand(args []string) error {
+	if strings.EqualFold(args[1], "--search") {
+		return printSearch(strings.Join(args[2:], " "))
+	}
+	if len(args) < 4 {
+		usage(config.program)
+		return nil
+	}
+	return exportSearch(strings.Join(args[3:], " "), args[2])
+}
+
+// exportSector writes the named sector from the database to the file.
+func exportSector(name, filename string) error {
+	s, err := loadSector(name)
+	if err != nil {
+		return err
+	}
+	if err = writeSectorFile(s, filename); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d worlds of %s to %s\n", len(s.worlds), s.name, filename)
+	return nil
+}
+
+// convertSectorFile reads a sector file, in whatever format it is, and writes it to the other file. The lines that
+// could not be read are reported.
+func convertSectorFile(in, out string) error {
+	s, errs, err := readSectorFile(in)
+	for _, e := range errs {
+		fmt.Printf("%s: %v\n", in, e)
+	}
+	if err != nil {
+		return err
+	}
+	if err = writeSectorFile(s, out); err != nil {
+		return err
+	}
+	fmt.Printf("Converted %d worlds of %s to %s\n", len(s.worlds), s.name, out)
+	return nil
 }
 
 // displayVersion displays the application version.
@@ -0,0 +1,278 @@
+// Package metadata reads and writes sector metadata in the XML format used by Travellermap, such as the
+// foreven-metadata.xml file. The metadata holds what the tab-delimited world data does not: the sector's names and
+// location, credits, subsector names, allegiances, borders, routes, labels and products.
+package metadata
+
+import (
+	"encoding/xml"
+	"html"
+	"io"
+	"os"
+	"strings"
+)
+
+// Sector is the <Sector> element at the root of the metadata.
+type Sector struct {
+	Selected     bool   // Whether the sector is selected on the map
+	Tags         string // Space separated tags, eg "Official OTU"
+	Abbreviation string // The four letter abbreviation, eg "Spin"
+	Names        []Name // The names of the sector, the main name first
+	Credits      string // The credits for the sector, as HTML
+	X, Y         int    // The location of the sector, in sectors from Core
+	Products     []Product
+	DataFile     *DataFile
+	Subsectors   []Subsector
+	Allegiances  []Allegiance
+	Stylesheet   string // CSS for the borders and routes of the allegiances
+	Borders      []Border
+	Labels       []Label
+	Routes       []Route
+}
+
+// sectorXML is the layout of the <Sector> element. The lists are pointers so that empty lists are left out, and
+// the credits are kept as raw XML as they may hold HTML elements.
+type sectorXML struct {
+	XMLName      xml.Name `xml:"Sector"`
+	Selected     bool     `xml:"Selected,attr,omitempty"`
+	Tags         string   `xml:"Tags,attr,omitempty"`
+	Abbreviation string   `xml:"Abbreviation,attr,omitempty"`
+	Names        []Name   `xml:"Name"`
+	Credits      *struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"Credits"`
+	X          int       `xml:"X"`
+	Y          int       `xml:"Y"`
+	Products   []Product `xml:"Product"`
+	DataFile   *DataFile `xml:"DataFile"`
+	Subsectors *struct {
+		List []Subsector `xml:"Subsector"`
+	} `xml:"Subsectors"`
+	Allegiances *struct {
+		List []Allegiance `xml:"Allegiance"`
+	} `xml:"Allegiances"`
+	Stylesheet string `xml:"Stylesheet,omitempty"`
+	Borders    *struct {
+		List []Border `xml:"Border"`
+	} `xml:"Borders"`
+	Labels *struct {
+		List []Label `xml:"Label"`
+	} `xml:"Labels"`
+	Routes *struct {
+		List []Route `xml:"Route"`
+	} `xml:"Routes"`
+}
+
+// Name is a name of the sector. The name without a language is the main name.
+type Name struct {
+	Lang   string `xml:"Lang,attr,omitempty"`   // The language code of the name, eg "zh"
+	Source string `xml:"Source,attr,omitempty"` // Where the name comes from
+	Text   string `xml:",chardata"`
+}
+
+// Product is a published product that covers the sector.
+type Product struct {
+	Title     string `xml:"Title,attr,omitempty"`
+	Author    string `xml:"Author,attr,omitempty"`
+	Publisher string `xml:"Publisher,attr,omitempty"`
+	Ref       string `xml:"Ref,attr,omitempty"`
+}
+
+// DataFile describes where the sector's world data comes from, and its milieu.
+type DataFile struct {
+	Source    string `xml:"Source,attr,omitempty"`
+	Milieu    string `xml:"Milieu,attr,omitempty"` // The milieu abbreviation, eg "M1105"
+	Title     string `xml:"Title,attr,omitempty"`
+	Author    string `xml:"Author,attr,omitempty"`
+	Publisher string `xml:"Publisher,attr,omitempty"`
+	Ref       string `xml:"Ref,attr,omitempty"`
+}
+
+// Subsector is the name of one of the subsectors.
+type Subsector struct {
+	Index string `xml:"Index,attr"` // The subsector index, "A" to "P"
+	Name  string `xml:",chardata"`
+}
+
+// Allegiance defines an allegiance code used by the worlds of the sector.
+type Allegiance struct {
+	Code string `xml:"Code,attr"`           // The four letter code, eg "CsIm"
+	Base string `xml:"Base,attr,omitempty"` // The two letter base code, eg "Na"
+	Name string `xml:",chardata"`
+}
+
+// Border is the border of an allegiance, as a path of hexes.
+type Border struct {
+	Allegiance    string `xml:"Allegiance,attr,omitempty"`
+	Label         string `xml:"Label,attr,omitempty"`
+	LabelPosition string `xml:"LabelPosition,attr,omitempty"`
+	ShowLabel     string `xml:"ShowLabel,attr,omitempty"` // "false" to hide the label
+	WrapLabel     bool   `xml:"WrapLabel,attr,omitempty"`
+	Color         string `xml:"Color,attr,omitempty"`
+	Style         string `xml:"Style,attr,omitempty"`
+	Path          string `xml:",chardata"` // The hexes, separated by spaces, eg "0223 0323 0422"
+}
+
+// Hexes returns the hexes of the border's path.
+func (b Border) Hexes() []string {
+	return strings.Fields(b.Path)
+}
+
+// Label is some text shown on the map.
+type Label struct {
+	Hex     string  `xml:"Hex,attr"`
+	Color   string  `xml:"Color,attr,omitempty"`
+	Size    string  `xml:"Size,attr,omitempty"` // "small" or "large"
+	Wrap    bool    `xml:"Wrap,attr,omitempty"`
+	OffsetY float64 `xml:"OffsetY,attr,omitempty"`
+	Text    string  `xml:",chardata"`
+}
+
+// Route is a single link of an xboat or trade route. The offsets are for links that cross into a neighbouring
+// sector, eg EndOffsetX="1" for the sector to trailing.
+type Route struct {
+	Start        string  `xml:"Start,attr"`
+	End          string  `xml:"End,attr"`
+	StartOffsetX int     `xml:"StartOffsetX,attr,omitempty"`
+	StartOffsetY int     `xml:"StartOffsetY,attr,omitempty"`
+	EndOffsetX   int     `xml:"EndOffsetX,attr,omitempty"`
+	EndOffsetY   int     `xml:"EndOffsetY,attr,omitempty"`
+	Allegiance   string  `xml:"Allegiance,attr,omitempty"`
+	Type         string  `xml:"Type,attr,omitempty"`
+	Style        string  `xml:"Style,attr,omitempty"` // "Solid", "Dashed" or "Dotted"
+	Color        string  `xml:"Color,attr,omitempty"`
+	Width        float64 `xml:"Width,attr,omitempty"`
+}
+
+// Name returns the main name of the sector, the first name without a language.
+func (s *Sector) Name() string {
+	for _, n := range s.Names {
+		if n.Lang == "" {
+			return strings.TrimSpace(n.Text)
+		}
+	}
+	if len(s.Names) > 0 {
+		return strings.TrimSpace(s.Names[0].Text)
+	}
+	return ""
+}
+
+// Milieu returns the milieu of the sector's data, or "" if there is none.
+func (s *Sector) Milieu() string {
+	if s.DataFile == nil {
+		return ""
+	}
+	return s.DataFile.Milieu
+}
+
+// Read reads sector metadata from the reader.
+func Read(r io.Reader) (*Sector, error) {
+	var x sectorXML
+	if err := xml.NewDecoder(r).Decode(&x); err != nil {
+		return nil, err
+	}
+	s := &Sector{Selected: x.Selected, Tags: x.Tags, Abbreviation: x.Abbreviation, Names: x.Names, X: x.X, Y: x.Y,
+		Products: x.Products, DataFile: x.DataFile, Stylesheet: strings.TrimSpace(x.Stylesheet)}
+	if x.Credits != nil {
+		// Credits may be HTML elements or escaped HTML, so both end up as HTML, on one line.
+		s.Credits = strings.Join(strings.Fields(html.UnescapeString(x.Credits.Inner)), " ")
+	}
+	if x.Subsectors != nil {
+		s.Subsectors = x.Subsectors.List
+	}
+	if x.Allegiances != nil {
+		s.Allegiances = x.Allegiances.List
+	}
+	if x.Borders != nil {
+		s.Borders = x.Borders.List
+	}
+	if x.Labels != nil {
+		s.Labels = x.Labels.List
+	}
+	if x.Routes != nil {
+		s.Routes = x.Routes.List
+	}
+	for i := range s.Names {
+		s.Names[i].Text = strings.TrimSpace(s.Names[i].Text)
+	}
+	for i := range s.Subsectors {
+		s.Subsectors[i].Name = strings.TrimSpace(s.Subsectors[i].Name)
+	}
+	for i := range s.Borders {
+		s.Borders[i].Path = strings.Join(s.Borders[i].Hexes(), " ")
+	}
+	return s, nil
+}
+
+// ReadFile reads sector metadata from the named file.
+func ReadFile(filename string) (*Sector, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Write writes the sector metadata to the writer, with an XML header.
+func (s *Sector) Write(w io.Writer) error {
+	x := sectorXML{Selected: s.Selected, Tags: s.Tags, Abbreviation: s.Abbreviation, Names: s.Names, X: s.X, Y: s.Y,
+		Products: s.Products, DataFile: s.DataFile, Stylesheet: s.Stylesheet}
+	if s.Credits != "" {
+		var b strings.Builder
+		if err := xml.EscapeText(&b, []byte(s.Credits)); err != nil {
+			return err
+		}
+		x.Credits = &struct {
+			Inner string `xml:",innerxml"`
+		}{b.String()}
+	}
+	if len(s.Subsectors) > 0 {
+		x.Subsectors = &struct {
+			List []Subsector `xml:"Subsector"`
+		}{s.Subsectors}
+	}
+	if len(s.Allegiances) > 0 {
+		x.Allegiances = &struct {
+			List []Allegiance `xml:"Allegiance"`
+		}{s.Allegiances}
+	}
+	if len(s.Borders) > 0 {
+		x.Borders = &struct {
+			List []Border `xml:"Border"`
+		}{s.Borders}
+	}
+	if len(s.Labels) > 0 {
+		x.Labels = &struct {
+			List []Label `xml:"Label"`
+		}{s.Labels}
+	}
+	if len(s.Routes) > 0 {
+		x.Routes = &struct {
+			List []Route `xml:"Route"`
+		}{s.Routes}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(x); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteFile writes the sector metadata to the named file, replacing it if it exists.
+func (s *Sector) WriteFile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err = s.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,196 @@
+package main
+
+// migrate.go contains the schema migrations that build the traveller database. The SQL scripts that used to be run
+// by hand are embedded in the program as numbered migrations (see the migrations directory), and the version of the
+// schema is kept in the schema_version table. When the program starts, a new database is built from scratch and an
+// older one is brought up to date, so that there is nothing to set up before the program is first run.
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFiles holds the migration scripts, named with their version number then an underscore, e.g.
+// 0005_fixes.sql. The versions number up from 1 without gaps.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migration is a numbered script that changes the schema (or data) of the database.
+type migration struct {
+	version int    // The schema version after the migration is applied
+	name    string // The name of the migration, from its file name
+	script  string // The SQL statements of the migration
+}
+
+// schemaVersionTable records each migration applied to the database.
+const schemaVersionTable = `CREATE TABLE IF NOT EXISTS "schema_version" (
+	"version"	INTEGER NOT NULL PRIMARY KEY,
+	"name"	TEXT NOT NULL,
+	"applied"	TEXT NOT NULL
+)`
+
+// migrationProbes tell whether each migration has already been applied to a database that was built by running
+// the scripts by hand, before there was a schema_version table. Each probe counts the rows that show the migration's
+// changes are present; where a script could stop part way through, the probe looks for its data rather than its last
+// table, so that data is never loaded twice. Migrations written since schema_version was added need no probe.
+var migrationProbes = map[int]string{
+	1:  "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'world'",
+	2:  "SELECT count(*) FROM world WHERE sector_id IN (38, 173, 237, 253)", // Dark Nebula, Provence, Vland, Zhodane
+	3:  "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'rulebook'",
+	4:  "SELECT count(*) FROM pragma_table_info('skill') WHERE name = 'rulebook_id'",
+	5:  "SELECT count(*) FROM allegiance WHERE code = 'ImXX'",
+	6:  "SELECT count(*) FROM pragma_table_info('subsector') WHERE name = 'subsector_index'",
+	7:  "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'stellar_detail'",
+	8:  "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'world_system'",
+	9:  "SELECT count(*) FROM pragma_table_info('stellar_detail') WHERE name = 'luminosity'",
+	10: "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'campaign_overlay'",
+	11: "SELECT count(*) FROM pragma_table_info('sector') WHERE name = 'otu'",
+	12: "SELECT count(*) FROM pragma_index_info('subsector_sector_index') WHERE name = 'subsector_index'",
+}
+
+// loadMigrations returns the embedded migrations in order of version.
+func loadMigrations() ([]migration, error) {
+	files, e := migrationFiles.ReadDir("migrations")
+	if e != nil {
+		return nil, e
+	}
+	ms := make([]migration, 0, len(files))
+	for _, f := range files {
+		base := strings.TrimSuffix(f.Name(), path.Ext(f.Name()))
+		num, name, _ := strings.Cut(base, "_")
+		v, e := strconv.Atoi(num)
+		if e != nil {
+			return nil, fmt.Errorf("%w: %s", ErrMigrationName, f.Name())
+		}
+		b, e := migrationFiles.ReadFile(path.Join("migrations", f.Name()))
+		if e != nil {
+			return nil, e
+		}
+		ms = append(ms, migration{version: v, name: name, script: string(b)})
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].version < ms[j].version })
+	for i, m := range ms {
+		if m.version != i+1 {
+			return nil, fmt.Errorf("%w: version %d", ErrMigrationMissing, i+1)
+		}
+	}
+	return ms, nil
+}
+
+// migrate brings the database up to the current schema, creating it if the database file is new. The migrations
+// not yet applied are applied in one transaction, so a migration that fails leaves the database as it was. A
+// database with a newer schema than the migrations in this program is refused with ErrDatabaseTooNew, as the
+// program could damage data it does not understand.
+func (r *repository) migrate(ctx context.Context) error {
+	ms, e := loadMigrations()
+	if e != nil {
+		return e
+	}
+	db, e := r.conn(ctx)
+	if e != nil {
+		return e
+	}
+	tx, e := db.BeginTx(ctx, nil)
+	if e != nil {
+		return e
+	}
+	defer tx.Rollback()
+
+	applied, e := appliedMigrations(ctx, tx, ms)
+	if e != nil {
+		return e
+	}
+	for v := range applied {
+		if v > len(ms) {
+			return fmt.Errorf("%w: database is at schema version %d, this program knows up to %d", ErrDatabaseTooNew, v, len(ms))
+		}
+	}
+
+	n := 0
+	for _, m := range ms {
+		if applied[m.version] {
+			continue
+		}
+		if _, e = tx.ExecContext(ctx, m.script); e != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, e)
+		}
+		if e = recordMigration(ctx, tx, m); e != nil {
+			return e
+		}
+		log.Printf("Applied database migration %d (%s)", m.version, m.name)
+		n++
+	}
+	if e = tx.Commit(); e != nil {
+		return e
+	}
+	if n > 0 {
+		log.Printf("Database %s is at schema version %d", r.file, len(ms))
+	}
+	return nil
+}
+
+// appliedMigrations returns the versions of the migrations applied to the database, creating the schema_version
+// table if it is not there. A database that has tables but no schema_version table was built by running the scripts
+// by hand, so the migration probes are used to find out which migrations it has, and those are recorded as applied.
+func appliedMigrations(ctx context.Context, tx *sql.Tx, ms []migration) (map[int]bool, error) {
+	var versioned, world int
+	if e := tx.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'").Scan(&versioned); e != nil {
+		return nil, e
+	}
+	if e := tx.QueryRowContext(ctx, migrationProbes[1]).Scan(&world); e != nil {
+		return nil, e
+	}
+	if _, e := tx.ExecContext(ctx, schemaVersionTable); e != nil {
+		return nil, e
+	}
+
+	applied := make(map[int]bool)
+	if versioned == 0 && world > 0 {
+		for _, m := range ms {
+			probe, ok := migrationProbes[m.version]
+			if !ok {
+				continue
+			}
+			var n int
+			if e := tx.QueryRowContext(ctx, probe).Scan(&n); e != nil {
+				return nil, fmt.Errorf("migration %d (%s): %w", m.version, m.name, e)
+			}
+			if n == 0 {
+				continue
+			}
+			if e := recordMigration(ctx, tx, m); e != nil {
+				return nil, e
+			}
+			log.Printf("Database already has migration %d (%s)", m.version, m.name)
+		}
+	}
+
+	rows, e := tx.QueryContext(ctx, "SELECT version FROM schema_version")
+	if e != nil {
+		return nil, e
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v int
+		if e = rows.Scan(&v); e != nil {
+			return nil, e
+		}
+		applied[v] = true
+	}
+	return applied, rows.Err()
+}
+
+// recordMigration records in the schema_version table that the migration has been applied.
+func recordMigration(ctx context.Context, tx *sql.Tx, m migration) error {
+	_, e := tx.ExecContext(ctx, "INSERT INTO schema_version (version, name, applied) VALUES (?, ?, datetime('now'))",
+		m.version, m.name)
+	return e
+}
@@ -3472,7 +3472,7 @@ SET capital_id = (
 UPDATE subsector
 SET capital_id = (
 	SELECT id FROM world WHERE name LIKE '%Capital%')
-WHERE subsector.name LIKE '%Core%'
+WHERE subsector.name LIKE '%Core%';
 
 -- Fix up the Race table for Core/Core/Capital
 UPDATE race
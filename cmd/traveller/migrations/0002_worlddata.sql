@@ -87,14 +87,13 @@ INSERT INTO world ("sector_id","subsector_index","hex","name","UWP","bases","rem
 	(38,"B","1108","Hasyesya","C000333-A","","As Lo Va Da","A","512","NaAs","K4 V","{ 0 }","(821-3)","[1327]","",14,-48),
 	(38,"B","1110","Oeuilyuh","BA79413-7","","Ni","","910","NaAs","M3 V","{ -1 }","(632-4)","[1324]","",8,-144),
 	(38,"B","1203","Trienamm","C200262-7","","Lo Va O:1003","","120","NaAs","M3 V","{ -2 }","(411-5)","[1113]","",10,-20),
-	(38,"B","1205","Uaweisa","B7B2676-A","","Fl He Ni","","603","NaAs","K0 V","{ 1 }","(B55+1)","[5749]","",12,275),
-	(38,"B","1207","Hfieao","E576000-0","","Ba","","004","NaXX","A1 IV F7 V","{ -3 }","(200-5)","[0000]",""INSERT INTO world ("sector_id","subsector_index","hex","name","UWP","bases","remarks",
+	(38,"B","1205","Uaweisa","B7B2676-A","","Fl He Ni","","603","NaAs","K0 V","{ 1 }","(B55+1)","[5749]","",12,275);
+
+-- The rows for hexes 1207 to 1304 were lost from this copy of the data; they can be imported again from
+-- travellermap.com with the sector import.
+INSERT INTO world ("sector_id","subsector_index","hex","name","UWP","bases","remarks",
 "zone","PBG","allegiance","stars","importance","economics","culture","nobility","worlds","RU")
   VALUES
-	(38,"A","0106","Uiwe","D100389-A","","Lo Va","","523","AsMw","M2 V","{ -1 }","(A21+1)","[426B]","",17,20),
-	(38,"A","0107","Oukheayekh","C10068A-8","","Na Ni Va","","200","AsVc","K0 V","{ -2 }","(852+1)","[847A]","",9,80),
-	(38,"A","0109","Oifairouw","A646215-9","","Lo","","512","AsMw","G6 V","{ 0 }","(711-2)","[1237]","",9,-14),
-16,165),
 	(38,"B","1305","Uaoya","A422556-E","KM","He Ni Po","","224","NaAs","K1 V","{ 2 }","(D46+1)","[474D]","",15,312),
 	(38,"B","1310","Teioyukh","B310530-A","","Ni","","220","NaAs","K1 III","{ 1 }","(945-3)","[1615]","",9,-540),
 	(38,"B","1402","Reawaiy","C647234-8","","Lo","","502","NaAs","M3 III","{ -2 }","(611-4)","[1136]","",10,-24),
@@ -560,7 +559,7 @@ INSERT INTO subsector (name,lang_id,sector_id,sector_index,capital_id)
 SELECT subsector.id, subsector.name, subsector.sector_index, subsector.capital_id, world.id, world.name 
   FROM subsector,world
   WHERE 
-	world.sector_id=38 AND subsector.sector_id=38 AND world.subsector_index = subsector.sector_index AND world.remarks LIKE '%Cp%'
+	world.sector_id=38 AND subsector.sector_id=38 AND world.subsector_index = subsector.sector_index AND world.remarks LIKE '%Cp%';
 
 -- Update the Aslan race with Homeworld data
 UPDATE race
@@ -2189,7 +2188,7 @@ CREATE TABLE "world_staging" (
 	"worlds"	INTEGER,
 	"RU"	INTEGER,
 	"sector_id"	INTEGER
-)
+);
 
 -- Discovered there are duplicates in the sector table (in terms of abbreviations)
 SELECT abbreviation, count(*)
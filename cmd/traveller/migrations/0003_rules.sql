@@ -134,7 +134,7 @@ INSERT INTO skill ("skill_name","description","parent_skill","is_virtual","rules
 -- This is the complete list of Vehicle skills:Aircraft (select Helicopter, Propeller-driven Fixed Wing, or Jet-driven Fixed Wing), Grav
 -- Vehicle, Tracked Vehicle, Wheeled Vehicle, and Watercraft (select Small Watercraft, Large Watercraft, Hovercraft, or Submersible)
 
-DELETE FROM skill where "skill_name" in ("Ground Car","Watercraft","Winged Craft","Grav Belt")
+DELETE FROM skill where "skill_name" in ("Ground Car","Watercraft","Winged Craft","Grav Belt");
 
 -- The corrections for this are as follows:
 INSERT INTO skill ("skill_name","description","parent_skill","is_virtual","ruleset")
@@ -188,7 +188,7 @@ INSERT INTO rulebook ("abbreviation","rulebook_name","ruleset_id","comments")
     ("TIM #11","Third Imperium Magazine #11",(SELECT id FROM ruleset WHERE abbreviation="CT"),"Third Imperium Magazine #11"),
     ("CT S04","Supplement 4",(SELECT id FROM ruleset WHERE abbreviation="CT"),"Classic Traveller Supplement 4 - Citizens of the Imperium"),
     ("JTAS #22","Journal of the Travellers Aid Society #22",(SELECT id FROM ruleset WHERE abbreviation="CT"),"Journal of the Travellers Aid Society #22"),
-    ("TIM #8","Third Imperium Magazine #8",(SELECT id FROM ruleset WHERE abbreviation="CT"),"Third Imperium Magazine #8")
+    ("TIM #8","Third Imperium Magazine #8",(SELECT id FROM ruleset WHERE abbreviation="CT"),"Third Imperium Magazine #8"),
     ("JTAS #11","Journal of the Travellers Aid Society #11",(SELECT id FROM ruleset WHERE abbreviation="CT"),"Journal of the Travellers Aid Society #11"),
     ("JTAS #12","Journal of the Travellers Aid Society #12",(SELECT id FROM ruleset WHERE abbreviation="CT"),"Journal of the Travellers Aid Society #12"),
     ("JTAS #18","Journal of the Travellers Aid Society #18",(SELECT id FROM ruleset WHERE abbreviation="CT"),"Journal of the Travellers Aid Society #18"),
@@ -226,28 +226,7 @@ INSERT INTO rulebook ("abbreviation","rulebook_name","ruleset_id","comments")
 -- Traveller5
     ("T5 Core", "Traveller5 Core Rulebook",(SELECT id FROM ruleset WHERE abbreviation="T5"),"Traveller5 Core Rulebook"),
 -- Mongoose Traveller 2nd Edition
-    ("MGT2 Core", "Mongoose Traveller 2e Core Rulebook",(SELECT id FROM ruleset WHERE abbreviation="MGT2"),"Mongoose Traveller 2nd Edition Core Rulebook")
-
--- Modify the Skill table for the new "rulebook" based way.
-BEGIN TRANSACTION;
-ALTER TABLE skill rename to skill_old;
-CREATE TABLE "skill" (
-	"id"	INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
-	"skill_name"	TEXT NOT NULL,
-	"description"	TEXT NOT NULL,
-	"parent_skill"	INTEGER,
-	"is_virtual"	INTEGER,
-	"rulebook_id"	INTEGER
-);
-
-INSERT INTO skill ("id","skill_name","description","parent_skill","is_virtual","rulebook_id")
-SELECT "id","skill_name","description","parent_skill","is_virtual","ruleset" FROM skill_old;
-
-DROP TABLE skill_old
-COMMIT;
-
--- Now change the data so it is pointing to Book 1 instead of just to CT
-UPDATE skill SET rulebook_id = (SELECT id FROM rulebook WHERE abbreviation='CT B01')
+    ("MGT2 Core", "Mongoose Traveller 2e Core Rulebook",(SELECT id FROM ruleset WHERE abbreviation="MGT2"),"Mongoose Traveller 2nd Edition Core Rulebook");
 
 -- I've found this one - an update for the Crenduthaar.
 UPDATE RACE 
@@ -270,7 +249,7 @@ WHERE homeworld_id IS NULL;
 
 -- Bad initial data for this one
 UPDATE race
-SET homeworld = "Ul" WHERE race_name = "Ulane"
+SET homeworld = "Ul" WHERE race_name = "Ulane";
 
 UPDATE race
 SET homeworld_id = (SELECT id FROM world WHERE name = 'Ul')
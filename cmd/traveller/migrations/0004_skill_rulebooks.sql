@@ -0,0 +1,21 @@
+-- Skills belong to a rulebook rather than to a ruleset. SQLite cannot rename a column in older versions, so the
+-- skill table is rebuilt with the ruleset column renamed to rulebook_id. This was part of database03-rules.sql,
+-- where it is now split out so that it can be applied to databases that were built before the change.
+--
+ALTER TABLE skill RENAME TO skill_old;
+CREATE TABLE "skill" (
+	"id"	INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"skill_name"	TEXT NOT NULL,
+	"description"	TEXT NOT NULL,
+	"parent_skill"	INTEGER,
+	"is_virtual"	INTEGER,
+	"rulebook_id"	INTEGER
+);
+
+INSERT INTO skill ("id","skill_name","description","parent_skill","is_virtual","rulebook_id")
+SELECT "id","skill_name","description","parent_skill","is_virtual","ruleset" FROM skill_old;
+
+DROP TABLE skill_old;
+
+-- Now change the data so it is pointing to Book 1 instead of just to CT
+UPDATE skill SET rulebook_id = (SELECT id FROM rulebook WHERE abbreviation='CT B01');
@@ -179,27 +179,7 @@ UPDATE subsector SET capital_id=123834 WHERE id=2190;
 UPDATE subsector SET capital_id=124578 WHERE id=2206;
 --UPDATE subsector SET capital_id=125635 WHERE id=2243;
 
--- Fix the SECTOR/SUBSECTOR issue
--- Modify the Skill table for the new "rulebook" based way.
-BEGIN TRANSACTION;
-ALTER TABLE subsector rename to subsector_old;
-CREATE TABLE "subsector" (
-	"id"	INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
-	"name"	TEXT NOT NULL,
-	"lang_id"	INTEGER NOT NULL,
-	"sector_id"	INTEGER NOT NULL,
-	"subsector_index"	TEXT NOT NULL,
-	"capital_id"	INTEGER,
-	"remarks"	TEXT
-);
-
-INSERT INTO subsector ("id","name","lang_id","sector_id","subsector_index","capital_id","remarks")
-SELECT "id","name","lang_id","sector_id","sector_index","capital_id","remarks" FROM subsector_old;
-
-DROP TABLE subsector_old
-COMMIT;
-
 -- This code needs to be in there.
 INSERT INTO allegiance ("code", "legacy_code", "allegiance_name")
-VALUES ("ImXX","Im","Third Imperium")
+VALUES ("ImXX","Im","Third Imperium");
 
@@ -0,0 +1,20 @@
+-- Fix the SECTOR/SUBSECTOR issue: the subsector's index within its sector is called subsector_index, as it is in
+-- the world table. The subsector table is rebuilt with the sector_index column renamed. This was part of
+-- database04_fixes.sql, where it is now split out so that it can be applied to databases that were built before
+-- the change.
+--
+ALTER TABLE subsector RENAME TO subsector_old;
+CREATE TABLE "subsector" (
+	"id"	INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"name"	TEXT NOT NULL,
+	"lang_id"	INTEGER NOT NULL,
+	"sector_id"	INTEGER NOT NULL,
+	"subsector_index"	TEXT NOT NULL,
+	"capital_id"	INTEGER,
+	"remarks"	TEXT
+);
+
+INSERT INTO subsector ("id","name","lang_id","sector_id","subsector_index","capital_id","remarks")
+SELECT "id","name","lang_id","sector_id","sector_index","capital_id","remarks" FROM subsector_old;
+
+DROP TABLE subsector_old;
@@ -1,4 +1,10 @@
 -- stars.sql contains code for creating the tables for Stars
+--
+-- The stellar tables hold only reference data, so any left from an earlier run of this script that stopped part way
+-- through are dropped and built again.
+DROP TABLE IF EXISTS "stellar_detail";
+DROP TABLE IF EXISTS "stellar_spectral";
+DROP TABLE IF EXISTS "stellar_luminosity";
 
 -- Stellar Luminosity (size)
 CREATE TABLE "stellar_luminosity" (
@@ -27,7 +33,7 @@ CREATE TABLE "stellar_spectral" (
 	"name"	TEXT NOT NULL,
 	"colour"	TEXT NOT NULL,
 	"comment"	TEXT
-)
+);
 
 -- Values for Stellar Spectral Class
 INSERT INTO stellar_spectral (id,name,colour,comment)
@@ -51,7 +57,7 @@ CREATE TABLE "stellar_detail" (
 	"min_zone"	INTEGER NOT NULL,
 	"mass"	REAL NOT NULL,
 	PRIMARY KEY("id")
-)
+);
 
 -- Values for Stellar Detail
 INSERT INTO stellar_detail (id,name,luminosity_id,spectral_id,spectral_decimal,habitable_zone,min_zone,mass)
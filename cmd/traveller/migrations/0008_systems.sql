@@ -0,0 +1,10 @@
+-- Generated star system detail for (canonical) worlds. The system is stored as JSON (see starSystem.go)
+-- alongside the world row, so that the canonical data in the world table is never changed.
+--
+CREATE TABLE IF NOT EXISTS "world_system" (
+	"world_id"	INTEGER NOT NULL,
+	"generated"	TEXT NOT NULL,
+	"system_json"	TEXT NOT NULL,
+	PRIMARY KEY("world_id"),
+	FOREIGN KEY("world_id") REFERENCES "world"("id")
+);
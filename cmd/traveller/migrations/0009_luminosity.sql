@@ -0,0 +1,524 @@
+-- database06-luminosity.sql adds the luminosity (in standard Sol luminosities) to the stellar_detail table,
+-- which is needed for the world temperature model. Values are interpolated (logarithmically) between the
+-- standard values for each spectral class at decimals 0 and 5.
+--
+ALTER TABLE stellar_detail ADD COLUMN "luminosity" REAL NOT NULL DEFAULT 1;
+--
+UPDATE stellar_detail SET luminosity=22400000.0 WHERE id=1; -- O0 O
+UPDATE stellar_detail SET luminosity=16600000.0 WHERE id=2; -- O1 O
+UPDATE stellar_detail SET luminosity=12300000.0 WHERE id=3; -- O2 O
+UPDATE stellar_detail SET luminosity=9119000.0 WHERE id=4; -- O3 O
+UPDATE stellar_detail SET luminosity=6758000.0 WHERE id=5; -- O4 O
+UPDATE stellar_detail SET luminosity=5009000.0 WHERE id=6; -- O5 O
+UPDATE stellar_detail SET luminosity=3712000.0 WHERE id=7; -- O6 O
+UPDATE stellar_detail SET luminosity=2751000.0 WHERE id=8; -- O7 O
+UPDATE stellar_detail SET luminosity=2039000.0 WHERE id=9; -- O8 O
+UPDATE stellar_detail SET luminosity=1511000.0 WHERE id=10; -- O9 O
+UPDATE stellar_detail SET luminosity=1120000.0 WHERE id=11; -- B0 O
+UPDATE stellar_detail SET luminosity=915200.0 WHERE id=12; -- B1 O
+UPDATE stellar_detail SET luminosity=747800.0 WHERE id=13; -- B2 O
+UPDATE stellar_detail SET luminosity=611100.0 WHERE id=14; -- B3 O
+UPDATE stellar_detail SET luminosity=499300.0 WHERE id=15; -- B4 O
+UPDATE stellar_detail SET luminosity=408000.0 WHERE id=16; -- B5 O
+UPDATE stellar_detail SET luminosity=358600.0 WHERE id=17; -- B6 O
+UPDATE stellar_detail SET luminosity=315200.0 WHERE id=18; -- B7 O
+UPDATE stellar_detail SET luminosity=277000.0 WHERE id=19; -- B8 O
+UPDATE stellar_detail SET luminosity=243500.0 WHERE id=20; -- B9 O
+UPDATE stellar_detail SET luminosity=214000.0 WHERE id=21; -- A0 O
+UPDATE stellar_detail SET luminosity=202400.0 WHERE id=22; -- A1 O
+UPDATE stellar_detail SET luminosity=191400.0 WHERE id=23; -- A2 O
+UPDATE stellar_detail SET luminosity=181100.0 WHERE id=24; -- A3 O
+UPDATE stellar_detail SET luminosity=171300.0 WHERE id=25; -- A4 O
+UPDATE stellar_detail SET luminosity=162000.0 WHERE id=26; -- A5 O
+UPDATE stellar_detail SET luminosity=153100.0 WHERE id=27; -- A6 O
+UPDATE stellar_detail SET luminosity=144600.0 WHERE id=28; -- A7 O
+UPDATE stellar_detail SET luminosity=136700.0 WHERE id=29; -- A8 O
+UPDATE stellar_detail SET luminosity=129100.0 WHERE id=30; -- A9 O
+UPDATE stellar_detail SET luminosity=122000.0 WHERE id=31; -- F0 O
+UPDATE stellar_detail SET luminosity=117700.0 WHERE id=32; -- F1 O
+UPDATE stellar_detail SET luminosity=113600.0 WHERE id=33; -- F2 O
+UPDATE stellar_detail SET luminosity=109600.0 WHERE id=34; -- F3 O
+UPDATE stellar_detail SET luminosity=105700.0 WHERE id=35; -- F4 O
+UPDATE stellar_detail SET luminosity=102000.0 WHERE id=36; -- F5 O
+UPDATE stellar_detail SET luminosity=107700.0 WHERE id=37; -- F6 O
+UPDATE stellar_detail SET luminosity=113800.0 WHERE id=38; -- F7 O
+UPDATE stellar_detail SET luminosity=120100.0 WHERE id=39; -- F8 O
+UPDATE stellar_detail SET luminosity=126900.0 WHERE id=40; -- F9 O
+UPDATE stellar_detail SET luminosity=134000.0 WHERE id=41; -- G0 O
+UPDATE stellar_detail SET luminosity=141800.0 WHERE id=42; -- G1 O
+UPDATE stellar_detail SET luminosity=150100.0 WHERE id=43; -- G2 O
+UPDATE stellar_detail SET luminosity=158900.0 WHERE id=44; -- G3 O
+UPDATE stellar_detail SET luminosity=168200.0 WHERE id=45; -- G4 O
+UPDATE stellar_detail SET luminosity=178000.0 WHERE id=46; -- G5 O
+UPDATE stellar_detail SET luminosity=181100.0 WHERE id=47; -- G6 O
+UPDATE stellar_detail SET luminosity=184200.0 WHERE id=48; -- G7 O
+UPDATE stellar_detail SET luminosity=187400.0 WHERE id=49; -- G8 O
+UPDATE stellar_detail SET luminosity=190700.0 WHERE id=50; -- G9 O
+UPDATE stellar_detail SET luminosity=194000.0 WHERE id=51; -- K0 O
+UPDATE stellar_detail SET luminosity=197800.0 WHERE id=52; -- K1 O
+UPDATE stellar_detail SET luminosity=201800.0 WHERE id=53; -- K2 O
+UPDATE stellar_detail SET luminosity=205800.0 WHERE id=54; -- K3 O
+UPDATE stellar_detail SET luminosity=209800.0 WHERE id=55; -- K4 O
+UPDATE stellar_detail SET luminosity=214000.0 WHERE id=56; -- K5 O
+UPDATE stellar_detail SET luminosity=217900.0 WHERE id=57; -- K6 O
+UPDATE stellar_detail SET luminosity=221800.0 WHERE id=58; -- K7 O
+UPDATE stellar_detail SET luminosity=225800.0 WHERE id=59; -- K8 O
+UPDATE stellar_detail SET luminosity=229900.0 WHERE id=60; -- K9 O
+UPDATE stellar_detail SET luminosity=234000.0 WHERE id=61; -- M0 O
+UPDATE stellar_detail SET luminosity=238600.0 WHERE id=62; -- M1 O
+UPDATE stellar_detail SET luminosity=243300.0 WHERE id=63; -- M2 O
+UPDATE stellar_detail SET luminosity=248100.0 WHERE id=64; -- M3 O
+UPDATE stellar_detail SET luminosity=253000.0 WHERE id=65; -- M4 O
+UPDATE stellar_detail SET luminosity=258000.0 WHERE id=66; -- M5 O
+UPDATE stellar_detail SET luminosity=263800.0 WHERE id=67; -- M6 O
+UPDATE stellar_detail SET luminosity=269700.0 WHERE id=68; -- M7 O
+UPDATE stellar_detail SET luminosity=275800.0 WHERE id=69; -- M8 O
+UPDATE stellar_detail SET luminosity=282000.0 WHERE id=70; -- M9 O
+UPDATE stellar_detail SET luminosity=11200000.0 WHERE id=71; -- O0 Ia
+UPDATE stellar_detail SET luminosity=8301000.0 WHERE id=72; -- O1 Ia
+UPDATE stellar_detail SET luminosity=6152000.0 WHERE id=73; -- O2 Ia
+UPDATE stellar_detail SET luminosity=4559000.0 WHERE id=74; -- O3 Ia
+UPDATE stellar_detail SET luminosity=3379000.0 WHERE id=75; -- O4 Ia
+UPDATE stellar_detail SET luminosity=2504000.0 WHERE id=76; -- O5 Ia
+UPDATE stellar_detail SET luminosity=1856000.0 WHERE id=77; -- O6 Ia
+UPDATE stellar_detail SET luminosity=1376000.0 WHERE id=78; -- O7 Ia
+UPDATE stellar_detail SET luminosity=1020000.0 WHERE id=79; -- O8 Ia
+UPDATE stellar_detail SET luminosity=755600.0 WHERE id=80; -- O9 Ia
+UPDATE stellar_detail SET luminosity=560000.0 WHERE id=81; -- B0 Ia
+UPDATE stellar_detail SET luminosity=457600.0 WHERE id=82; -- B1 Ia
+UPDATE stellar_detail SET luminosity=373900.0 WHERE id=83; -- B2 Ia
+UPDATE stellar_detail SET luminosity=305500.0 WHERE id=84; -- B3 Ia
+UPDATE stellar_detail SET luminosity=249700.0 WHERE id=85; -- B4 Ia
+UPDATE stellar_detail SET luminosity=204000.0 WHERE id=86; -- B5 Ia
+UPDATE stellar_detail SET luminosity=179300.0 WHERE id=87; -- B6 Ia
+UPDATE stellar_detail SET luminosity=157600.0 WHERE id=88; -- B7 Ia
+UPDATE stellar_detail SET luminosity=138500.0 WHERE id=89; -- B8 Ia
+UPDATE stellar_detail SET luminosity=121700.0 WHERE id=90; -- B9 Ia
+UPDATE stellar_detail SET luminosity=107000.0 WHERE id=91; -- A0 Ia
+UPDATE stellar_detail SET luminosity=101200.0 WHERE id=92; -- A1 Ia
+UPDATE stellar_detail SET luminosity=95720.0 WHERE id=93; -- A2 Ia
+UPDATE stellar_detail SET luminosity=90540.0 WHERE id=94; -- A3 Ia
+UPDATE stellar_detail SET luminosity=85640.0 WHERE id=95; -- A4 Ia
+UPDATE stellar_detail SET luminosity=81000.0 WHERE id=96; -- A5 Ia
+UPDATE stellar_detail SET luminosity=76530.0 WHERE id=97; -- A6 Ia
+UPDATE stellar_detail SET luminosity=72310.0 WHERE id=98; -- A7 Ia
+UPDATE stellar_detail SET luminosity=68330.0 WHERE id=99; -- A8 Ia
+UPDATE stellar_detail SET luminosity=64560.0 WHERE id=100; -- A9 Ia
+UPDATE stellar_detail SET luminosity=61000.0 WHERE id=101; -- F0 Ia
+UPDATE stellar_detail SET luminosity=58850.0 WHERE id=102; -- F1 Ia
+UPDATE stellar_detail SET luminosity=56780.0 WHERE id=103; -- F2 Ia
+UPDATE stellar_detail SET luminosity=54790.0 WHERE id=104; -- F3 Ia
+UPDATE stellar_detail SET luminosity=52860.0 WHERE id=105; -- F4 Ia
+UPDATE stellar_detail SET luminosity=51000.0 WHERE id=106; -- F5 Ia
+UPDATE stellar_detail SET luminosity=53860.0 WHERE id=107; -- F6 Ia
+UPDATE stellar_detail SET luminosity=56880.0 WHERE id=108; -- F7 Ia
+UPDATE stellar_detail SET luminosity=60070.0 WHERE id=109; -- F8 Ia
+UPDATE stellar_detail SET luminosity=63440.0 WHERE id=110; -- F9 Ia
+UPDATE stellar_detail SET luminosity=67000.0 WHERE id=111; -- G0 Ia
+UPDATE stellar_detail SET luminosity=70910.0 WHERE id=112; -- G1 Ia
+UPDATE stellar_detail SET luminosity=75060.0 WHERE id=113; -- G2 Ia
+UPDATE stellar_detail SET luminosity=79440.0 WHERE id=114; -- G3 Ia
+UPDATE stellar_detail SET luminosity=84090.0 WHERE id=115; -- G4 Ia
+UPDATE stellar_detail SET luminosity=89000.0 WHERE id=116; -- G5 Ia
+UPDATE stellar_detail SET luminosity=90550.0 WHERE id=117; -- G6 Ia
+UPDATE stellar_detail SET luminosity=92120.0 WHERE id=118; -- G7 Ia
+UPDATE stellar_detail SET luminosity=93720.0 WHERE id=119; -- G8 Ia
+UPDATE stellar_detail SET luminosity=95340.0 WHERE id=120; -- G9 Ia
+UPDATE stellar_detail SET luminosity=97000.0 WHERE id=121; -- K0 Ia
+UPDATE stellar_detail SET luminosity=98920.0 WHERE id=122; -- K1 Ia
+UPDATE stellar_detail SET luminosity=100900.0 WHERE id=123; -- K2 Ia
+UPDATE stellar_detail SET luminosity=102900.0 WHERE id=124; -- K3 Ia
+UPDATE stellar_detail SET luminosity=104900.0 WHERE id=125; -- K4 Ia
+UPDATE stellar_detail SET luminosity=107000.0 WHERE id=126; -- K5 Ia
+UPDATE stellar_detail SET luminosity=108900.0 WHERE id=127; -- K6 Ia
+UPDATE stellar_detail SET luminosity=110900.0 WHERE id=128; -- K7 Ia
+UPDATE stellar_detail SET luminosity=112900.0 WHERE id=129; -- K8 Ia
+UPDATE stellar_detail SET luminosity=114900.0 WHERE id=130; -- K9 Ia
+UPDATE stellar_detail SET luminosity=117000.0 WHERE id=131; -- M0 Ia
+UPDATE stellar_detail SET luminosity=119300.0 WHERE id=132; -- M1 Ia
+UPDATE stellar_detail SET luminosity=121700.0 WHERE id=133; -- M2 Ia
+UPDATE stellar_detail SET luminosity=124100.0 WHERE id=134; -- M3 Ia
+UPDATE stellar_detail SET luminosity=126500.0 WHERE id=135; -- M4 Ia
+UPDATE stellar_detail SET luminosity=129000.0 WHERE id=136; -- M5 Ia
+UPDATE stellar_detail SET luminosity=131900.0 WHERE id=137; -- M6 Ia
+UPDATE stellar_detail SET luminosity=134900.0 WHERE id=138; -- M7 Ia
+UPDATE stellar_detail SET luminosity=137900.0 WHERE id=139; -- M8 Ia
+UPDATE stellar_detail SET luminosity=141000.0 WHERE id=140; -- M9 Ia
+UPDATE stellar_detail SET luminosity=5400000.0 WHERE id=141; -- O0 Ib
+UPDATE stellar_detail SET luminosity=4002000.0 WHERE id=142; -- O1 Ib
+UPDATE stellar_detail SET luminosity=2966000.0 WHERE id=143; -- O2 Ib
+UPDATE stellar_detail SET luminosity=2198000.0 WHERE id=144; -- O3 Ib
+UPDATE stellar_detail SET luminosity=1629000.0 WHERE id=145; -- O4 Ib
+UPDATE stellar_detail SET luminosity=1207000.0 WHERE id=146; -- O5 Ib
+UPDATE stellar_detail SET luminosity=894900.0 WHERE id=147; -- O6 Ib
+UPDATE stellar_detail SET luminosity=663200.0 WHERE id=148; -- O7 Ib
+UPDATE stellar_detail SET luminosity=491600.0 WHERE id=149; -- O8 Ib
+UPDATE stellar_detail SET luminosity=364300.0 WHERE id=150; -- O9 Ib
+UPDATE stellar_detail SET luminosity=270000.0 WHERE id=151; -- B0 Ib
+UPDATE stellar_detail SET luminosity=190100.0 WHERE id=152; -- B1 Ib
+UPDATE stellar_detail SET luminosity=133800.0 WHERE id=153; -- B2 Ib
+UPDATE stellar_detail SET luminosity=94220.0 WHERE id=154; -- B3 Ib
+UPDATE stellar_detail SET luminosity=66330.0 WHERE id=155; -- B4 Ib
+UPDATE stellar_detail SET luminosity=46700.0 WHERE id=156; -- B5 Ib
+UPDATE stellar_detail SET luminosity=37210.0 WHERE id=157; -- B6 Ib
+UPDATE stellar_detail SET luminosity=29650.0 WHERE id=158; -- B7 Ib
+UPDATE stellar_detail SET luminosity=23630.0 WHERE id=159; -- B8 Ib
+UPDATE stellar_detail SET luminosity=18830.0 WHERE id=160; -- B9 Ib
+UPDATE stellar_detail SET luminosity=15000.0 WHERE id=161; -- A0 Ib
+UPDATE stellar_detail SET luminosity=14270.0 WHERE id=162; -- A1 Ib
+UPDATE stellar_detail SET luminosity=13580.0 WHERE id=163; -- A2 Ib
+UPDATE stellar_detail SET luminosity=12920.0 WHERE id=164; -- A3 Ib
+UPDATE stellar_detail SET luminosity=12300.0 WHERE id=165; -- A4 Ib
+UPDATE stellar_detail SET luminosity=11700.0 WHERE id=166; -- A5 Ib
+UPDATE stellar_detail SET luminosity=10680.0 WHERE id=167; -- A6 Ib
+UPDATE stellar_detail SET luminosity=9741 WHERE id=168; -- A7 Ib
+UPDATE stellar_detail SET luminosity=8888 WHERE id=169; -- A8 Ib
+UPDATE stellar_detail SET luminosity=8110 WHERE id=170; -- A9 Ib
+UPDATE stellar_detail SET luminosity=7400 WHERE id=171; -- F0 Ib
+UPDATE stellar_detail SET luminosity=6869 WHERE id=172; -- F1 Ib
+UPDATE stellar_detail SET luminosity=6376 WHERE id=173; -- F2 Ib
+UPDATE stellar_detail SET luminosity=5919 WHERE id=174; -- F3 Ib
+UPDATE stellar_detail SET luminosity=5494 WHERE id=175; -- F4 Ib
+UPDATE stellar_detail SET luminosity=5100 WHERE id=176; -- F5 Ib
+UPDATE stellar_detail SET luminosity=5286 WHERE id=177; -- F6 Ib
+UPDATE stellar_detail SET luminosity=5479 WHERE id=178; -- F7 Ib
+UPDATE stellar_detail SET luminosity=5678 WHERE id=179; -- F8 Ib
+UPDATE stellar_detail SET luminosity=5885 WHERE id=180; -- F9 Ib
+UPDATE stellar_detail SET luminosity=6100 WHERE id=181; -- G0 Ib
+UPDATE stellar_detail SET luminosity=6456 WHERE id=182; -- G1 Ib
+UPDATE stellar_detail SET luminosity=6833 WHERE id=183; -- G2 Ib
+UPDATE stellar_detail SET luminosity=7231 WHERE id=184; -- G3 Ib
+UPDATE stellar_detail SET luminosity=7653 WHERE id=185; -- G4 Ib
+UPDATE stellar_detail SET luminosity=8100 WHERE id=186; -- G5 Ib
+UPDATE stellar_detail SET luminosity=8718 WHERE id=187; -- G6 Ib
+UPDATE stellar_detail SET luminosity=9384 WHERE id=188; -- G7 Ib
+UPDATE stellar_detail SET luminosity=10100.0 WHERE id=189; -- G8 Ib
+UPDATE stellar_detail SET luminosity=10870.0 WHERE id=190; -- G9 Ib
+UPDATE stellar_detail SET luminosity=11700.0 WHERE id=191; -- K0 Ib
+UPDATE stellar_detail SET luminosity=13080.0 WHERE id=192; -- K1 Ib
+UPDATE stellar_detail SET luminosity=14610.0 WHERE id=193; -- K2 Ib
+UPDATE stellar_detail SET luminosity=16330.0 WHERE id=194; -- K3 Ib
+UPDATE stellar_detail SET luminosity=18250.0 WHERE id=195; -- K4 Ib
+UPDATE stellar_detail SET luminosity=20400.0 WHERE id=196; -- K5 Ib
+UPDATE stellar_detail SET luminosity=24000.0 WHERE id=197; -- K6 Ib
+UPDATE stellar_detail SET luminosity=28240.0 WHERE id=198; -- K7 Ib
+UPDATE stellar_detail SET luminosity=33230.0 WHERE id=199; -- K8 Ib
+UPDATE stellar_detail SET luminosity=39100.0 WHERE id=200; -- K9 Ib
+UPDATE stellar_detail SET luminosity=46000.0 WHERE id=201; -- M0 Ib
+UPDATE stellar_detail SET luminosity=52490.0 WHERE id=202; -- M1 Ib
+UPDATE stellar_detail SET luminosity=59900.0 WHERE id=203; -- M2 Ib
+UPDATE stellar_detail SET luminosity=68350.0 WHERE id=204; -- M3 Ib
+UPDATE stellar_detail SET luminosity=77990.0 WHERE id=205; -- M4 Ib
+UPDATE stellar_detail SET luminosity=89000.0 WHERE id=206; -- M5 Ib
+UPDATE stellar_detail SET luminosity=95300.0 WHERE id=207; -- M6 Ib
+UPDATE stellar_detail SET luminosity=102000.0 WHERE id=208; -- M7 Ib
+UPDATE stellar_detail SET luminosity=109300.0 WHERE id=209; -- M8 Ib
+UPDATE stellar_detail SET luminosity=117000.0 WHERE id=210; -- M9 Ib
+UPDATE stellar_detail SET luminosity=3400000.0 WHERE id=211; -- O0 II
+UPDATE stellar_detail SET luminosity=2520000.0 WHERE id=212; -- O1 II
+UPDATE stellar_detail SET luminosity=1868000.0 WHERE id=213; -- O2 II
+UPDATE stellar_detail SET luminosity=1384000.0 WHERE id=214; -- O3 II
+UPDATE stellar_detail SET luminosity=1026000.0 WHERE id=215; -- O4 II
+UPDATE stellar_detail SET luminosity=760300.0 WHERE id=216; -- O5 II
+UPDATE stellar_detail SET luminosity=563500.0 WHERE id=217; -- O6 II
+UPDATE stellar_detail SET luminosity=417600.0 WHERE id=218; -- O7 II
+UPDATE stellar_detail SET luminosity=309500.0 WHERE id=219; -- O8 II
+UPDATE stellar_detail SET luminosity=229400.0 WHERE id=220; -- O9 II
+UPDATE stellar_detail SET luminosity=170000.0 WHERE id=221; -- B0 II
+UPDATE stellar_detail SET luminosity=109200.0 WHERE id=222; -- B1 II
+UPDATE stellar_detail SET luminosity=70160.0 WHERE id=223; -- B2 II
+UPDATE stellar_detail SET luminosity=45070.0 WHERE id=224; -- B3 II
+UPDATE stellar_detail SET luminosity=28950.0 WHERE id=225; -- B4 II
+UPDATE stellar_detail SET luminosity=18600.0 WHERE id=226; -- B5 II
+UPDATE stellar_detail SET luminosity=12140.0 WHERE id=227; -- B6 II
+UPDATE stellar_detail SET luminosity=7919 WHERE id=228; -- B7 II
+UPDATE stellar_detail SET luminosity=5167 WHERE id=229; -- B8 II
+UPDATE stellar_detail SET luminosity=3372 WHERE id=230; -- B9 II
+UPDATE stellar_detail SET luminosity=2200 WHERE id=231; -- A0 II
+UPDATE stellar_detail SET luminosity=1819 WHERE id=232; -- A1 II
+UPDATE stellar_detail SET luminosity=1504 WHERE id=233; -- A2 II
+UPDATE stellar_detail SET luminosity=1243 WHERE id=234; -- A3 II
+UPDATE stellar_detail SET luminosity=1028 WHERE id=235; -- A4 II
+UPDATE stellar_detail SET luminosity=850 WHERE id=236; -- A5 II
+UPDATE stellar_detail SET luminosity=792.8 WHERE id=237; -- A6 II
+UPDATE stellar_detail SET luminosity=739.5 WHERE id=238; -- A7 II
+UPDATE stellar_detail SET luminosity=689.7 WHERE id=239; -- A8 II
+UPDATE stellar_detail SET luminosity=643.3 WHERE id=240; -- A9 II
+UPDATE stellar_detail SET luminosity=600 WHERE id=241; -- F0 II
+UPDATE stellar_detail SET luminosity=580.8 WHERE id=242; -- F1 II
+UPDATE stellar_detail SET luminosity=562.2 WHERE id=243; -- F2 II
+UPDATE stellar_detail SET luminosity=544.3 WHERE id=244; -- F3 II
+UPDATE stellar_detail SET luminosity=526.8 WHERE id=245; -- F4 II
+UPDATE stellar_detail SET luminosity=510 WHERE id=246; -- F5 II
+UPDATE stellar_detail SET luminosity=519.6 WHERE id=247; -- F6 II
+UPDATE stellar_detail SET luminosity=529.4 WHERE id=248; -- F7 II
+UPDATE stellar_detail SET luminosity=539.4 WHERE id=249; -- F8 II
+UPDATE stellar_detail SET luminosity=549.6 WHERE id=250; -- F9 II
+UPDATE stellar_detail SET luminosity=560 WHERE id=251; -- G0 II
+UPDATE stellar_detail SET luminosity=592.1 WHERE id=252; -- G1 II
+UPDATE stellar_detail SET luminosity=626 WHERE id=253; -- G2 II
+UPDATE stellar_detail SET luminosity=661.9 WHERE id=254; -- G3 II
+UPDATE stellar_detail SET luminosity=699.9 WHERE id=255; -- G4 II
+UPDATE stellar_detail SET luminosity=740 WHERE id=256; -- G5 II
+UPDATE stellar_detail SET luminosity=767.8 WHERE id=257; -- G6 II
+UPDATE stellar_detail SET luminosity=796.7 WHERE id=258; -- G7 II
+UPDATE stellar_detail SET luminosity=826.7 WHERE id=259; -- G8 II
+UPDATE stellar_detail SET luminosity=857.7 WHERE id=260; -- G9 II
+UPDATE stellar_detail SET luminosity=890 WHERE id=261; -- K0 II
+UPDATE stellar_detail SET luminosity=1090 WHERE id=262; -- K1 II
+UPDATE stellar_detail SET luminosity=1334 WHERE id=263; -- K2 II
+UPDATE stellar_detail SET luminosity=1634 WHERE id=264; -- K3 II
+UPDATE stellar_detail SET luminosity=2001 WHERE id=265; -- K4 II
+UPDATE stellar_detail SET luminosity=2450 WHERE id=266; -- K5 II
+UPDATE stellar_detail SET luminosity=2779 WHERE id=267; -- K6 II
+UPDATE stellar_detail SET luminosity=3152 WHERE id=268; -- K7 II
+UPDATE stellar_detail SET luminosity=3575 WHERE id=269; -- K8 II
+UPDATE stellar_detail SET luminosity=4055 WHERE id=270; -- K9 II
+UPDATE stellar_detail SET luminosity=4600 WHERE id=271; -- M0 II
+UPDATE stellar_detail SET luminosity=5819 WHERE id=272; -- M1 II
+UPDATE stellar_detail SET luminosity=7361 WHERE id=273; -- M2 II
+UPDATE stellar_detail SET luminosity=9311 WHERE id=274; -- M3 II
+UPDATE stellar_detail SET luminosity=11780.0 WHERE id=275; -- M4 II
+UPDATE stellar_detail SET luminosity=14900.0 WHERE id=276; -- M5 II
+UPDATE stellar_detail SET luminosity=15210.0 WHERE id=277; -- M6 II
+UPDATE stellar_detail SET luminosity=15540.0 WHERE id=278; -- M7 II
+UPDATE stellar_detail SET luminosity=15860.0 WHERE id=279; -- M8 II
+UPDATE stellar_detail SET luminosity=16200.0 WHERE id=280; -- M9 II
+UPDATE stellar_detail SET luminosity=2140000.0 WHERE id=281; -- O0 III
+UPDATE stellar_detail SET luminosity=1586000.0 WHERE id=282; -- O1 III
+UPDATE stellar_detail SET luminosity=1175000.0 WHERE id=283; -- O2 III
+UPDATE stellar_detail SET luminosity=871200.0 WHERE id=284; -- O3 III
+UPDATE stellar_detail SET luminosity=645700.0 WHERE id=285; -- O4 III
+UPDATE stellar_detail SET luminosity=478500.0 WHERE id=286; -- O5 III
+UPDATE stellar_detail SET luminosity=354600.0 WHERE id=287; -- O6 III
+UPDATE stellar_detail SET luminosity=262800.0 WHERE id=288; -- O7 III
+UPDATE stellar_detail SET luminosity=194800.0 WHERE id=289; -- O8 III
+UPDATE stellar_detail SET luminosity=144400.0 WHERE id=290; -- O9 III
+UPDATE stellar_detail SET luminosity=107000.0 WHERE id=291; -- B0 III
+UPDATE stellar_detail SET luminosity=61480.0 WHERE id=292; -- B1 III
+UPDATE stellar_detail SET luminosity=35320.0 WHERE id=293; -- B2 III
+UPDATE stellar_detail SET luminosity=20300.0 WHERE id=294; -- B3 III
+UPDATE stellar_detail SET luminosity=11660.0 WHERE id=295; -- B4 III
+UPDATE stellar_detail SET luminosity=6700 WHERE id=296; -- B5 III
+UPDATE stellar_detail SET luminosity=3551 WHERE id=297; -- B6 III
+UPDATE stellar_detail SET luminosity=1882 WHERE id=298; -- B7 III
+UPDATE stellar_detail SET luminosity=997.1 WHERE id=299; -- B8 III
+UPDATE stellar_detail SET luminosity=528.4 WHERE id=300; -- B9 III
+UPDATE stellar_detail SET luminosity=280 WHERE id=301; -- A0 III
+UPDATE stellar_detail SET luminosity=223.1 WHERE id=302; -- A1 III
+UPDATE stellar_detail SET luminosity=177.8 WHERE id=303; -- A2 III
+UPDATE stellar_detail SET luminosity=141.7 WHERE id=304; -- A3 III
+UPDATE stellar_detail SET luminosity=112.9 WHERE id=305; -- A4 III
+UPDATE stellar_detail SET luminosity=90 WHERE id=306; -- A5 III
+UPDATE stellar_detail SET luminosity=80.96 WHERE id=307; -- A6 III
+UPDATE stellar_detail SET luminosity=72.82 WHERE id=308; -- A7 III
+UPDATE stellar_detail SET luminosity=65.5 WHERE id=309; -- A8 III
+UPDATE stellar_detail SET luminosity=58.92 WHERE id=310; -- A9 III
+UPDATE stellar_detail SET luminosity=53 WHERE id=311; -- F0 III
+UPDATE stellar_detail SET luminosity=50.83 WHERE id=312; -- F1 III
+UPDATE stellar_detail SET luminosity=48.75 WHERE id=313; -- F2 III
+UPDATE stellar_detail SET luminosity=46.75 WHERE id=314; -- F3 III
+UPDATE stellar_detail SET luminosity=44.84 WHERE id=315; -- F4 III
+UPDATE stellar_detail SET luminosity=43 WHERE id=316; -- F5 III
+UPDATE stellar_detail SET luminosity=44.32 WHERE id=317; -- F6 III
+UPDATE stellar_detail SET luminosity=45.67 WHERE id=318; -- F7 III
+UPDATE stellar_detail SET luminosity=47.07 WHERE id=319; -- F8 III
+UPDATE stellar_detail SET luminosity=48.51 WHERE id=320; -- F9 III
+UPDATE stellar_detail SET luminosity=50 WHERE id=321; -- G0 III
+UPDATE stellar_detail SET luminosity=54.22 WHERE id=322; -- G1 III
+UPDATE stellar_detail SET luminosity=58.8 WHERE id=323; -- G2 III
+UPDATE stellar_detail SET luminosity=63.77 WHERE id=324; -- G3 III
+UPDATE stellar_detail SET luminosity=69.16 WHERE id=325; -- G4 III
+UPDATE stellar_detail SET luminosity=75 WHERE id=326; -- G5 III
+UPDATE stellar_detail SET luminosity=78.63 WHERE id=327; -- G6 III
+UPDATE stellar_detail SET luminosity=82.44 WHERE id=328; -- G7 III
+UPDATE stellar_detail SET luminosity=86.43 WHERE id=329; -- G8 III
+UPDATE stellar_detail SET luminosity=90.61 WHERE id=330; -- G9 III
+UPDATE stellar_detail SET luminosity=95 WHERE id=331; -- K0 III
+UPDATE stellar_detail SET luminosity=121.1 WHERE id=332; -- K1 III
+UPDATE stellar_detail SET luminosity=154.4 WHERE id=333; -- K2 III
+UPDATE stellar_detail SET luminosity=196.9 WHERE id=334; -- K3 III
+UPDATE stellar_detail SET luminosity=251 WHERE id=335; -- K4 III
+UPDATE stellar_detail SET luminosity=320 WHERE id=336; -- K5 III
+UPDATE stellar_detail SET luminosity=345.6 WHERE id=337; -- K6 III
+UPDATE stellar_detail SET luminosity=373.2 WHERE id=338; -- K7 III
+UPDATE stellar_detail SET luminosity=403 WHERE id=339; -- K8 III
+UPDATE stellar_detail SET luminosity=435.2 WHERE id=340; -- K9 III
+UPDATE stellar_detail SET luminosity=470 WHERE id=341; -- M0 III
+UPDATE stellar_detail SET luminosity=656.5 WHERE id=342; -- M1 III
+UPDATE stellar_detail SET luminosity=917.1 WHERE id=343; -- M2 III
+UPDATE stellar_detail SET luminosity=1281 WHERE id=344; -- M3 III
+UPDATE stellar_detail SET luminosity=1790 WHERE id=345; -- M4 III
+UPDATE stellar_detail SET luminosity=2500 WHERE id=346; -- M5 III
+UPDATE stellar_detail SET luminosity=2812 WHERE id=347; -- M6 III
+UPDATE stellar_detail SET luminosity=3162 WHERE id=348; -- M7 III
+UPDATE stellar_detail SET luminosity=3557 WHERE id=349; -- M8 III
+UPDATE stellar_detail SET luminosity=4000 WHERE id=350; -- M9 III
+UPDATE stellar_detail SET luminosity=1620000.0 WHERE id=351; -- O0 IV
+UPDATE stellar_detail SET luminosity=1201000.0 WHERE id=352; -- O1 IV
+UPDATE stellar_detail SET luminosity=889800.0 WHERE id=353; -- O2 IV
+UPDATE stellar_detail SET luminosity=659500.0 WHERE id=354; -- O3 IV
+UPDATE stellar_detail SET luminosity=488800.0 WHERE id=355; -- O4 IV
+UPDATE stellar_detail SET luminosity=362200.0 WHERE id=356; -- O5 IV
+UPDATE stellar_detail SET luminosity=268500.0 WHERE id=357; -- O6 IV
+UPDATE stellar_detail SET luminosity=199000.0 WHERE id=358; -- O7 IV
+UPDATE stellar_detail SET luminosity=147500.0 WHERE id=359; -- O8 IV
+UPDATE stellar_detail SET luminosity=109300.0 WHERE id=360; -- O9 IV
+UPDATE stellar_detail SET luminosity=81000.0 WHERE id=361; -- B0 IV
+UPDATE stellar_detail SET luminosity=38640.0 WHERE id=362; -- B1 IV
+UPDATE stellar_detail SET luminosity=18430.0 WHERE id=363; -- B2 IV
+UPDATE stellar_detail SET luminosity=8790 WHERE id=364; -- B3 IV
+UPDATE stellar_detail SET luminosity=4193 WHERE id=365; -- B4 IV
+UPDATE stellar_detail SET luminosity=2000 WHERE id=366; -- B5 IV
+UPDATE stellar_detail SET luminosity=1201 WHERE id=367; -- B6 IV
+UPDATE stellar_detail SET luminosity=720.9 WHERE id=368; -- B7 IV
+UPDATE stellar_detail SET luminosity=432.8 WHERE id=369; -- B8 IV
+UPDATE stellar_detail SET luminosity=259.8 WHERE id=370; -- B9 IV
+UPDATE stellar_detail SET luminosity=156 WHERE id=371; -- A0 IV
+UPDATE stellar_detail SET luminosity=117 WHERE id=372; -- A1 IV
+UPDATE stellar_detail SET luminosity=87.73 WHERE id=373; -- A2 IV
+UPDATE stellar_detail SET luminosity=65.79 WHERE id=374; -- A3 IV
+UPDATE stellar_detail SET luminosity=49.34 WHERE id=375; -- A4 IV
+UPDATE stellar_detail SET luminosity=37 WHERE id=376; -- A5 IV
+UPDATE stellar_detail SET luminosity=32.38 WHERE id=377; -- A6 IV
+UPDATE stellar_detail SET luminosity=28.34 WHERE id=378; -- A7 IV
+UPDATE stellar_detail SET luminosity=24.8 WHERE id=379; -- A8 IV
+UPDATE stellar_detail SET luminosity=21.71 WHERE id=380; -- A9 IV
+UPDATE stellar_detail SET luminosity=19 WHERE id=381; -- F0 IV
+UPDATE stellar_detail SET luminosity=16.02 WHERE id=382; -- F1 IV
+UPDATE stellar_detail SET luminosity=13.51 WHERE id=383; -- F2 IV
+UPDATE stellar_detail SET luminosity=11.39 WHERE id=384; -- F3 IV
+UPDATE stellar_detail SET luminosity=9.606 WHERE id=385; -- F4 IV
+UPDATE stellar_detail SET luminosity=8.1 WHERE id=386; -- F5 IV
+UPDATE stellar_detail SET luminosity=7.325 WHERE id=387; -- F6 IV
+UPDATE stellar_detail SET luminosity=6.625 WHERE id=388; -- F7 IV
+UPDATE stellar_detail SET luminosity=5.991 WHERE id=389; -- F8 IV
+UPDATE stellar_detail SET luminosity=5.418 WHERE id=390; -- F9 IV
+UPDATE stellar_detail SET luminosity=4.9 WHERE id=391; -- G0 IV
+UPDATE stellar_detail SET luminosity=4.9 WHERE id=392; -- G1 IV
+UPDATE stellar_detail SET luminosity=4.9 WHERE id=393; -- G2 IV
+UPDATE stellar_detail SET luminosity=4.9 WHERE id=394; -- G3 IV
+UPDATE stellar_detail SET luminosity=4.9 WHERE id=395; -- G4 IV
+UPDATE stellar_detail SET luminosity=4.9 WHERE id=396; -- G5 IV
+UPDATE stellar_detail SET luminosity=5.216 WHERE id=397; -- G6 IV
+UPDATE stellar_detail SET luminosity=5.553 WHERE id=398; -- G7 IV
+UPDATE stellar_detail SET luminosity=5.912 WHERE id=399; -- G8 IV
+UPDATE stellar_detail SET luminosity=6.294 WHERE id=400; -- G9 IV
+UPDATE stellar_detail SET luminosity=6.7 WHERE id=401; -- K0 IV
+UPDATE stellar_detail SET luminosity=6.942 WHERE id=402; -- K1 IV
+UPDATE stellar_detail SET luminosity=7.193 WHERE id=403; -- K2 IV
+UPDATE stellar_detail SET luminosity=7.452 WHERE id=404; -- K3 IV
+UPDATE stellar_detail SET luminosity=7.721 WHERE id=405; -- K4 IV
+UPDATE stellar_detail SET luminosity=1120000.0 WHERE id=421; -- O0 V
+UPDATE stellar_detail SET luminosity=830100.0 WHERE id=422; -- O1 V
+UPDATE stellar_detail SET luminosity=615200.0 WHERE id=423; -- O2 V
+UPDATE stellar_detail SET luminosity=455900.0 WHERE id=424; -- O3 V
+UPDATE stellar_detail SET luminosity=337900.0 WHERE id=425; -- O4 V
+UPDATE stellar_detail SET luminosity=250400.0 WHERE id=426; -- O5 V
+UPDATE stellar_detail SET luminosity=185600.0 WHERE id=427; -- O6 V
+UPDATE stellar_detail SET luminosity=137600.0 WHERE id=428; -- O7 V
+UPDATE stellar_detail SET luminosity=102000.0 WHERE id=429; -- O8 V
+UPDATE stellar_detail SET luminosity=75560.0 WHERE id=430; -- O9 V
+UPDATE stellar_detail SET luminosity=56000.0 WHERE id=431; -- B0 V
+UPDATE stellar_detail SET luminosity=26780.0 WHERE id=432; -- B1 V
+UPDATE stellar_detail SET luminosity=12800.0 WHERE id=433; -- B2 V
+UPDATE stellar_detail SET luminosity=6123 WHERE id=434; -- B3 V
+UPDATE stellar_detail SET luminosity=2928 WHERE id=435; -- B4 V
+UPDATE stellar_detail SET luminosity=1400 WHERE id=436; -- B5 V
+UPDATE stellar_detail SET luminosity=808.6 WHERE id=437; -- B6 V
+UPDATE stellar_detail SET luminosity=467.1 WHERE id=438; -- B7 V
+UPDATE stellar_detail SET luminosity=269.8 WHERE id=439; -- B8 V
+UPDATE stellar_detail SET luminosity=155.8 WHERE id=440; -- B9 V
+UPDATE stellar_detail SET luminosity=90 WHERE id=441; -- A0 V
+UPDATE stellar_detail SET luminosity=63.71 WHERE id=442; -- A1 V
+UPDATE stellar_detail SET luminosity=45.1 WHERE id=443; -- A2 V
+UPDATE stellar_detail SET luminosity=31.93 WHERE id=444; -- A3 V
+UPDATE stellar_detail SET luminosity=22.6 WHERE id=445; -- A4 V
+UPDATE stellar_detail SET luminosity=16 WHERE id=446; -- A5 V
+UPDATE stellar_detail SET luminosity=13.96 WHERE id=447; -- A6 V
+UPDATE stellar_detail SET luminosity=12.19 WHERE id=448; -- A7 V
+UPDATE stellar_detail SET luminosity=10.64 WHERE id=449; -- A8 V
+UPDATE stellar_detail SET luminosity=9.281 WHERE id=450; -- A9 V
+UPDATE stellar_detail SET luminosity=8.1 WHERE id=451; -- F0 V
+UPDATE stellar_detail SET luminosity=6.849 WHERE id=452; -- F1 V
+UPDATE stellar_detail SET luminosity=5.791 WHERE id=453; -- F2 V
+UPDATE stellar_detail SET luminosity=4.896 WHERE id=454; -- F3 V
+UPDATE stellar_detail SET luminosity=4.14 WHERE id=455; -- F4 V
+UPDATE stellar_detail SET luminosity=3.5 WHERE id=456; -- F5 V
+UPDATE stellar_detail SET luminosity=2.83 WHERE id=457; -- F6 V
+UPDATE stellar_detail SET luminosity=2.289 WHERE id=458; -- F7 V
+UPDATE stellar_detail SET luminosity=1.851 WHERE id=459; -- F8 V
+UPDATE stellar_detail SET luminosity=1.496 WHERE id=460; -- F9 V
+UPDATE stellar_detail SET luminosity=1.21 WHERE id=461; -- G0 V
+UPDATE stellar_detail SET luminosity=1.075 WHERE id=462; -- G1 V
+UPDATE stellar_detail SET luminosity=0.9552 WHERE id=463; -- G2 V
+UPDATE stellar_detail SET luminosity=0.8487 WHERE id=464; -- G3 V
+UPDATE stellar_detail SET luminosity=0.7541 WHERE id=465; -- G4 V
+UPDATE stellar_detail SET luminosity=0.67 WHERE id=466; -- G5 V
+UPDATE stellar_detail SET luminosity=0.6103 WHERE id=467; -- G6 V
+UPDATE stellar_detail SET luminosity=0.5558 WHERE id=468; -- G7 V
+UPDATE stellar_detail SET luminosity=0.5063 WHERE id=469; -- G8 V
+UPDATE stellar_detail SET luminosity=0.4611 WHERE id=470; -- G9 V
+UPDATE stellar_detail SET luminosity=0.42 WHERE id=471; -- K0 V
+UPDATE stellar_detail SET luminosity=0.3015 WHERE id=472; -- K1 V
+UPDATE stellar_detail SET luminosity=0.2164 WHERE id=473; -- K2 V
+UPDATE stellar_detail SET luminosity=0.1553 WHERE id=474; -- K3 V
+UPDATE stellar_detail SET luminosity=0.1115 WHERE id=475; -- K4 V
+UPDATE stellar_detail SET luminosity=0.08 WHERE id=476; -- K5 V
+UPDATE stellar_detail SET luminosity=0.06964 WHERE id=477; -- K6 V
+UPDATE stellar_detail SET luminosity=0.06063 WHERE id=478; -- K7 V
+UPDATE stellar_detail SET luminosity=0.05278 WHERE id=479; -- K8 V
+UPDATE stellar_detail SET luminosity=0.04595 WHERE id=480; -- K9 V
+UPDATE stellar_detail SET luminosity=0.04 WHERE id=481; -- M0 V
+UPDATE stellar_detail SET luminosity=0.02823 WHERE id=482; -- M1 V
+UPDATE stellar_detail SET luminosity=0.01992 WHERE id=483; -- M2 V
+UPDATE stellar_detail SET luminosity=0.01406 WHERE id=484; -- M3 V
+UPDATE stellar_detail SET luminosity=0.00992 WHERE id=485; -- M4 V
+UPDATE stellar_detail SET luminosity=0.007 WHERE id=486; -- M5 V
+UPDATE stellar_detail SET luminosity=0.004304 WHERE id=487; -- M6 V
+UPDATE stellar_detail SET luminosity=0.002646 WHERE id=488; -- M7 V
+UPDATE stellar_detail SET luminosity=0.001627 WHERE id=489; -- M8 V
+UPDATE stellar_detail SET luminosity=0.001 WHERE id=490; -- M9 V
+UPDATE stellar_detail SET luminosity=0.035 WHERE id=526; -- F5 VI
+UPDATE stellar_detail SET luminosity=0.05482 WHERE id=527; -- F6 VI
+UPDATE stellar_detail SET luminosity=0.08587 WHERE id=528; -- F7 VI
+UPDATE stellar_detail SET luminosity=0.1345 WHERE id=529; -- F8 VI
+UPDATE stellar_detail SET luminosity=0.2107 WHERE id=530; -- F9 VI
+UPDATE stellar_detail SET luminosity=0.33 WHERE id=531; -- G0 VI
+UPDATE stellar_detail SET luminosity=0.2599 WHERE id=532; -- G1 VI
+UPDATE stellar_detail SET luminosity=0.2047 WHERE id=533; -- G2 VI
+UPDATE stellar_detail SET luminosity=0.1612 WHERE id=534; -- G3 VI
+UPDATE stellar_detail SET luminosity=0.127 WHERE id=535; -- G4 VI
+UPDATE stellar_detail SET luminosity=0.1 WHERE id=536; -- G5 VI
+UPDATE stellar_detail SET luminosity=0.09466 WHERE id=537; -- G6 VI
+UPDATE stellar_detail SET luminosity=0.0896 WHERE id=538; -- G7 VI
+UPDATE stellar_detail SET luminosity=0.08482 WHERE id=539; -- G8 VI
+UPDATE stellar_detail SET luminosity=0.08029 WHERE id=540; -- G9 VI
+UPDATE stellar_detail SET luminosity=0.076 WHERE id=541; -- K0 VI
+UPDATE stellar_detail SET luminosity=0.05418 WHERE id=542; -- K1 VI
+UPDATE stellar_detail SET luminosity=0.03863 WHERE id=543; -- K2 VI
+UPDATE stellar_detail SET luminosity=0.02754 WHERE id=544; -- K3 VI
+UPDATE stellar_detail SET luminosity=0.01964 WHERE id=545; -- K4 VI
+UPDATE stellar_detail SET luminosity=0.014 WHERE id=546; -- K5 VI
+UPDATE stellar_detail SET luminosity=0.01229 WHERE id=547; -- K6 VI
+UPDATE stellar_detail SET luminosity=0.01079 WHERE id=548; -- K7 VI
+UPDATE stellar_detail SET luminosity=0.009472 WHERE id=549; -- K8 VI
+UPDATE stellar_detail SET luminosity=0.008315 WHERE id=550; -- K9 VI
+UPDATE stellar_detail SET luminosity=0.0073 WHERE id=551; -- M0 VI
+UPDATE stellar_detail SET luminosity=0.004084 WHERE id=552; -- M1 VI
+UPDATE stellar_detail SET luminosity=0.002285 WHERE id=553; -- M2 VI
+UPDATE stellar_detail SET luminosity=0.001278 WHERE id=554; -- M3 VI
+UPDATE stellar_detail SET luminosity=0.000715 WHERE id=555; -- M4 VI
+UPDATE stellar_detail SET luminosity=0.0004 WHERE id=556; -- M5 VI
+UPDATE stellar_detail SET luminosity=0.000296 WHERE id=557; -- M6 VI
+UPDATE stellar_detail SET luminosity=0.0002191 WHERE id=558; -- M7 VI
+UPDATE stellar_detail SET luminosity=0.0001621 WHERE id=559; -- M8 VI
+UPDATE stellar_detail SET luminosity=0.00012 WHERE id=560; -- M9 VI
+UPDATE stellar_detail SET luminosity=10 WHERE id=561; -- DO
+UPDATE stellar_detail SET luminosity=0.046 WHERE id=562; -- DB
+UPDATE stellar_detail SET luminosity=0.005 WHERE id=563; -- DA
+UPDATE stellar_detail SET luminosity=0.0005 WHERE id=564; -- DF
+UPDATE stellar_detail SET luminosity=0.0003 WHERE id=565; -- DG
+UPDATE stellar_detail SET luminosity=6e-05 WHERE id=566; -- DK
+UPDATE stellar_detail SET luminosity=3e-05 WHERE id=567; -- DM
+UPDATE stellar_detail SET luminosity=1e-05 WHERE id=568; -- BD
@@ -0,0 +1,33 @@
+-- Campaign overlays for canonical sectors. An overlay fills in data missing from the canon (such as the many
+-- "????" names in Foreven) without changing the canonical rows in the world table. Each save of an overlay for a
+-- campaign and sector is a new version, so earlier versions can be returned to.
+--
+CREATE TABLE IF NOT EXISTS "campaign_overlay" (
+	"id"	INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	"campaign"	TEXT NOT NULL,
+	"sector_id"	INTEGER NOT NULL,
+	"version"	INTEGER NOT NULL,
+	"created"	TEXT NOT NULL,
+	UNIQUE("campaign","sector_id","version"),
+	FOREIGN KEY("sector_id") REFERENCES "sector"("id")
+);
+
+-- The fields filled in for each world by an overlay. Fields that are NULL are taken from the canonical world.
+-- Capital is 1 if the overlay makes the world its subsector's capital.
+--
+CREATE TABLE IF NOT EXISTS "world_overlay" (
+	"overlay_id"	INTEGER NOT NULL,
+	"world_id"	INTEGER NOT NULL,
+	"name"	TEXT,
+	"stars"	TEXT,
+	"importance"	TEXT,
+	"economics"	TEXT,
+	"culture"	TEXT,
+	"nobility"	TEXT,
+	"worlds"	INTEGER,
+	"RU"	INTEGER,
+	"capital"	INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY("overlay_id","world_id"),
+	FOREIGN KEY("overlay_id") REFERENCES "campaign_overlay"("id"),
+	FOREIGN KEY("world_id") REFERENCES "world"("id")
+);
@@ -0,0 +1,7 @@
+-- Sectors that are not part of the Official Traveller Universe, such as generated sectors, are flagged so that they
+-- are never mixed with the canon when listing or searching sectors.
+ALTER TABLE sector
+  ADD COLUMN otu INTEGER NOT NULL DEFAULT 1;
+
+-- Fix the flag for sectors already generated
+UPDATE sector SET otu = 0 WHERE tags LIKE '%Generated%';
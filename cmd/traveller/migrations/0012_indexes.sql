@@ -0,0 +1,10 @@
+-- Indexes for the queries that load whole sectors and search the worlds of the whole OTU. Without them every load
+-- of a sector's worlds or subsectors is a scan of the whole table.
+--
+CREATE INDEX IF NOT EXISTS "world_sector_hex" ON "world" ("sector_id", "subsector_index", "hex");
+CREATE INDEX IF NOT EXISTS "world_name" ON "world" ("name");
+CREATE INDEX IF NOT EXISTS "subsector_sector_index" ON "subsector" ("sector_id", "subsector_index");
+CREATE INDEX IF NOT EXISTS "sector_name" ON "sector" ("name");
+CREATE INDEX IF NOT EXISTS "world_staging_sector_hex" ON "world_staging" ("sector_id", "hex");
+CREATE INDEX IF NOT EXISTS "world_overlay_overlay" ON "world_overlay" ("overlay_id");
+CREATE INDEX IF NOT EXISTS "stellar_detail_name" ON "stellar_detail" ("name");
@@ -0,0 +1,323 @@
+package main
+
+// naming.go contains code for naming generated subsectors and worlds, using the word generators for the
+// subsector's languages. Each subsector has a language mix (for example 70% Vilani, 30% Vargr), which is taken
+// from the user, the subsector's majority language in the database, or the allegiance of its worlds.
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"trav2/cmd/traveller/tools"
+	"unicode"
+)
+
+// Limits on the length of generated names, in letters.
+const (
+	minNameLength = 3
+	maxNameLength = 12
+)
+
+// maxNameAttempts is the number of words generated before giving up on finding a name that does not clash.
+const maxNameAttempts = 100
+
+// unnamed is the name given to worlds that have not been named.
+const unnamed = "????"
+
+// languageShare is one language in a language mix, and its share of the names.
+type languageShare struct {
+	Language Language // The language
+	Percent  int      // The share of names in the language, as a percentage
+}
+
+// languageMix contains the languages that names are generated in for a subsector.
+type languageMix []languageShare
+
+// hasGenerator returns whether names can be generated in the language. The Solomani generator is not written yet.
+func (l Language) hasGenerator() bool {
+	return l >= LanguageAslan && l < LanguageNone && l != LanguageSolomani
+}
+
+// languageFromName returns the language with the given name (case is ignored), or LanguageNone if it is unknown.
+// Anglic is named with the Vilani generator, as there is no Anglic (Solomani) generator yet.
+func languageFromName(name string) Language {
+	name = strings.TrimSpace(name)
+	if strings.EqualFold(name, "Anglic") || strings.EqualFold(name, "Solomani") {
+		return LanguageVilani
+	}
+	for l := LanguageAslan; l < LanguageNone; l++ {
+		if strings.EqualFold(l.String(), name) {
+			return l
+		}
+	}
+	return LanguageNone
+}
+
+// languageForAllegiance returns the language usually spoken by worlds of the allegiance code, eg "Va" for Vargr.
+func languageForAllegiance(code string) Language {
+	if len(code) < 2 {
+		return LanguageVilani
+	}
+	switch strings.ToLower(code[:2]) {
+	case "as":
+		return LanguageAslan
+	case "da":
+		return LanguageDarrian
+	case "dr":
+		return LanguageDroyne
+	case "kk":
+		return LanguageKkree
+	case "va":
+		return LanguageVargr
+	case "zh":
+		return LanguageZhodani
+	}
+	return LanguageVilani
+}
+
+// languageDbName returns the name of the language in the language table of the database.
+func languageDbName(l Language) string {
+	if l == LanguageSolomani {
+		return "Anglic"
+	}
+	return l.String()
+}
+
+// parseLanguageMix parses a language mix such as "70% Vilani, 30% Vargr" or "Aslan". Languages without a
+// percentage share what is left over equally. It returns an error if a language is unknown or cannot be used.
+func parseLanguageMix(s string) (mix languageMix, err error) {
+	remaining, unshared := 100, 0
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		share := languageShare{Percent: -1}
+		if i := strings.Index(part, "%"); i >= 0 {
+			if share.Percent, err = strconv.Atoi(strings.TrimSpace(part[:i])); err != nil || share.Percent < 0 {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidLanguageMix, part)
+			}
+			part = strings.TrimSpace(part[i+1:])
+			remaining -= share.Percent
+		} else {
+			unshared++
+		}
+		if share.Language = languageFromName(part); !share.Language.hasGenerator() {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidLanguageMix, part)
+		}
+		mix = append(mix, share)
+	}
+	if remaining < 0 {
+		return nil, fmt.Errorf("%w: shares add up to more than 100%%", ErrInvalidLanguageMix)
+	}
+	for i := range mix {
+		if mix[i].Percent < 0 {
+			mix[i].Percent = remaining / unshared
+		}
+	}
+	return mix, nil
+}
+
+// pick chooses a language from the mix, weighted by the shares. An empty mix gives Vilani.
+func (mix languageMix) pick(r *rand.Rand) Language {
+	total := 0
+	for _, s := range mix {
+		total += s.Percent
+	}
+	if total <= 0 {
+		return LanguageVilani
+	}
+	roll := tools.RollDice(r, total)
+	for _, s := range mix {
+		if roll -= s.Percent; roll <= 0 {
+			return s.Language
+		}
+	}
+	return mix[len(mix)-1].Language
+}
+
+// String returns the mix in the same form that parseLanguageMix reads, eg "70% Vilani, 30% Vargr".
+func (mix languageMix) String() string {
+	parts := make([]string, len(mix))
+	for i, s := range mix {
+		parts[i] = fmt.Sprintf("%d%% %s", s.Percent, s.Language)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sectorNamer generates names for a sector, making sure that no two names in the sector clash.
+type sectorNamer struct {
+	used []string // The names already in use, normalised by normaliseName
+}
+
+// normaliseName returns the name in lower case with anything but letters and digits removed, for comparing names.
+func normaliseName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// nameDistance returns the number of letters that must be inserted, removed, changed or swapped with their
+// neighbours to turn a into b. It is used both for names that clash and for the fuzzy search.
+func nameDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(minInt(d[i-1][j]+1, d[i][j-1]+1), d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// minInt returns the smaller of two ints.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// clashes returns whether the name is the same as, or only one letter (or a swap) different from, a name already in
+// use.
+func (n *sectorNamer) clashes(name string) bool {
+	norm := normaliseName(name)
+	for _, u := range n.used {
+		if nameDistance(norm, u) <= 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// inUse returns whether exactly the name (once normalised) is already in use.
+func (n *sectorNamer) inUse(name string) bool {
+	norm := normaliseName(name)
+	for _, u := range n.used {
+		if norm == u {
+			return true
+		}
+	}
+	return false
+}
+
+// use records the name as being in use.
+func (n *sectorNamer) use(name string) {
+	if name != "" && name != unnamed {
+		n.used = append(n.used, normaliseName(name))
+	}
+}
+
+// name generates a new name in a language from the mix that does not clash with any name in use, and records it.
+// If no such name can be found, the last word generated is used with a number after it.
+func (n *sectorNamer) name(r *rand.Rand, mix languageMix) (name string) {
+	for i := 0; i < maxNameAttempts; i++ {
+		word, _ := mix.pick(r).GenWord(r)
+		letters := len([]rune(normaliseName(word)))
+		if letters < minNameLength || letters > maxNameLength || !unicode.IsLetter([]rune(word)[0]) {
+			continue
+		}
+		name = word
+		if !n.clashes(name) {
+			n.use(name)
+			return
+		}
+	}
+	if name == "" {
+		name = "World"
+	}
+	for i := 2; ; i++ {
+		if numbered := fmt.Sprintf("%s %d", name, i); !n.inUse(numbered) {
+			n.use(numbered)
+			return numbered
+		}
+	}
+}
+
+// subsectorMix works out the language mix for a subsector of the sector: the mix given by the user if any, then
+// the majority language of the subsector from the database, then the language of the allegiance of its worlds.
+func (s *sector) subsectorMix(idx int, given string) languageMix {
+	if mix, err := parseLanguageMix(given); err == nil && len(mix) > 0 {
+		return mix
+	}
+	if mix, err := parseLanguageMix(s.subsectors[idx].language); err == nil && len(mix) > 0 {
+		return mix
+	}
+	for _, w := range s.worlds {
+		if w.subsectorIndex == ssIndex[idx] {
+			return languageMix{{Language: languageForAllegiance(w.allegiance), Percent: 100}}
+		}
+	}
+	return languageMix{{Language: LanguageVilani, Percent: 100}}
+}
+
+// nameSector names the subsectors and worlds of the sector that have not been named, or have been named but
+// not locked. Names that the user has accepted (locked) are kept. mixes holds a language mix for each subsector
+// (A to P) as a string for parseLanguageMix; any that are empty or cannot be parsed are worked out instead.
+func (s *sector) nameSector(r *rand.Rand, mixes [16]string) {
+	if s.lockedNames == nil {
+		s.lockedNames = make(map[string]bool)
+	}
+
+	// Locked names are kept, so they must be in use before any new names are generated.
+	var namer sectorNamer
+	for i := range s.subsectors {
+		if s.lockedSubsectors[i] {
+			namer.use(s.subsectors[i].name)
+		}
+	}
+	for _, w := range s.worlds {
+		if s.lockedNames[w.hexLoc.String()] {
+			namer.use(w.name)
+		}
+	}
+
+	for i := range s.subsectors {
+		mix := s.subsectorMix(i, mixes[i])
+		if !s.lockedSubsectors[i] {
+			s.subsectors[i].name = namer.name(r, mix)
+			s.subsectors[i].language = mix.String()
+		}
+		for j := range s.worlds {
+			w := &s.worlds[j]
+			if w.subsectorIndex != ssIndex[i] {
+				continue
+			}
+			if !s.lockedNames[w.hexLoc.String()] {
+				w.name = namer.name(r, mix)
+			}
+			w.subsector = s.subsectors[i].name
+		}
+	}
+}
+
+// lockNames locks the names of all the subsectors and worlds of the sector, so they are kept when it is renamed.
+func (s *sector) lockNames() {
+	if s.lockedNames == nil {
+		s.lockedNames = make(map[string]bool)
+	}
+	for _, w := range s.worlds {
+		s.lockedNames[w.hexLoc.String()] = true
+	}
+	for i := range s.lockedSubsectors {
+		s.lockedSubsectors[i] = true
+	}
+}
@@ -0,0 +1,259 @@
+// Package pdf writes simple PDF documents of lines, shapes and text. Only the standard fonts that every PDF reader
+// has are used, so no font files are needed and documents can be written offline. Positions are in points (1/72
+// inch) from the top left corner of the page, with y increasing down the page.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// Font is one of the standard PDF fonts.
+type Font int
+
+// The fonts that can be used.
+const (
+	Helvetica Font = iota
+	HelveticaBold
+	Courier
+	CourierBold
+)
+
+// fontNames are the PostScript names of the fonts.
+var fontNames = [...]string{"Helvetica", "Helvetica-Bold", "Courier", "Courier-Bold"}
+
+// Page sizes, in points, as width and height.
+var (
+	A4     = [2]float64{595, 842}
+	Letter = [2]float64{612, 792}
+	Digest = [2]float64{396, 612} // 5.5 by 8.5 inches, the size of the classic Traveller little black books
+)
+
+// helveticaWidths are the widths of the printable ASCII characters in Helvetica, in thousandths of the font size.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// TextWidth returns the width of the text in the font, in points. Courier is exact; Helvetica is exact for ASCII,
+// and the bold fonts and other characters are estimated.
+func TextWidth(f Font, size float64, s string) float64 {
+	if f == Courier || f == CourierBold {
+		return 0.6 * size * float64(len([]rune(s)))
+	}
+	w := 0
+	for _, r := range s {
+		if r >= ' ' && r <= '~' {
+			w += helveticaWidths[r-' ']
+		} else {
+			w += 556
+		}
+	}
+	if f == HelveticaBold {
+		w = w * 105 / 100
+	}
+	return float64(w) * size / 1000
+}
+
+// Document is a PDF document being built a page at a time.
+type Document struct {
+	title string
+	pages []*Page
+}
+
+// New returns a new, empty document with the title.
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+// Page is a page of a document. Drawing on it adds to its content.
+type Page struct {
+	width, height float64
+	content       bytes.Buffer
+}
+
+// AddPage adds a page of the size, in points, to the end of the document, and returns it for drawing on.
+func (d *Document) AddPage(size [2]float64) *Page {
+	p := &Page{width: size[0], height: size[1]}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Size returns the width and height of the page, in points.
+func (p *Page) Size() (width, height float64) {
+	return p.width, p.height
+}
+
+// op writes a drawing operator to the page's content.
+func (p *Page) op(format string, args ...interface{}) {
+	fmt.Fprintf(&p.content, format+"\n", args...)
+}
+
+// SetFill sets the colour that shapes and text are filled with.
+func (p *Page) SetFill(c color.RGBA) {
+	p.op("%.3f %.3f %.3f rg", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+// SetStroke sets the colour and width of lines, and whether they are dashed.
+func (p *Page) SetStroke(c color.RGBA, width float64, dashed bool) {
+	p.op("%.3f %.3f %.3f RG %.2f w", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255, width)
+	if dashed {
+		p.op("[%.2f] 0 d", 4*width)
+	} else {
+		p.op("[] 0 d")
+	}
+}
+
+// paint finishes a path, filling it with the fill colour and outlining it with the stroke colour as asked.
+func (p *Page) paint(fill, stroke bool) {
+	switch {
+	case fill && stroke:
+		p.op("B")
+	case fill:
+		p.op("f")
+	case stroke:
+		p.op("S")
+	default:
+		p.op("n")
+	}
+}
+
+// Line draws a line between the points.
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	p.op("%.2f %.2f m %.2f %.2f l S", x1, p.height-y1, x2, p.height-y2)
+}
+
+// Polygon draws the closed shape with the corners, given as x and y pairs.
+func (p *Page) Polygon(pts [][2]float64, fill, stroke bool) {
+	if len(pts) == 0 {
+		return
+	}
+	for i, pt := range pts {
+		cmd := "l"
+		if i == 0 {
+			cmd = "m"
+		}
+		p.op("%.2f %.2f %s", pt[0], p.height-pt[1], cmd)
+	}
+	p.op("h")
+	p.paint(fill, stroke)
+}
+
+// Rect draws the rectangle with its top left corner at x and y.
+func (p *Page) Rect(x, y, width, height float64, fill, stroke bool) {
+	p.op("%.2f %.2f %.2f %.2f re", x, p.height-y-height, width, height)
+	p.paint(fill, stroke)
+}
+
+// Circle draws the circle, made of four Bezier curves.
+func (p *Page) Circle(x, y, r float64, fill, stroke bool) {
+	k := r * 4 * (math.Sqrt2 - 1) / 3
+	y = p.height - y
+	p.op("%.2f %.2f m", x+r, y)
+	p.op("%.2f %.2f %.2f %.2f %.2f %.2f c", x+r, y+k, x+k, y+r, x, y+r)
+	p.op("%.2f %.2f %.2f %.2f %.2f %.2f c", x-k, y+r, x-r, y+k, x-r, y)
+	p.op("%.2f %.2f %.2f %.2f %.2f %.2f c", x-r, y-k, x-k, y-r, x, y-r)
+	p.op("%.2f %.2f %.2f %.2f %.2f %.2f c", x+k, y-r, x+r, y-k, x+r, y)
+	p.paint(fill, stroke)
+}
+
+// Text draws the text in the fill colour, starting at x with its baseline at y.
+func (p *Page) Text(x, y float64, f Font, size float64, s string) {
+	p.op("BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET", f+1, size, x, p.height-y, encode(s))
+}
+
+// encode encodes the text as the bytes of a PDF string in WinAnsi encoding, escaping the characters that need it.
+// Characters that are not in Latin-1 become question marks.
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r < ' ':
+			b.WriteByte(' ')
+		case r < 0x80 || (r >= 0xa0 && r <= 0xff):
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// Write writes the document as a PDF file. The page contents are compressed.
+func (d *Document) Write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	// Objects are numbered from 1 in the order they are started.
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 is the catalog, 2 the page tree, 3 the information, and the fonts follow. Each page is then a page
+	// object followed by its content stream.
+	firstFont := 4
+	firstPage := firstFont + len(fontNames)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (The Travellers Tool) /CreationDate (D:%s) >>", encode(d.title),
+		time.Now().UTC().Format("20060102150405Z")))
+	var fonts strings.Builder
+	for i, name := range fontNames {
+		obj(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fmt.Fprintf(&fonts, "/F%d %d 0 R ", i+1, firstFont+i)
+	}
+	for i, p := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			p.width, p.height, fonts.String(), firstPage+2*i+1))
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		if _, err := zw.Write(p.content.Bytes()); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		obj(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// WriteFile writes the document to the named file, replacing it if it exists.
+func (d *Document) WriteFile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err = d.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
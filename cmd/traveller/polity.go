@@ -0,0 +1,283 @@
+package main
+
+// polity.go contains code for growing polities across a generated sector. A few capitals are seeded on populous,
+// high tech worlds with good starports. Each polity then spreads out along the jump-2 or jump-3 routes its
+// technology allows, a jump at a time, until its strength runs out. Worlds just beyond a polity's reach may become
+// its client states, and the rest are left non-aligned.
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"trav2/cmd/traveller/tools"
+)
+
+// Limits used when growing polities.
+const (
+	worldsPerPolity = 120 // A polity is seeded for about this many worlds in the sector
+	maxPolities     = 8   // The most polities seeded in a sector
+	capitalSpacing  = 8   // The least distance in parsecs between two capitals
+	clientMinPop    = 4   // The least population code for a world to become a client state
+	resistingPop    = 9   // Worlds with at least this population code cost an extra jump to take over
+)
+
+// polity is an interstellar state, or a group of worlds such as client states or non-aligned worlds, with an
+// allegiance code for its worlds.
+type polity struct {
+	code       string // The four letter allegiance code, eg "FeKa"
+	legacyCode string // The two letter (legacy) allegiance code, eg "Ka"
+	name       string // The name, eg "Federation of Kargu"
+	capital    string // The hex of the capital world, or "" if there is none
+	worlds     int    // The number of worlds with this allegiance
+}
+
+// polityTypes are the kinds of polity, with the two letters that start their allegiance codes. A polity must have
+// at least minWorlds worlds to be of that kind; the biggest kind it can be is chosen.
+var polityTypes = [...]struct {
+	minWorlds int
+	name      string
+	abbr      string
+}{
+	{40, "Empire", "Em"}, {40, "Hegemony", "He"},
+	{15, "Federation", "Fe"}, {15, "Confederation", "Cf"}, {15, "Republic", "Re"},
+	{0, "League", "Le"}, {0, "Union", "Un"}, {0, "Cluster", "Cl"},
+}
+
+// nonAlignedCodes are the allegiance codes for non-aligned worlds, for the basic allegiance of the sector.
+var nonAlignedCodes = map[string]polity{
+	"Aslan":    {code: "NaAs", legacyCode: "Na", name: "Non-Aligned, Aslan-dominated"},
+	"Imperial": {code: "NaHu", legacyCode: "Na", name: "Non-Aligned, Human-dominated"},
+	"Vargr":    {code: "NaVa", legacyCode: "Na", name: "Non-Aligned, Vargr-dominated"},
+	"Zhodani":  {code: "NaZh", legacyCode: "Na", name: "Non-Aligned, Zhodani-dominated"},
+}
+
+// capitalScore scores how well the world would do as the capital of a polity. Worlds that cannot be a capital,
+// without a population of 7+, a tech level of 9+ and a class A or B starport, score 0.
+func capitalScore(w *world) int {
+	u := w.uwp
+	if u.popInt < 7 || u.techInt < 9 || (u.starport != "A" && u.starport != "B") {
+		return 0
+	}
+	score := u.popInt + u.techInt/2
+	if u.starport == "A" {
+		score += 2
+	}
+	return score
+}
+
+// polityClaim is a claim by a polity on a world, waiting in a claimQueue.
+type polityClaim struct {
+	cost     int  // The number of jumps from the capital, plus an extra jump for each resisting world
+	polity   int  // The index of the polity
+	world    int  // The index of the world
+	client   bool // Whether the world is beyond the polity's reach, so can only become a client state
+	strength int  // The strength of the polity, which wins ties
+}
+
+// claimQueue is a priority queue of claims, cheapest first, for use with container/heap.
+type claimQueue []polityClaim
+
+func (q claimQueue) Len() int { return len(q) }
+func (q claimQueue) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+	if q[i].strength != q[j].strength {
+		return q[i].strength > q[j].strength
+	}
+	return q[i].polity < q[j].polity
+}
+func (q claimQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *claimQueue) Push(x interface{}) { *q = append(*q, x.(polityClaim)) }
+func (q *claimQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// newAllegianceCode makes an unused four letter allegiance code from the two letter prefix and the letters of the
+// name, eg "Fe" and "Kargu" give "FeKa". Other letters of the name, then digits, are tried if the code is in use.
+// The code is recorded as used.
+func newAllegianceCode(prefix, name string, used map[string]bool) string {
+	letters := []rune(normaliseName(name))
+	if len(letters) < 2 {
+		letters = []rune("xx")
+	}
+	first := strings.ToUpper(string(letters[0]))
+	for _, r := range letters[1:] {
+		if code := prefix + first + string(r); !used[code] {
+			used[code] = true
+			return code
+		}
+	}
+	for i := 0; i < 100; i++ {
+		if code := fmt.Sprintf("%s%02d", prefix, i); !used[code] {
+			used[code] = true
+			return code
+		}
+	}
+	return prefix + "XX"
+}
+
+// growPolities seeds polities across the sector and grows them, setting the allegiance of every world. Worlds left
+// over are given the non-aligned code for the basic allegiance (a key of basicAllegianceMap). The polities are named
+// in the languages of their capitals' subsectors, using mixes as in nameSector, so the worlds should be named first.
+func (s *sector) growPolities(r *rand.Rand, allegiance string, mixes [16]string) {
+	ws := s.worlds
+	used := make(map[string]bool)
+	names, err := repo.getAllegianceNames(context.Background())
+	if err != nil {
+		log.Printf("Unable to get the allegiance codes, new codes may clash: %v", err)
+	}
+	for code := range names {
+		used[code] = true
+	}
+
+	// Seed the capitals on the best worlds, keeping them apart.
+	var candidates []int
+	for i := range ws {
+		if capitalScore(&ws[i]) > 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return capitalScore(&ws[candidates[i]]) > capitalScore(&ws[candidates[j]])
+	})
+	var capitals []int
+	for _, c := range candidates {
+		if len(capitals) >= minInt(maxPolities, 1+len(ws)/worldsPerPolity) {
+			break
+		}
+		apart := true
+		for _, o := range capitals {
+			if Distance(ws[c].hexLoc, ws[o].hexLoc) < capitalSpacing {
+				apart = false
+				break
+			}
+		}
+		if apart {
+			capitals = append(capitals, c)
+		}
+	}
+
+	// Grow the polities outward from their capitals, cheapest claims first, so that each world goes to the
+	// polity that reaches it in the fewest jumps.
+	reach := make([]int, len(capitals))
+	budget := make([]int, len(capitals))
+	strength := make([]int, len(capitals))
+	q := &claimQueue{}
+	for p, c := range capitals {
+		reach[p] = 2
+		if ws[c].uwp.techInt >= 13 {
+			reach[p] = 3
+		}
+		strength[p] = capitalScore(&ws[c]) + tools.RollD6(r) + tools.RollD6(r)
+		budget[p] = 2 + strength[p]/5
+		heap.Push(q, polityClaim{polity: p, world: c, strength: strength[p]})
+	}
+	owner := make([]int, len(ws))
+	client := make([]bool, len(ws))
+	for i := range owner {
+		owner[i] = -1
+	}
+	for q.Len() > 0 {
+		c := heap.Pop(q).(polityClaim)
+		if owner[c.world] >= 0 || (c.client && ws[c.world].uwp.popInt < clientMinPop) {
+			continue
+		}
+		owner[c.world], client[c.world] = c.polity, c.client
+		if c.client {
+			continue
+		}
+		for j := range ws {
+			if owner[j] >= 0 {
+				continue
+			}
+			if d := Distance(ws[c.world].hexLoc, ws[j].hexLoc); d == 0 || d > reach[c.polity] {
+				continue
+			}
+			cost := c.cost + 1
+			if ws[j].uwp.popInt >= resistingPop {
+				cost++
+			}
+			heap.Push(q, polityClaim{cost: cost, polity: c.polity, world: j, client: cost > budget[c.polity],
+				strength: c.strength})
+		}
+	}
+
+	// Name the polities, and make up their codes.
+	var namer sectorNamer
+	for i := range ws {
+		namer.use(ws[i].name)
+	}
+	for i := range s.subsectors {
+		namer.use(s.subsectors[i].name)
+	}
+	polities := make([]polity, len(capitals))
+	clients := make([]polity, len(capitals))
+	for p, c := range capitals {
+		size := 0
+		for i := range ws {
+			if owner[i] == p && !client[i] {
+				size++
+			}
+		}
+		// Choose a kind of polity from the biggest that the polity is big enough for.
+		var kinds []int
+		for k, t := range polityTypes {
+			if size >= t.minWorlds && (len(kinds) == 0 || t.minWorlds == polityTypes[kinds[0]].minWorlds) {
+				kinds = append(kinds, k)
+			}
+		}
+		kind := polityTypes[kinds[tools.RollDice(r, len(kinds))-1]]
+		name := namer.name(r, s.subsectorMix(ws[c].hexLoc.IntIndex(), mixes[ws[c].hexLoc.IntIndex()]))
+		letters := []rune(normaliseName(name))
+		polities[p] = polity{code: newAllegianceCode(kind.abbr, name, used),
+			legacyCode: strings.ToUpper(string(letters[0])) + string(letters[1]),
+			name:       fmt.Sprintf("%s of %s", kind.name, name), capital: ws[c].hexLoc.String()}
+		clients[p] = polity{code: newAllegianceCode("Cs", name, used), legacyCode: "Cs",
+			name: "Client state, " + polities[p].name}
+	}
+	nonAligned, ok := nonAlignedCodes[allegiance]
+	if !ok {
+		nonAligned = nonAlignedCodes["Imperial"]
+	}
+
+	// Set the allegiances.
+	for i := range ws {
+		w := &ws[i]
+		switch {
+		case owner[i] < 0:
+			w.allegiance = nonAligned.code
+			nonAligned.worlds++
+		case client[i]:
+			w.allegiance = clients[owner[i]].code
+			clients[owner[i]].worlds++
+		default:
+			w.allegiance = polities[owner[i]].code
+			polities[owner[i]].worlds++
+		}
+		if w.nobility != "" {
+			w.getNobility()
+		}
+	}
+	for _, c := range capitals {
+		ws[c].remarks = strings.TrimSpace(ws[c].remarks + " Cx")
+	}
+
+	s.polities = polities
+	for _, c := range clients {
+		if c.worlds > 0 {
+			s.polities = append(s.polities, c)
+		}
+	}
+	if nonAligned.worlds > 0 {
+		s.polities = append(s.polities, nonAligned)
+	}
+	log.Printf("Grew %d polities across %d worlds of %s sector", len(capitals), len(ws), s.name)
+}
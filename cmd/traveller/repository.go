@@ -0,0 +1,168 @@
+package main
+
+// repository.go contains the repository, which holds the one pool of connections to the sqlite database for the life
+// of the program. Queries are prepared the first time they are used and the prepared statements are kept for reuse,
+// so that loading whole sectors and searching the whole OTU do not open the database or parse SQL for every query.
+// Every method of the repository takes a context, so that long queries can be cancelled. The typed methods for
+// sectors, subsectors, worlds, allegiances, races, skills and stars are in sqliteDb.go.
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+)
+
+// repository gives access to the database. The database is opened when it is first used.
+type repository struct {
+	mu    sync.Mutex
+	file  string               // The name of the sqlite3 database file
+	db    *sql.DB              // The connection pool, nil until the database is first used
+	stmts map[string]*sql.Stmt // The prepared statements, by their SQL
+}
+
+// repo is the repository used throughout the program. Its database file is set by SetDbFile.
+var repo = &repository{}
+
+// SetDbFile sets the name of the sqlite3 database file. A database already open is closed, to be opened again
+// from the new file when next used.
+func SetDbFile(dbf string) {
+	repo.close()
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.file = dbf
+}
+
+// conn returns the repository's connection pool, opening the database the first time.
+func (r *repository) conn(ctx context.Context) (*sql.DB, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.db != nil {
+		return r.db, nil
+	}
+	db, e := sql.Open(dbType, r.file)
+	if e != nil {
+		return nil, e
+	}
+	if e = db.PingContext(ctx); e != nil {
+		db.Close()
+		return nil, e
+	}
+	r.db, r.stmts = db, make(map[string]*sql.Stmt)
+	log.Printf("Opened database %s", r.file)
+	return db, nil
+}
+
+// close closes the prepared statements and the connection pool. The database is opened again if it is used after.
+func (r *repository) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.db == nil {
+		return nil
+	}
+	for _, s := range r.stmts {
+		s.Close()
+	}
+	e := r.db.Close()
+	r.db, r.stmts = nil, nil
+	return e
+}
+
+// prepare returns the prepared statement for the query, preparing it the first time the query is used.
+func (r *repository) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	db, e := r.conn(ctx)
+	if e != nil {
+		return nil, e
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.stmts[query]; ok {
+		return s, nil
+	}
+	s, e := db.PrepareContext(ctx, query)
+	if e != nil {
+		return nil, e
+	}
+	r.stmts[query] = s
+	return s, nil
+}
+
+// query runs the query, which returns rows, with the arguments for its parameters.
+func (r *repository) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	s, e := r.prepare(ctx, query)
+	if e != nil {
+		return nil, e
+	}
+	return s.QueryContext(ctx, args...)
+}
+
+// queryRow runs the query, which returns at most one row, and scans the row into dest. It returns sql.ErrNoRows if
+// there is no row.
+func (r *repository) queryRow(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	s, e := r.prepare(ctx, query)
+	if e != nil {
+		return e
+	}
+	return s.QueryRowContext(ctx, args...).Scan(dest...)
+}
+
+// exec runs the statement, which returns no rows, with the arguments for its parameters.
+func (r *repository) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	s, e := r.prepare(ctx, query)
+	if e != nil {
+		return nil, e
+	}
+	return s.ExecContext(ctx, args...)
+}
+
+// inTx runs fn in a transaction, which is committed if fn returns nil and rolled back if not.
+func (r *repository) inTx(ctx context.Context, fn func(tx *repoTx) error) error {
+	db, e := r.conn(ctx)
+	if e != nil {
+		return e
+	}
+	tx, e := db.BeginTx(ctx, nil)
+	if e != nil {
+		return e
+	}
+	defer tx.Rollback()
+
+	if e = fn(&repoTx{r: r, tx: tx, ctx: ctx}); e != nil {
+		return e
+	}
+	return tx.Commit()
+}
+
+// repoTx is a transaction on the repository's database. Its statements use the repository's prepared statements.
+type repoTx struct {
+	r   *repository
+	tx  *sql.Tx
+	ctx context.Context
+}
+
+// stmt returns the repository's prepared statement for the query, for use in the transaction.
+func (t *repoTx) stmt(query string) (*sql.Stmt, error) {
+	s, e := t.r.prepare(t.ctx, query)
+	if e != nil {
+		return nil, e
+	}
+	return t.tx.StmtContext(t.ctx, s), nil
+}
+
+// exec runs the statement, which returns no rows, in the transaction.
+func (t *repoTx) exec(query string, args ...interface{}) (sql.Result, error) {
+	s, e := t.stmt(query)
+	if e != nil {
+		return nil, e
+	}
+	return s.ExecContext(t.ctx, args...)
+}
+
+// queryRow runs the query, which returns at most one row, in the transaction and scans the row into dest.
+func (t *repoTx) queryRow(query string, args []interface{}, dest ...interface{}) error {
+	s, e := t.stmt(query)
+	if e != nil {
+		return e
+	}
+	return s.QueryRowContext(t.ctx, args...).Scan(dest...)
+}
@@ -0,0 +1,314 @@
+package main
+
+// routes.go contains code for generating the xboat and trade routes of a sector. The xboat network links the
+// capitals and important worlds of each polity, using the fewest and shortest jump-4 links. Trade routes are worked
+// out from the World Trade Numbers (WTN) and Bilateral Trade Numbers (BTN) of pairs of worlds, after GURPS
+// Traveller: Far Trader, and follow the jump-2 links between the worlds. The routes can be written to the <Routes>
+// element of Travellermap sector metadata.
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"trav2/cmd/traveller/metadata"
+)
+
+// routeType is the kind of a route.
+type routeType int
+
+// The kinds of route, from the most to the least important.
+const (
+	routeXboat      routeType = iota // An xboat (express boat) communication route
+	routeMajorTrade                  // A major trade route
+	routeMinorTrade                  // A minor trade route
+)
+
+// String returns the route type, as used in the Type attribute of Travellermap routes.
+func (t routeType) String() string {
+	switch t {
+	case routeXboat:
+		return "Xboat"
+	case routeMajorTrade:
+		return "Major"
+	default:
+		return "Minor"
+	}
+}
+
+// Limits used when generating routes.
+const (
+	xboatJump        = 4    // The longest xboat link, in parsecs
+	tradeJump        = 2    // The longest jump that trade routes are made of
+	maxTradeDistance = 10   // The furthest apart two worlds can be, in parsecs, to trade with each other
+	majorTradeBTN    = 12.0 // The least BTN for a major trade route
+	minorTradeBTN    = 10.0 // The least BTN for a minor trade route
+	hubImportance    = 4    // The least importance for a world to be an xboat station
+)
+
+// route is a single link of a route between two worlds of a sector.
+type route struct {
+	start, end HexLoc    // The hexes at each end of the link
+	kind       routeType // The kind of route
+	allegiance string    // The allegiance of the xboat network, or "" for trade routes
+}
+
+// wtnTechMod is the modifier to the World Trade Number for tech level, indexed by tech level.
+var wtnTechMod = [...]float64{-0.5, -0.5, 0, 0, 0, 0, 0.5, 0.5, 0.5, 1, 1, 1, 1.5, 1.5, 1.5, 2}
+
+// wtnPortMod is the modifier to the World Trade Number for the starport, indexed by the WTN so far (halved, up to
+// 6) and then the starport (A, B, C, D, E, X).
+var wtnPortMod = [...][6]float64{
+	{3, 2, 2, 1.5, 1, 0},
+	{2.5, 2, 1.5, 1, 0.5, -0.5},
+	{2, 1.5, 1, 0.5, 0, -1},
+	{1.5, 1, 0.5, 0, -0.5, -1.5},
+	{1, 0.5, 0, -0.5, -1, -2},
+	{0.5, 0, -0.5, -1, -1.5, -2.5},
+	{0, -0.5, -1, -1.5, -2, -5},
+}
+
+// worldTradeNumber works out the World Trade Number of the world, from its population, tech level and starport.
+func worldTradeNumber(w *world) float64 {
+	u := w.uwp
+	wtn := float64(u.popInt) / 2
+	wtn += wtnTechMod[minInt(maxInt(u.techInt, 0), len(wtnTechMod)-1)]
+	port := strings.Index("ABCDE", u.starport)
+	if port < 0 {
+		port = 5
+	}
+	row := minInt(maxInt(int(wtn)/2, 0), len(wtnPortMod)-1)
+	return math.Max(wtn+wtnPortMod[row][port], 0)
+}
+
+// tradeDistanceMod is the modifier to the Bilateral Trade Number for the distance between the worlds.
+func tradeDistanceMod(d int) float64 {
+	switch {
+	case d <= 1:
+		return 0
+	case d == 2:
+		return 0.5
+	case d <= 5:
+		return 1
+	case d <= 9:
+		return 1.5
+	case d <= 19:
+		return 2
+	case d <= 29:
+		return 2.5
+	}
+	return 3
+}
+
+// bilateralTradeNumber works out the Bilateral Trade Number between two worlds, given their World Trade Numbers.
+// It is never more than 5 above the smaller WTN.
+func bilateralTradeNumber(w1, w2 *world, wtn1, wtn2 float64) float64 {
+	btn := wtn1 + wtn2 - tradeDistanceMod(Distance(w1.hexLoc, w2.hexLoc))
+	pair := func(a, b string) bool {
+		return (strings.Contains(w1.remarks, a) && strings.Contains(w2.remarks, b)) ||
+			(strings.Contains(w1.remarks, b) && strings.Contains(w2.remarks, a))
+	}
+	// Worlds that complement each other trade more.
+	if pair("Ag", "Na") || pair("In", "Ni") {
+		btn += 0.5
+	}
+	if w1.allegiance != w2.allegiance {
+		btn--
+	}
+	return math.Min(btn, math.Min(wtn1, wtn2)+5)
+}
+
+// maxInt returns the larger of two ints.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// isXboatHub returns whether the world is an xboat station: a capital, or an important world. Non-aligned worlds
+// have no xboat stations.
+func isXboatHub(w *world) bool {
+	if strings.HasPrefix(w.allegiance, "Na") {
+		return false
+	}
+	for _, r := range strings.Fields(w.remarks) {
+		if r == "Cp" || r == "Cs" || r == "Cx" {
+			return true
+		}
+	}
+	importance := w.importance.Importance
+	if w.genType != WgtT5ss {
+		// Only T5 worlds have an importance, so work one out for the others.
+		c := *w
+		importance = c.determineImportanceExtension().Importance
+	}
+	return importance >= hubImportance
+}
+
+// generateRoutes generates the xboat and trade routes for the worlds of the sector, replacing any it already has.
+func (s *sector) generateRoutes() {
+	s.routes = append(s.xboatRoutes(), s.tradeRoutes()...)
+}
+
+// xboatRoutes links the xboat stations of each allegiance into a network, using the fewest and shortest links
+// (a minimum spanning tree). Stations more than a jump-4 from any other of their allegiance are left out.
+func (s *sector) xboatRoutes() (rs []route) {
+	ws := s.worlds
+	var hubs []int
+	for i := range ws {
+		if isXboatHub(&ws[i]) {
+			hubs = append(hubs, i)
+		}
+	}
+	type link struct{ a, b, d int }
+	var links []link
+	for i, a := range hubs {
+		for _, b := range hubs[i+1:] {
+			if ws[a].allegiance != ws[b].allegiance {
+				continue
+			}
+			if d := Distance(ws[a].hexLoc, ws[b].hexLoc); d <= xboatJump {
+				links = append(links, link{a, b, d})
+			}
+		}
+	}
+	sort.SliceStable(links, func(i, j int) bool { return links[i].d < links[j].d })
+
+	// Kruskal's algorithm, with the networks kept as a union-find forest.
+	parent := make(map[int]int)
+	var find func(i int) int
+	find = func(i int) int {
+		if p, ok := parent[i]; ok && p != i {
+			parent[i] = find(p)
+			return parent[i]
+		}
+		return i
+	}
+	for _, l := range links {
+		if ra, rb := find(l.a), find(l.b); ra != rb {
+			parent[ra] = rb
+			rs = append(rs, route{start: ws[l.a].hexLoc, end: ws[l.b].hexLoc, kind: routeXboat, allegiance: ws[l.a].allegiance})
+		}
+	}
+	return
+}
+
+// tradeRoutes works out the BTN of every pair of worlds within maxTradeDistance, and lays a route along the
+// shortest jump-2 path between each pair that trades enough. Where routes share a link, the link has the most
+// important kind.
+func (s *sector) tradeRoutes() (rs []route) {
+	ws := s.worlds
+	wtn := make([]float64, len(ws))
+	neighbours := make([][]int, len(ws))
+	for i := range ws {
+		wtn[i] = worldTradeNumber(&ws[i])
+		for j := range ws {
+			if d := Distance(ws[i].hexLoc, ws[j].hexLoc); i != j && d <= tradeJump {
+				neighbours[i] = append(neighbours[i], j)
+			}
+		}
+	}
+
+	links := make(map[[2]int]routeType)
+	for i := range ws {
+		for j := i + 1; j < len(ws); j++ {
+			if Distance(ws[i].hexLoc, ws[j].hexLoc) > maxTradeDistance {
+				continue
+			}
+			kind := routeMinorTrade
+			switch btn := bilateralTradeNumber(&ws[i], &ws[j], wtn[i], wtn[j]); {
+			case btn >= majorTradeBTN:
+				kind = routeMajorTrade
+			case btn < minorTradeBTN:
+				continue
+			}
+			path := jumpPath(neighbours, i, j)
+			for k := 1; k < len(path); k++ {
+				key := [2]int{minInt(path[k-1], path[k]), maxInt(path[k-1], path[k])}
+				if old, ok := links[key]; !ok || kind < old {
+					links[key] = kind
+				}
+			}
+		}
+	}
+
+	for key, kind := range links {
+		rs = append(rs, route{start: ws[key[0]].hexLoc, end: ws[key[1]].hexLoc, kind: kind})
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].kind != rs[j].kind {
+			return rs[i].kind < rs[j].kind
+		}
+		if rs[i].start != rs[j].start {
+			return rs[i].start.String() < rs[j].start.String()
+		}
+		return rs[i].end.String() < rs[j].end.String()
+	})
+	return
+}
+
+// jumpPath finds the shortest path (in jumps) from world a to world b, given the worlds each world can jump to.
+// It returns the indexes of the worlds along the path, including a and b, or nil if there is no path.
+func jumpPath(neighbours [][]int, a, b int) []int {
+	from := map[int]int{a: a}
+	queue := []int{a}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == b {
+			var path []int
+			for ; cur != a; cur = from[cur] {
+				path = append([]int{cur}, path...)
+			}
+			return append([]int{a}, path...)
+		}
+		for _, n := range neighbours[cur] {
+			if _, seen := from[n]; !seen {
+				from[n] = cur
+				queue = append(queue, n)
+			}
+		}
+	}
+	return nil
+}
+
+// metadataRoutes converts the routes to Travellermap metadata routes. Trade routes are given a colour and style,
+// as Travellermap only knows how to draw xboat routes.
+func metadataRoutes(rs []route) (mrs []metadata.Route) {
+	for _, r := range rs {
+		mr := metadata.Route{Start: r.start.String(), End: r.end.String(), Type: r.kind.String(), Allegiance: r.allegiance}
+		switch r.kind {
+		case routeMajorTrade:
+			mr.Style, mr.Color = "Solid", "#E0A000"
+		case routeMinorTrade:
+			mr.Style, mr.Color = "Dashed", "#A08000"
+		}
+		mrs = append(mrs, mr)
+	}
+	return
+}
+
+// routesFromMetadata converts the Travellermap metadata routes within the sector back to routes. Links that cross
+// into a neighbouring sector are left out. Routes with no type are taken to be xboat routes, as on Travellermap.
+func routesFromMetadata(mrs []metadata.Route) (rs []route) {
+	for _, mr := range mrs {
+		if mr.StartOffsetX != 0 || mr.StartOffsetY != 0 || mr.EndOffsetX != 0 || mr.EndOffsetY != 0 {
+			continue
+		}
+		start, end := NewHexLoc(mr.Start, true), NewHexLoc(mr.End, true)
+		if start == nil || end == nil {
+			continue
+		}
+		r := route{start: *start, end: *end, allegiance: mr.Allegiance}
+		switch mr.Type {
+		case "", "Xboat":
+			r.kind = routeXboat
+		case "Major":
+			r.kind = routeMajorTrade
+		default:
+			r.kind = routeMinorTrade
+		}
+		rs = append(rs, r)
+	}
+	return
+}
@@ -2,8 +2,15 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"image/color"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+	"trav2/cmd/traveller/tools"
 
 	"github.com/inkyblackness/imgui-go"
 )
@@ -63,6 +70,9 @@ func Run(p Platform, r Renderer) {
 	showDebugWindow := false
 	showLogWindow := false
 	showWordgenWindow := false
+	showWorldMapWindow := false
+	showJumpWindow := false
+	showSectorWindow := false
 	clearColor := [3]float32{0.0, 0.0, 0.0}
 	f := float32(0)
 	counter := 0
@@ -79,6 +89,94 @@ func Run(p Platform, r Renderer) {
 
 	var langText bytes.Buffer
 
+	mapName := "Regina"
+	mapUwp := "A788899-C"
+	mapSeed := int32(1)
+	mapStatus := ""
+	var currentMap *worldMap
+
+	jumpStar := "G2 V"
+	jumpUwp := "A788899-C"
+	jumpOrbit := float32(3)
+	jumpToOrbit := float32(5)
+	jumpText := ""
+
+	secName := "Unknown"
+	secAllegiance := "Imperial"
+	secDensity := sdStandard
+	secTraffic := ssStandard
+	secRules := RulesMegaTraveller
+	secSeed := int32(0)
+	secChallenge25 := false
+	secPolities := false
+	secRoutes := false
+	secImperial := false
+	var secLanguages [16]string
+	showVerifyWindow := false
+	verifyText := ""
+	secStatus := ""
+	secSortColumn := 0
+	secSortAscending := true
+	var secGeneration *sectorGeneration
+	var currentSector *sector
+	showCompletionWindow := false
+	compSector := forevenSector
+	compCampaign := config.Campaign
+	compStatus := ""
+	var currentCompletion *sectorCompletion
+	showImportWindow := false
+	importFile := "foreven-metadata.xml"
+	importStatus := ""
+	showStatsWindow := false
+	statsSector := forevenSector
+	statsScope := 0
+	statsStatus := ""
+	var statsLoaded *sector
+	var statsTotal *sectorStats
+	var statsSubsectors [16]*sectorStats
+	showHexMapWindow := false
+	hexMapSector := forevenSector
+	hexMapFile := config.ForevenFile
+	hexMapScopeSel := scopeSector
+	hexMapIndex := 0
+	hexMapStyleSel := stylePoster
+	hexMapRadius := int32(32)
+	hexMapStatus := ""
+	var hexMapLoaded *sector
+	var secExport, hexMapExport exportPanel
+	showSectorImportWindow := false
+	sfImportFile := "data/Joyner_worlds.tab"
+	sfImportSector := ""
+	sfImportColumns := defaultImportColumns
+	sfImportReplace := false
+	sfImportStatus := ""
+	var sfImport *sectorImport
+	showAtlasWindow := false
+	atlasCampaign := config.Campaign
+	atlasSectors := forevenSector
+	atlasDir := "atlas"
+	atlasNotesDir := ""
+	atlasStyleSel := stylePoster
+	atlasStatus := ""
+	showHandoutWindow := false
+	handoutSector := forevenSector
+	handoutHex := "0101"
+	handoutName := "Unnamed"
+	handoutDir := "handouts"
+	handoutNotesDir := ""
+	handoutStatus := ""
+	var handoutWorld *world
+	showSearchWindow := false
+	searchText := ""
+	searchStatus := ""
+	var searchResults []searchResult
+	var searchExport exportPanel
+	allegiances := make([]string, 0, len(basicAllegianceMap))
+	for k := range basicAllegianceMap {
+		allegiances = append(allegiances, k)
+	}
+	sort.Strings(allegiances)
+
 	for !p.ShouldStop() {
 		p.ProcessEvents()
 
@@ -96,6 +194,12 @@ func Run(p Platform, r Renderer) {
 				if imgui.MenuItem("Backup") {
 					doNotImplementedPopup = true
 				}
+				if imgui.MenuItemV("Import Metadata", "", showImportWindow, true) {
+					showImportWindow = !showImportWindow
+				}
+				if imgui.MenuItemV("Import Sector File", "", showSectorImportWindow, true) {
+					showSectorImportWindow = !showSectorImportWindow
+				}
 				imgui.Separator()
 				if imgui.MenuItem("Settings") {
 					doNotImplementedPopup = true
@@ -136,14 +240,38 @@ func Run(p Platform, r Renderer) {
 				if imgui.MenuItem("Worlds") {
 					doNotImplementedPopup = true
 				}
+				if imgui.MenuItemV("Sector", "", showSectorWindow, true) {
+					showSectorWindow = !showSectorWindow
+				}
+				if imgui.MenuItemV("World Map", "", showWorldMapWindow, true) {
+					showWorldMapWindow = !showWorldMapWindow
+				}
+				if imgui.MenuItemV("Sector Completion", "", showCompletionWindow, true) {
+					showCompletionWindow = !showCompletionWindow
+				}
 				imgui.EndMenu()
 			}
 			if imgui.BeginMenu("Tools") {
 				if imgui.MenuItem("Manage Campaign") {
 					doNotImplementedPopup = true
 				}
-				if imgui.MenuItem("Search") {
-					doNotImplementedPopup = true
+				if imgui.MenuItemV("Search", "", showSearchWindow, true) {
+					showSearchWindow = !showSearchWindow
+				}
+				if imgui.MenuItemV("Jump Calculator", "", showJumpWindow, true) {
+					showJumpWindow = !showJumpWindow
+				}
+				if imgui.MenuItemV("Sector Statistics", "", showStatsWindow, true) {
+					showStatsWindow = !showStatsWindow
+				}
+				if imgui.MenuItemV("Sector Map", "", showHexMapWindow, true) {
+					showHexMapWindow = !showHexMapWindow
+				}
+				if imgui.MenuItemV("HTML Atlas", "", showAtlasWindow, true) {
+					showAtlasWindow = !showAtlasWindow
+				}
+				if imgui.MenuItemV("World Handouts", "", showHandoutWindow, true) {
+					showHandoutWindow = !showHandoutWindow
 				}
 				if imgui.MenuItem("ImGui-Go Debug") {
 					showDebugWindow = true
@@ -279,6 +407,744 @@ func Run(p Platform, r Renderer) {
 			imgui.End()
 		}
 
+		// 6. Show the World Map window
+		if showWorldMapWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 100, Y: 60}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+			imgui.SetNextWindowSizeV(imgui.Vec2{X: 1000, Y: 480}, imgui.ConditionFirstUseEver)
+
+			// Start the World Map Window
+			imgui.BeginV("World Map", &showWorldMapWindow, 0)
+			imgui.PushItemWidth(120)
+			imgui.InputText("Name", &mapName)
+			imgui.SameLine()
+			imgui.InputText("UWP", &mapUwp)
+			imgui.SameLine()
+			imgui.InputInt("Seed", &mapSeed)
+			imgui.PopItemWidth()
+			imgui.SameLine()
+			if imgui.Button("Generate") {
+				if uwp := parseUwp(mapUwp); uwp.validate() {
+					currentMap = generateWorldMap(mapName, uwp, int64(mapSeed))
+					mapStatus = ""
+				} else {
+					mapStatus = fmt.Sprintf("Invalid UWP %s", mapUwp)
+				}
+			}
+			if currentMap != nil {
+				imgui.SameLine()
+				if imgui.Button("Export PNG") {
+					mapStatus = exportWorldMap(currentMap, "png")
+				}
+				imgui.SameLine()
+				if imgui.Button("Export SVG") {
+					mapStatus = exportWorldMap(currentMap, "svg")
+				}
+			}
+			if mapStatus != "" {
+				imgui.Text(mapStatus)
+			}
+			imgui.Separator()
+
+			if currentMap != nil {
+				// World details on the left, the map on the right.
+				imgui.BeginChildV("mapdetails", imgui.Vec2{X: 260, Y: 0}, true, 0)
+				imgui.Text(worldMapDetails(currentMap))
+				imgui.EndChild()
+				imgui.SameLine()
+				imgui.BeginChildV("mapimage", imgui.Vec2{}, false, imgui.WindowFlagsHorizontalScrollbar)
+				drawWorldMap(currentMap, 120)
+				imgui.EndChild()
+			}
+			imgui.End()
+		}
+
+		// 7. Show the Jump Calculator window
+		if showJumpWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 200, Y: 80}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+			imgui.SetNextWindowSizeV(imgui.Vec2{X: 560, Y: 360}, imgui.ConditionFirstUseEver)
+
+			// Start the Jump Calculator Window
+			imgui.BeginV("Jump Calculator", &showJumpWindow, 0)
+			imgui.InputText("Star", &jumpStar)
+			imgui.SameLine()
+			HelpMarker("The spectral type and size of the star, eg G2 V, M3 D or BD.")
+			imgui.InputText("UWP", &jumpUwp)
+			imgui.SameLine()
+			HelpMarker("The UWP of the world. Only the size is used.")
+			imgui.InputFloat("Orbit", &jumpOrbit)
+			imgui.InputFloat("To Orbit", &jumpToOrbit)
+			if imgui.Button("Calculate") {
+				jumpText = jumpCalculation(jumpStar, jumpUwp, float64(jumpOrbit), float64(jumpToOrbit))
+			}
+			imgui.Separator()
+			imgui.Text(jumpText)
+			imgui.End()
+		}
+
+		// 8. Show the Sector Generator window
+		if showSectorWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 60, Y: 60}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+			imgui.SetNextWindowSizeV(imgui.Vec2{X: 900, Y: 600}, imgui.ConditionFirstUseEver)
+
+			// Start the Sector Generator Window
+			imgui.BeginV("Sector Generator", &showSectorWindow, 0)
+			imgui.PushItemWidth(200)
+			imgui.InputText("Sector name", &secName)
+			if imgui.BeginComboV("Allegiance", secAllegiance, 0) {
+				for _, a := range allegiances {
+					if imgui.SelectableV(a, a == secAllegiance, 0, imgui.Vec2{}) {
+						secAllegiance = a
+					}
+				}
+				imgui.EndCombo()
+			}
+			if imgui.BeginComboV("Star density", mtSectorStarDensity[secDensity], 0) {
+				for i, d := range mtSectorStarDensity {
+					if imgui.SelectableV(d, i == secDensity, 0, imgui.Vec2{}) {
+						secDensity = i
+					}
+				}
+				imgui.EndCombo()
+			}
+			if imgui.BeginComboV("Traffic", mtSubsectorTrafficArr[secTraffic], 0) {
+				for i, t := range mtSubsectorTrafficArr {
+					if imgui.SelectableV(t, i == secTraffic, 0, imgui.Vec2{}) {
+						secTraffic = i
+					}
+				}
+				imgui.EndCombo()
+			}
+			imgui.SameLine()
+			HelpMarker("The subsector traffic level sets the starport quality. It is only used by MegaTraveller.")
+			if imgui.BeginComboV("Ruleset", secRules.String(), 0) {
+				for _, r := range sectorRulesets {
+					if imgui.SelectableV(r.String(), r == secRules, 0, imgui.Vec2{}) {
+						secRules = r
+					}
+				}
+				imgui.EndCombo()
+			}
+			imgui.Checkbox("Challenge #25 (1986)", &secChallenge25)
+			imgui.SameLine()
+			HelpMarker("Use the sector generator from Challenge Magazine #25. The density, traffic and ruleset are not used.")
+			imgui.Checkbox("Grow polities", &secPolities)
+			imgui.SameLine()
+			HelpMarker("Seed polities on the best worlds and grow them along jump routes, with client states and\n" +
+				"non-aligned worlds. Otherwise every world has the allegiance.")
+			imgui.Checkbox("Generate routes", &secRoutes)
+			imgui.SameLine()
+			HelpMarker("Link the capitals and important worlds with xboat routes, and work out the major and minor\n" +
+				"trade routes from the world trade numbers and the distances between the worlds.")
+			imgui.Checkbox("New Imperial sector", &secImperial)
+			imgui.SameLine()
+			HelpMarker("Generate a long settled Imperial sector outside the OTU, with more populous, higher tech worlds,\n" +
+				"subsector capitals, an xboat network with way stations, and nobility. Routes are always generated.")
+			imgui.InputInt("Seed", &secSeed)
+			imgui.SameLine()
+			HelpMarker("The same seed and options generate the same sector. Use 0 for a random seed.")
+			HelpMarker("The languages used to name the worlds of each subsector, eg \"70% Vilani, 30% Vargr\".\n" +
+				"If left empty, the subsector's language or the allegiance is used.")
+			imgui.SameLine()
+			if imgui.TreeNode("Subsector languages") {
+				for i := range secLanguages {
+					imgui.InputText(ssIndex[i], &secLanguages[i])
+				}
+				imgui.TreePop()
+			}
+			imgui.PopItemWidth()
+
+			if secGeneration != nil {
+				// Generation is under way, so show its progress until it finishes.
+				done, total, finished := secGeneration.progress()
+				fraction := float32(0)
+				if total > 0 {
+					fraction = float32(done) / float32(total)
+				}
+				imgui.ProgressBarV(fraction, imgui.Vec2{X: 400, Y: 0}, fmt.Sprintf("%d/%d systems", done, total))
+				if finished {
+					var opts sectorGenOptions
+					currentSector, opts = secGeneration.result()
+					sortSectorWorlds(currentSector.worlds, secSortColumn, secSortAscending)
+					secSeed = int32(opts.Seed)
+					secStatus = fmt.Sprintf("Generated %d worlds, seed %d", len(currentSector.worlds), opts.Seed)
+					secGeneration = nil
+				}
+			} else {
+				if imgui.Button("Generate") {
+					secGeneration = startSectorGeneration(sectorGenOptions{Name: secName, Allegiance: secAllegiance,
+						Density: secDensity, Traffic: secTraffic, Rules: secRules, Seed: int64(secSeed), Challenge25: secChallenge25,
+						Polities: secPolities, Routes: secRoutes, Imperial: secImperial, Languages: secLanguages})
+					currentSector = nil
+					secStatus = ""
+				}
+				if currentSector != nil {
+					imgui.SameLine()
+					if imgui.Button("Save") {
+						secStatus = saveSector(currentSector)
+					}
+					imgui.SameLine()
+					if imgui.Button("Rename") {
+						currentSector.nameSector(tools.RNG(), secLanguages)
+						sortSectorWorlds(currentSector.worlds, secSortColumn, secSortAscending)
+					}
+					imgui.SameLine()
+					if imgui.Button("Accept Names") {
+						currentSector.lockNames()
+					}
+					imgui.SameLine()
+					HelpMarker("Rename gives new names to the subsectors and worlds, except those that are ticked.\n" +
+						"Accept Names ticks them all.")
+				}
+				if secChallenge25 {
+					imgui.SameLine()
+					if imgui.Button("Verify") {
+						verifyText, _ = verifyChallenge25(c25VerifyWorlds)
+						showVerifyWindow = true
+					}
+					imgui.SameLine()
+					HelpMarker("Generate many worlds and compare them with the tables of the original program.")
+				}
+			}
+			if secStatus != "" {
+				imgui.Text(secStatus)
+			}
+			if currentSector != nil {
+				secExport.draw("sectorgen", currentSector)
+			}
+			if currentSector != nil && len(currentSector.polities) > 0 && imgui.TreeNode("Polities") {
+				imgui.ColumnsV(4, "polities", true)
+				for _, p := range currentSector.polities {
+					imgui.Text(p.code)
+					imgui.NextColumn()
+					imgui.Text(p.name)
+					imgui.NextColumn()
+					imgui.Text(p.capital)
+					imgui.NextColumn()
+					imgui.Text(fmt.Sprintf("%d worlds", p.worlds))
+					imgui.NextColumn()
+				}
+				imgui.Columns()
+				imgui.TreePop()
+			}
+			if currentSector != nil && len(currentSector.routes) > 0 && imgui.TreeNode("Routes") {
+				for _, r := range currentSector.routes {
+					imgui.Text(fmt.Sprintf("%s %s-%s %s", r.kind, r.start.String(), r.end.String(), r.allegiance))
+				}
+				imgui.TreePop()
+			}
+			imgui.Separator()
+
+			if currentSector != nil {
+				imgui.BeginChildV("sectorworlds", imgui.Vec2{}, false, imgui.WindowFlagsHorizontalScrollbar)
+				if drawSectorTable(currentSector, &secSortColumn, &secSortAscending) {
+					sortSectorWorlds(currentSector.worlds, secSortColumn, secSortAscending)
+				}
+				imgui.EndChild()
+			}
+			imgui.End()
+		}
+
+		// 9. Show the Challenge #25 verification window
+		if showVerifyWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 300, Y: 80}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+			imgui.SetNextWindowSizeV(imgui.Vec2{X: 420, Y: 600}, imgui.ConditionFirstUseEver)
+
+			imgui.BeginV("Challenge #25 Verification", &showVerifyWindow, 0)
+			imgui.BeginChildV("verifyscroll", imgui.Vec2{}, false, imgui.WindowFlagsHorizontalScrollbar)
+			imgui.Text(verifyText)
+			imgui.EndChild()
+			imgui.End()
+		}
+
+		// 10. Show the Sector Completion window
+		if showCompletionWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 80, Y: 80}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+			imgui.SetNextWindowSizeV(imgui.Vec2{X: 900, Y: 600}, imgui.ConditionFirstUseEver)
+
+			// Start the Sector Completion Window
+			imgui.BeginV("Sector Completion", &showCompletionWindow, 0)
+			imgui.PushItemWidth(200)
+			imgui.InputText("Sector", &compSector)
+			imgui.InputText("Campaign", &compCampaign)
+			imgui.PopItemWidth()
+			if imgui.Button("Load") {
+				var err error
+				if currentCompletion, err = newSectorCompletion(compSector, compCampaign); err != nil {
+					compStatus = fmt.Sprintf("Unable to load %s: %v", compSector, err)
+				} else {
+					compStatus = fmt.Sprintf("Loaded %d worlds", len(currentCompletion.worlds))
+				}
+			}
+			imgui.SameLine()
+			HelpMarker("Load a canonical sector and fill in its missing names, stars, extensions, nobility and capitals.\n" +
+				"Canonical fields are never changed.")
+			if currentCompletion != nil {
+				imgui.SameLine()
+				if imgui.Button("Accept All") {
+					currentCompletion.acceptAll()
+				}
+				imgui.SameLine()
+				if imgui.Button("Save") {
+					currentCompletion.campaign = compCampaign
+					compStatus = currentCompletion.save(config.ForevenFile)
+				}
+				imgui.SameLine()
+				HelpMarker("Save the accepted worlds as a new version of the campaign's overlay for the sector.\n" +
+					"Worlds not accepted are saved as they are in canon.")
+			}
+			if compStatus != "" {
+				imgui.Text(compStatus)
+			}
+			imgui.Separator()
+
+			if currentCompletion != nil {
+				imgui.BeginChildV("completionworlds", imgui.Vec2{}, false, imgui.WindowFlagsHorizontalScrollbar)
+				drawCompletionTable(currentCompletion)
+				imgui.EndChild()
+			}
+			imgui.End()
+		}
+
+		// 11. Show the Import Metadata window
+		if showImportWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 100, Y: 100}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+
+			imgui.BeginV("Import Metadata", &showImportWindow, imgui.WindowFlagsAlwaysAutoResize)
+			imgui.PushItemWidth(400)
+			imgui.InputText("Metadata file", &importFile)
+			imgui.PopItemWidth()
+			if imgui.Button("Import") {
+				importStatus = importMetadataFile(importFile)
+			}
+			imgui.SameLine()
+			HelpMarker("Import the subsector names, allegiances and other names of a sector from a\n" +
+				"Travellermap metadata file, eg SpinwardMarches-metadata.xml.")
+			if importStatus != "" {
+				imgui.Text(importStatus)
+			}
+			imgui.End()
+		}
+
+		// 12. Show the Sector Statistics window
+		if showStatsWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 120, Y: 120}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+			imgui.SetNextWindowSizeV(imgui.Vec2{X: 520, Y: 600}, imgui.ConditionFirstUseEver)
+
+			imgui.BeginV("Sector Statistics", &showStatsWindow, 0)
+			imgui.PushItemWidth(200)
+			imgui.InputText("Sector", &statsSector)
+			imgui.PopItemWidth()
+			if imgui.Button("Load") {
+				var err error
+				if statsLoaded, err = loadSector(statsSector); err != nil {
+					statsStatus = fmt.Sprintf("Unable to load %s: %v", statsSector, err)
+				} else {
+					statsTotal, statsSubsectors = statsLoaded.statistics()
+					statsStatus = fmt.Sprintf("Loaded %d worlds", len(statsLoaded.worlds))
+				}
+			}
+			if currentSector != nil {
+				imgui.SameLine()
+				if imgui.Button("Use Generated") {
+					statsLoaded = currentSector
+					statsTotal, statsSubsectors = statsLoaded.statistics()
+					statsStatus = fmt.Sprintf("Using the generated %s sector", statsLoaded.name)
+				}
+			}
+			imgui.SameLine()
+			HelpMarker("Load a canon sector from the database, or use the sector last generated, and show the\n" +
+				"statistics for the sector or one of its subsectors.")
+			if statsLoaded != nil {
+				imgui.SameLine()
+				if imgui.Button("Export") {
+					statsStatus = statsLoaded.writeStatistics()
+				}
+				imgui.SameLine()
+				HelpMarker("Write the statistics for the sector and every subsector to text and CSV files.")
+			}
+			if statsStatus != "" {
+				imgui.Text(statsStatus)
+			}
+			imgui.Separator()
+
+			if statsTotal != nil {
+				scopes := append([]*sectorStats{statsTotal}, statsSubsectors[:]...)
+				imgui.PushItemWidth(300)
+				if imgui.BeginCombo("Scope", scopes[statsScope].name) {
+					for i, st := range scopes {
+						if imgui.SelectableV(st.name, i == statsScope, 0, imgui.Vec2{}) {
+							statsScope = i
+						}
+					}
+					imgui.EndCombo()
+				}
+				imgui.PopItemWidth()
+				imgui.BeginChildV("statsscroll", imgui.Vec2{}, false, imgui.WindowFlagsHorizontalScrollbar)
+				imgui.Text(scopes[statsScope].text())
+				imgui.EndChild()
+			}
+			imgui.End()
+		}
+
+		// 13. Show the Sector Map window
+		if showHexMapWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 140, Y: 140}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+
+			imgui.BeginV("Sector Map", &showHexMapWindow, imgui.WindowFlagsAlwaysAutoResize)
+			imgui.PushItemWidth(300)
+			imgui.InputText("Sector", &hexMapSector)
+			imgui.SameLine()
+			if imgui.Button("Load") {
+				var err error
+				if hexMapLoaded, err = loadSector(hexMapSector); err != nil {
+					hexMapStatus = fmt.Sprintf("Unable to load %s: %v", hexMapSector, err)
+				} else {
+					hexMapStatus = fmt.Sprintf("Loaded %d worlds", len(hexMapLoaded.worlds))
+				}
+			}
+			imgui.InputText("Sector file", &hexMapFile)
+			imgui.SameLine()
+			if imgui.Button("Read") {
+				var err error
+				var errs []lineError
+				if hexMapLoaded, errs, err = readSectorFile(hexMapFile); err != nil {
+					hexMapStatus = fmt.Sprintf("Unable to read %s: %v", hexMapFile, err)
+				} else {
+					hexMapStatus = fmt.Sprintf("Read %d worlds of %s", len(hexMapLoaded.worlds), hexMapLoaded.name)
+				}
+				if len(errs) > 0 {
+					hexMapStatus += fmt.Sprintf(", skipping %d (%v", len(errs), errs[0])
+					if len(errs) > 1 {
+						hexMapStatus += ", ..."
+					}
+					hexMapStatus += "; see the log)"
+				}
+			}
+			if currentSector != nil {
+				if imgui.Button("Use Generated") {
+					hexMapLoaded = currentSector
+					hexMapStatus = fmt.Sprintf("Using the generated %s sector", hexMapLoaded.name)
+				}
+				imgui.SameLine()
+			}
+			HelpMarker("Load a canon sector from the database, read a sector from a file in any of the export formats\n" +
+				"(with the routes from its metadata file), or use the sector last generated.")
+
+			if imgui.BeginCombo("Scope", hexMapScopeSel.String()) {
+				for _, sc := range []hexMapScope{scopeSector, scopeQuadrant, scopeSubsector} {
+					if imgui.SelectableV(sc.String(), sc == hexMapScopeSel, 0, imgui.Vec2{}) {
+						hexMapScopeSel, hexMapIndex = sc, 0
+					}
+				}
+				imgui.EndCombo()
+			}
+			var parts []string
+			switch hexMapScopeSel {
+			case scopeQuadrant:
+				parts = quadrantNames[:]
+			case scopeSubsector:
+				for i := range ssIndex {
+					name := ssIndex[i]
+					if hexMapLoaded != nil && hexMapLoaded.subsectors[i].name != "" {
+						name += " " + hexMapLoaded.subsectors[i].name
+					}
+					parts = append(parts, name)
+				}
+			}
+			if len(parts) > 0 && imgui.BeginCombo(hexMapScopeSel.String()+" shown", parts[hexMapIndex]) {
+				for i, part := range parts {
+					if imgui.SelectableV(part, i == hexMapIndex, 0, imgui.Vec2{}) {
+						hexMapIndex = i
+					}
+				}
+				imgui.EndCombo()
+			}
+			if imgui.BeginCombo("Style", hexMapStyleSel.String()) {
+				for _, st := range []hexMapStyle{stylePrint, stylePoster, styleAtlas} {
+					if imgui.SelectableV(st.String(), st == hexMapStyleSel, 0, imgui.Vec2{}) {
+						hexMapStyleSel = st
+					}
+				}
+				imgui.EndCombo()
+			}
+			imgui.InputInt("Hex radius", &hexMapRadius)
+			if hexMapRadius < 8 {
+				hexMapRadius = 8
+			}
+			imgui.PopItemWidth()
+
+			if hexMapLoaded != nil {
+				m := newHexMap(hexMapLoaded, hexMapScopeSel, hexMapIndex, hexMapStyleSel, float64(hexMapRadius))
+				for _, ext := range []string{"svg", "png"} {
+					if imgui.Button("Save " + strings.ToUpper(ext)) {
+						if err := m.save(m.filename(ext)); err != nil {
+							log.Printf("Unable to save the map of %s: %v", hexMapLoaded.name, err)
+							hexMapStatus = fmt.Sprintf("Unable to save %s: %v", m.filename(ext), err)
+						} else {
+							hexMapStatus = fmt.Sprintf("Saved %s", m.filename(ext))
+						}
+					}
+					imgui.SameLine()
+				}
+				HelpMarker("Save the map, named after the sector, eg \"Foreven sector map.svg\".")
+				if imgui.Button("Save PDF Booklet") {
+					if err := writeBooklet(m, bookletFilename(m)); err != nil {
+						hexMapStatus = fmt.Sprintf("Unable to save %s: %v", bookletFilename(m), err)
+					} else {
+						hexMapStatus = fmt.Sprintf("Saved %s", bookletFilename(m))
+					}
+				}
+				imgui.SameLine()
+				HelpMarker("Save a printable referee's booklet of the sector, quadrant or subsector shown, with a map,\n" +
+					"world listing and world descriptions for each subsector, and a key to the UWP.")
+				hexMapExport.draw("hexmap", hexMapLoaded)
+			}
+			if hexMapStatus != "" {
+				imgui.Text(hexMapStatus)
+			}
+			imgui.End()
+		}
+
+		// 14. Show the Import Sector File window
+		if showSectorImportWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 140, Y: 140}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+
+			imgui.BeginV("Import Sector File", &showSectorImportWindow, imgui.WindowFlagsAlwaysAutoResize)
+			imgui.PushItemWidth(400)
+			imgui.InputText("Sector file", &sfImportFile)
+			imgui.InputText("Sector", &sfImportSector)
+			imgui.SameLine()
+			HelpMarker("The name or abbreviation of the sector to import the worlds into. If it is empty, the\n" +
+				"sector is found from the Sector column of the file.")
+			imgui.InputText("Columns", &sfImportColumns)
+			imgui.SameLine()
+			HelpMarker("The columns of a file that has no header line, separated by commas. Files with a header\n" +
+				"line, or in a format other than tab delimited, are read by their own columns.")
+			imgui.PopItemWidth()
+			if imgui.Button("Parse") {
+				var err error
+				if sfImport, err = parseImportFile(sfImportFile, sfImportSector, parseImportColumns(sfImportColumns)); err != nil {
+					sfImportStatus = fmt.Sprintf("Unable to parse %s: %v", sfImportFile, err)
+				} else {
+					sfImportStatus = ""
+				}
+			}
+			if sfImport != nil {
+				imgui.SameLine()
+				if imgui.Button("Stage") {
+					if err := sfImport.stage(); err != nil {
+						log.Printf("Unable to stage the worlds from %s: %v", sfImport.filename, err)
+						sfImportStatus = fmt.Sprintf("Unable to stage: %v", err)
+					} else {
+						sfImportStatus = ""
+					}
+				}
+				if sfImport.staged {
+					imgui.SameLine()
+					if imgui.Button("Commit") {
+						sfImportStatus = sfImport.commit(sfImportReplace)
+					}
+					imgui.SameLine()
+					imgui.Checkbox("Replace existing worlds", &sfImportReplace)
+				}
+				imgui.SameLine()
+				HelpMarker("Parse reads and checks the file. Stage saves the valid worlds to the staging table and\n" +
+					"shows the worlds already in their hexes. Commit adds the staged worlds to the sector, keeping\n" +
+					"or replacing the worlds already there.")
+				imgui.Text(sfImport.summary())
+				if len(sfImport.errs) > 0 && imgui.TreeNode(fmt.Sprintf("Errors (%d)", len(sfImport.errs))) {
+					for _, e := range sfImport.errs {
+						imgui.Text(e.Error())
+					}
+					imgui.TreePop()
+				}
+				if len(sfImport.conflicts) > 0 && imgui.TreeNode(fmt.Sprintf("Conflicts (%d)", len(sfImport.conflicts))) {
+					for _, c := range sfImport.conflicts {
+						imgui.Text(c.String())
+					}
+					imgui.TreePop()
+				}
+			}
+			if sfImportStatus != "" {
+				imgui.Text(sfImportStatus)
+			}
+			imgui.End()
+		}
+
+		// 15. Show the HTML Atlas window
+		if showAtlasWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 160, Y: 160}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+
+			imgui.BeginV("HTML Atlas", &showAtlasWindow, imgui.WindowFlagsAlwaysAutoResize)
+			imgui.PushItemWidth(300)
+			imgui.InputText("Campaign", &atlasCampaign)
+			imgui.InputText("Sectors", &atlasSectors)
+			imgui.SameLine()
+			HelpMarker("The sectors in the atlas, separated by commas. The latest version of the campaign's\n" +
+				"overlay is applied to each sector that has one.")
+			imgui.InputText("Directory", &atlasDir)
+			imgui.InputText("Notes directory", &atlasNotesDir)
+			imgui.SameLine()
+			HelpMarker("A directory of the referee's notes for the worlds, one text file per world named after\n" +
+				"its sector and hex, eg \"Foreven 0101.txt\". Leave empty for no notes.")
+			if imgui.BeginCombo("Style", atlasStyleSel.String()) {
+				for _, st := range []hexMapStyle{stylePrint, stylePoster, styleAtlas} {
+					if imgui.SelectableV(st.String(), st == atlasStyleSel, 0, imgui.Vec2{}) {
+						atlasStyleSel = st
+					}
+				}
+				imgui.EndCombo()
+			}
+			imgui.PopItemWidth()
+			if imgui.Button("Export") {
+				n, worlds, err := writeAtlas(atlasOptions{Campaign: atlasCampaign, Sectors: strings.Split(atlasSectors, ","),
+					Dir: atlasDir, NotesDir: atlasNotesDir, Style: atlasStyleSel})
+				switch {
+				case n == 0:
+					atlasStatus = fmt.Sprintf("Unable to write the atlas: %v", err)
+				case err != nil:
+					atlasStatus = fmt.Sprintf("Written %d sectors and %d worlds to %s, with %v", n, worlds, atlasDir, err)
+				default:
+					atlasStatus = fmt.Sprintf("Written %d sectors and %d worlds to %s", n, worlds, atlasDir)
+				}
+			}
+			imgui.SameLine()
+			HelpMarker("Write a static web site of the sectors, with subsector maps, a page for each world and a\n" +
+				"search box, that can be browsed offline. Open index.html in the directory to start.")
+			if atlasStatus != "" {
+				imgui.Text(atlasStatus)
+			}
+			imgui.End()
+		}
+
+		// 16. Show the World Handouts window
+		if showHandoutWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 180, Y: 180}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+
+			imgui.BeginV("World Handouts", &showHandoutWindow, imgui.WindowFlagsAlwaysAutoResize)
+			imgui.PushItemWidth(300)
+			imgui.InputText("Sector", &handoutSector)
+			imgui.InputText("Hex", &handoutHex)
+			imgui.InputText("Name", &handoutName)
+			imgui.SameLine()
+			HelpMarker("The name of a freshly generated world. Worlds loaded from the database keep their names.")
+			imgui.PopItemWidth()
+			if imgui.Button("Load") {
+				w, err := findSectorWorld(handoutSector, handoutHex)
+				if err != nil {
+					handoutWorld, handoutStatus = nil, fmt.Sprintf("Unable to load the world: %v", err)
+				} else {
+					handoutWorld, handoutStatus = w, ""
+				}
+			}
+			imgui.SameLine()
+			if imgui.Button("Generate") {
+				w := generateT5World(tools.RNG(), handoutName, handoutHex, handoutSector, "")
+				if w.genType == WgtInvalid {
+					handoutWorld, handoutStatus = nil, fmt.Sprintf("Unable to generate a world: %v %q", ErrInvalidHex, handoutHex)
+				} else {
+					handoutWorld, handoutStatus = &w, ""
+				}
+			}
+			imgui.SameLine()
+			HelpMarker("Load the world in the hex from the database, with the campaign's overlay applied, or\n" +
+				"generate a new world for the hex. Generated worlds are not saved.")
+			if handoutWorld != nil {
+				imgui.Text(fmt.Sprintf("%s %s %s %s", handoutWorld.hexLoc.String(), handoutWorld.name,
+					handoutWorld.uwp.String(), handoutWorld.remarks))
+				imgui.PushItemWidth(300)
+				imgui.InputText("Directory", &handoutDir)
+				imgui.InputText("Notes directory", &handoutNotesDir)
+				imgui.SameLine()
+				HelpMarker("A directory of the referee's notes for the worlds, one text file per world named after\n" +
+					"its sector and hex, eg \"Foreven 0101.txt\". Leave empty for no notes.")
+				imgui.PopItemWidth()
+				if imgui.Button("Write Handouts") {
+					if err := writeHandouts(handoutWorld, handoutSector, handoutDir, handoutNotesDir); err != nil {
+						handoutStatus = fmt.Sprintf("Unable to write the handouts: %v", err)
+					} else {
+						handoutStatus = fmt.Sprintf("Written the handouts for %s to %s", handoutWorld.name, handoutDir)
+					}
+				}
+				imgui.SameLine()
+				HelpMarker("Write the player handout and referee sheet for the world, each as Markdown, HTML and PDF.")
+			}
+			if imgui.Button("Write Default Templates") {
+				handoutStatus = writeDefaultHandoutTemplates()
+			}
+			imgui.SameLine()
+			HelpMarker("Write the default templates to " + handoutTemplateDir() + " to be customised. The\n" +
+				"templates there, player.md, referee.md, player.html and referee.html, are used instead of\n" +
+				"the defaults. The PDF sheets are laid out from the Markdown.")
+			if handoutStatus != "" {
+				imgui.Text(handoutStatus)
+			}
+			imgui.End()
+		}
+
+		// 17. Show the Search window
+		if showSearchWindow {
+
+			imgui.SetNextWindowPosV(imgui.Vec2{X: 200, Y: 200}, imgui.ConditionFirstUseEver, imgui.Vec2{})
+
+			imgui.BeginV("Search", &showSearchWindow, imgui.WindowFlagsAlwaysAutoResize)
+			imgui.PushItemWidth(300)
+			enter := imgui.InputTextV("##search", &searchText, imgui.InputTextFlagsEnterReturnsTrue, nil)
+			imgui.PopItemWidth()
+			imgui.SameLine()
+			if imgui.Button("Search") || enter {
+				var err error
+				if searchResults, err = searchWorlds(context.Background(), searchText, searchLimit); err != nil {
+					searchStatus = fmt.Sprintf("Unable to search: %v", err)
+				} else {
+					searchStatus = fmt.Sprintf("%d worlds found", len(searchResults))
+				}
+			}
+			imgui.SameLine()
+			HelpMarker("Search the names, sectors, remarks, sophonts and notes of all the worlds. Every word is\n" +
+				"searched for as the start of a word. Put phrases in double quotes, and limit a word to a field\n" +
+				"with name:, sector:, alt:, remarks:, sophont: or notes:, eg sophont:vargr. If few worlds are\n" +
+				"found, words spelt alike are searched for too, and those worlds are marked with a ~.")
+			imgui.SameLine()
+			if imgui.Button("Rebuild Index") {
+				if n, err := rebuildSearchIndex(context.Background(), config.Campaign, config.NotesDir); err != nil {
+					searchStatus = fmt.Sprintf("Unable to rebuild the index: %v", err)
+				} else {
+					searchStatus = fmt.Sprintf("Indexed %d worlds", n)
+				}
+			}
+			imgui.SameLine()
+			HelpMarker("Rebuild the search index, after changing notes or a campaign's overlays. The index is\n" +
+				"rebuilt by itself when worlds are added to or removed from the database.")
+			if searchStatus != "" {
+				imgui.Text(searchStatus)
+			}
+			if len(searchResults) > 0 {
+				imgui.BeginChildV("results", imgui.Vec2{X: 640, Y: 300}, true, 0)
+				for _, r := range searchResults {
+					imgui.Text(r.String())
+				}
+				imgui.EndChild()
+				ws := make([]world, len(searchResults))
+				for i, r := range searchResults {
+					ws[i] = r.world
+				}
+				searchExport.draw("search", &sector{id: -1, name: "Search results", worlds: ws})
+			}
+			imgui.End()
+		}
+
 		// For not implemented features
 		if doNotImplementedPopup {
 			imgui.OpenPopup("Not Implemented")
@@ -348,7 +1214,7 @@ func getLanguageWord(lidx int, showPattern bool) (retValue string) {
 
 	lang := Language(lidx)
 
-	retValue, pattern := lang.GenWord()
+	retValue, pattern := lang.GenWord(tools.RNG())
 
 	if showPattern {
 		retValue += " - [" + lang.String() + "] - (" + pattern + ")"
@@ -357,6 +1223,227 @@ func getLanguageWord(lidx int, showPattern bool) (retValue string) {
 	return
 }
 
+// jumpCalculation works out the jump point for a world of the UWP size in the orbit around the star, and the
+// travel times from that orbit to another. It returns the results for display.
+func jumpCalculation(star, uwp string, orbit, toOrbit float64) string {
+	starDiameter, err := starDiameterKm(star)
+	if err != nil {
+		return fmt.Sprintf("Unable to find star %s: %v", star, err)
+	}
+	jp := calculateJumpPoint(bodyDiameterKm(wtMainworld, uwp), orbitalDistanceKm(orbit), starDiameter)
+	str := fmt.Sprintf("Star diameter %.0f km, 100D limit %.0f km\nOrbit %.1f is %.0f km (%.2f AU)\n\n%s\n",
+		starDiameter, starDiameter*jumpDiameters, orbit, orbitalDistanceKm(orbit), orbitToAU(orbit), jp.String())
+
+	shortest, longest := orbitTravelDistances(orbit, toOrbit)
+	str += fmt.Sprintf("Orbit %.1f to orbit %.1f: %.0f km to %.0f km\n", orbit, toOrbit, shortest, longest)
+	for g := 1; g <= maxDriveG; g++ {
+		str += fmt.Sprintf("%dG: %s to %s\n", g, formatTravelTime(travelTime(shortest, float64(g))),
+			formatTravelTime(travelTime(longest, float64(g))))
+	}
+	return str
+}
+
+// exportWorldMap saves the world map to the working directory in the given format (png or svg).
+// It returns a status message for display.
+func exportWorldMap(m *worldMap, ext string) string {
+	filename := worldMapFilename(m.Name, m.UWP, ext)
+	if err := m.saveWorldMap(filename, 120); err != nil {
+		log.Printf("Unable to export world map: %v", err)
+		return fmt.Sprintf("Unable to export %s", filename)
+	}
+	log.Printf("World map exported to %s", filename)
+	return fmt.Sprintf("Exported %s", filename)
+}
+
+// worldMapDetails returns the details of the world shown alongside its map.
+func worldMapDetails(m *worldMap) string {
+	u := parseUwp(m.UWP)
+	return fmt.Sprintf("%s\n%s\n\nStarport: %s\nSize: %d km\nAtmosphere: %s\nHydrographics: %d%%\n"+
+		"Population: %s\nGovernment: %s\nLaw Level: %s\nTech Level: %s\n\n%s",
+		m.Name, m.UWP, tableStarport(u.starport), u.sizeInt*1600, Ehex(u.atmInt).String(), u.hydInt*10,
+		Ehex(u.popInt).String(), Ehex(u.govInt).String(), Ehex(u.lawInt).String(), Ehex(u.techInt).String(),
+		climateFromHabZoneVar(u, 0).String())
+}
+
+// drawWorldMap draws the world map in the current window, with triangle sides of scale pixels.
+func drawWorldMap(m *worldMap, scale float32) {
+	origin := imgui.CursorScreenPos()
+	dl := imgui.WindowDrawList()
+	pt := func(p mapPoint) imgui.Vec2 {
+		return imgui.Vec2{X: origin.X + float32(p.X)*scale, Y: origin.Y + float32(p.Y)*scale}
+	}
+	for _, t := range m.Triangles {
+		dl.AddTriangleFilled(pt(t.P[0]), pt(t.P[1]), pt(t.P[2]), packedColour(terrainColours[t.Terrain]))
+	}
+	if m.Starport >= 0 {
+		c := pt(m.Triangles[m.Starport].centre())
+		size := scale / 20
+		dl.AddRectFilled(imgui.Vec2{X: c.X - size, Y: c.Y - size}, imgui.Vec2{X: c.X + size, Y: c.Y + size}, packedColour(starportColour))
+	}
+	w, h := m.bounds()
+	imgui.Dummy(imgui.Vec2{X: float32(w) * scale, Y: float32(h) * scale})
+}
+
+// exportPanel holds the state of an "Export as" control, which writes a sector or list of worlds to a file in one of
+// the registered formats.
+type exportPanel struct {
+	format   int    // The index of the chosen format in sectorFormats
+	filename string // The file to write, named after the sector until the user changes it
+	sector   string // The name of the sector the file was named after
+	status   string // The result of the last export
+}
+
+// draw draws the control for exporting the sector. The id keeps the control's widgets apart from others in the
+// same window.
+func (e *exportPanel) draw(id string, s *sector) {
+	f := sectorFormats[e.format]
+	if e.filename == "" || e.sector != s.name {
+		e.sector = s.name
+		e.filename = strings.TrimSuffix(sectorFilename(s.name), ".tab") + f.extensions[0]
+	}
+	imgui.PushItemWidth(200)
+	if imgui.BeginCombo("Export as##"+id, f.name) {
+		for i, name := range formatNames() {
+			if imgui.SelectableV(name, i == e.format, 0, imgui.Vec2{}) {
+				e.format = i
+				e.filename = strings.TrimSuffix(e.filename, filepath.Ext(e.filename)) + sectorFormats[i].extensions[0]
+			}
+		}
+		imgui.EndCombo()
+	}
+	imgui.SameLine()
+	imgui.InputText("##file"+id, &e.filename)
+	imgui.PopItemWidth()
+	imgui.SameLine()
+	if imgui.Button("Export##" + id) {
+		if err := writeSectorFile(s, e.filename); err != nil {
+			e.status = fmt.Sprintf("Unable to export %s: %v", e.filename, err)
+		} else {
+			e.status = fmt.Sprintf("Exported %d worlds to %s", len(s.worlds), e.filename)
+		}
+	}
+	imgui.SameLine()
+	HelpMarker("Write the worlds to a file in the chosen format. The format of the file is chosen by its extension.")
+	if e.status != "" {
+		imgui.Text(e.status)
+	}
+}
+
+// drawSectorTable draws the worlds of the sector as a table, grouped by subsector. Clicking on a column header
+// sorts on that column, and clicking it again reverses the order. It returns true if the sort order has changed.
+func drawSectorTable(s *sector, sortColumn *int, ascending *bool) (changed bool) {
+	for i := 0; i < len(s.worlds); {
+		// Find the worlds in this subsector.
+		idx := s.worlds[i].subsectorIndex
+		j := i
+		for j < len(s.worlds) && s.worlds[j].subsectorIndex == idx {
+			j++
+		}
+		label := fmt.Sprintf("Subsector %s", idx)
+		if s.worlds[i].subsector != "" {
+			label += " - " + s.worlds[i].subsector
+		}
+		if imgui.CollapsingHeader(fmt.Sprintf("%s (%d worlds)###ss%s", label, j-i, idx)) {
+			if ss := s.worlds[i].hexLoc.IntIndex(); ss >= 0 {
+				imgui.Checkbox("Keep subsector name##"+idx, &s.lockedSubsectors[ss])
+				imgui.SameLine()
+				imgui.Text(s.subsectors[ss].language)
+			}
+			imgui.ColumnsV(len(sectorColumns), "sector"+idx, true)
+			for c, name := range sectorColumns {
+				if c == *sortColumn {
+					if *ascending {
+						name += " ^"
+					} else {
+						name += " v"
+					}
+				}
+				if imgui.SelectableV(name+"##"+idx, c == *sortColumn, 0, imgui.Vec2{}) {
+					if c == *sortColumn {
+						*ascending = !*ascending
+					} else {
+						*sortColumn, *ascending = c, true
+					}
+					changed = true
+				}
+				imgui.NextColumn()
+			}
+			imgui.Separator()
+			for k := i; k < j; k++ {
+				for c := range sectorColumns {
+					if c == 1 {
+						// The name can be ticked to keep it when the sector is renamed.
+						hex := s.worlds[k].hexLoc.String()
+						locked := s.lockedNames[hex]
+						if imgui.Checkbox(s.worlds[k].name+"##"+hex, &locked) {
+							s.lockedNames[hex] = locked
+						}
+					} else {
+						imgui.Text(sectorColumnValue(&s.worlds[k], c))
+					}
+					imgui.NextColumn()
+				}
+			}
+			imgui.Columns()
+		}
+		i = j
+	}
+	return
+}
+
+// completionColumns are the columns shown for a sector being completed.
+var completionColumns = [...]string{"Accept", "Hex", "Name", "UWP", "Remarks", "Stars", "Ix", "Ex", "Cx", "Nobility", "Filled In", ""}
+
+// drawCompletionTable draws the worlds of a sector being completed, grouped by subsector, with a checkbox to accept
+// each world and a button to reroll its missing fields. Worlds with nothing missing are not shown.
+func drawCompletionTable(c *sectorCompletion) {
+	for ss := range ssIndex {
+		var idxs []int
+		for i, cw := range c.worlds {
+			if cw.canon.hexLoc.IntIndex() == ss && cw.missing != 0 {
+				idxs = append(idxs, i)
+			}
+		}
+		if len(idxs) == 0 {
+			continue
+		}
+		label := fmt.Sprintf("Subsector %s", ssIndex[ss])
+		if c.sector.subsectors[ss].name != "" {
+			label += " - " + c.sector.subsectors[ss].name
+		}
+		if imgui.CollapsingHeader(fmt.Sprintf("%s (%d worlds)###css%s", label, len(idxs), ssIndex[ss])) {
+			imgui.ColumnsV(len(completionColumns), "completion"+ssIndex[ss], true)
+			for _, name := range completionColumns {
+				imgui.Text(name)
+				imgui.NextColumn()
+			}
+			imgui.Separator()
+			for _, i := range idxs {
+				cw := &c.worlds[i]
+				w := &cw.world
+				hex := w.hexLoc.String()
+				imgui.Checkbox("##accept"+hex, &cw.accepted)
+				imgui.NextColumn()
+				for _, v := range []string{hex, w.name, w.uwp.String(), w.remarks, w.systemStarString(),
+					w.importance.String(), w.economics.String(), w.culture.String(), w.nobility, cw.missing.String()} {
+					imgui.Text(v)
+					imgui.NextColumn()
+				}
+				if imgui.Button("Reroll##" + hex) {
+					c.reroll(i)
+				}
+				imgui.NextColumn()
+			}
+			imgui.Columns()
+		}
+	}
+}
+
+// packedColour converts a colour to the packed form used by imgui.
+func packedColour(c color.RGBA) imgui.PackedColor {
+	return imgui.PackedColorFromVec4(imgui.Vec4{X: float32(c.R) / 255, Y: float32(c.G) / 255, Z: float32(c.B) / 255, W: float32(c.A) / 255})
+}
+
 // HelpMarker displaya a little (?) mark which shows a tooltip when hovered.
 // In your own code you may want to display an actual icon if you are using a merged icon fonts (see docs/FONTS.md)
 func HelpMarker(desc string) {
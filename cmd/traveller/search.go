@@ -0,0 +1,381 @@
+package main
+
+// search.go contains the world search, which uses an SQLite FTS5 full-text index (the world_search table) of the
+// worlds' names, their sectors' names and other names, remarks, sophonts and the referee's notes. Every term is
+// searched for as a prefix, and the results are ranked with the names counting most. Names are also indexed folded
+// to letters and digits, so "Barkakr" finds Bar'kakr. If too few worlds are found, each term is widened to the terms
+// in the index that are a letter or two from it or that are spelt alike, to allow for typos and the many ways alien
+// names are spelt. The FTS5 module must be built into the sqlite3 driver, with "go build -tags sqlite_fts5".
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// searchLimit is the most worlds returned by a search.
+const searchLimit = 200
+
+// searchEntry is a world's row in the world_search table.
+type searchEntry struct {
+	worldID  int
+	name     string // The world's name, or the name given to it by the campaign's overlay
+	folded   string // The name folded to lower case letters and digits
+	sector   string // The sector's name and abbreviation
+	altNames string // The sector's other names
+	remarks  string
+	sophonts string // The names of the sophonts in the remarks
+	notes    string // The referee's notes on the world
+}
+
+// searchResult is a world found by a search.
+type searchResult struct {
+	world world
+	fuzzy bool // Whether the world was only found by widening the terms
+}
+
+// sophontCodes are the names of the sophonts for the four letter codes used in T5 remarks, eg "Varg4" for a
+// population of 40% Vargr, or "VargW" for a Vargr homeworld.
+var sophontCodes = map[string]string{
+	"Asla": "Aslan", "Bwap": "Bwap", "Chir": "Chirper", "Darr": "Darrian", "Dary": "Daryen", "Dolp": "Dolphin",
+	"Droy": "Droyne", "Flor": "Floriani", "Geon": "Geonee", "Hive": "Hiver", "Huma": "Human", "Ithk": "Ithklur",
+	"Jonk": "Jonkeereen", "K'kr": "K'kree", "Llel": "Llellewyloly", "Orca": "Orca", "Sold": "Solomani", "Suer": "Suerrat",
+	"Syle": "Sylean", "Varg": "Vargr", "Vega": "Vegan", "Vila": "Vilani", "Zhod": "Zhodani",
+}
+
+// searchColumns are the columns of world_search that a term can be limited to, eg "sophont:vargr".
+var searchColumns = map[string]string{
+	"name": "name", "sector": "sector", "alt": "altnames", "altname": "altnames", "remarks": "remarks",
+	"sophont": "sophonts", "sophonts": "sophonts", "notes": "notes",
+}
+
+// searchVocab caches the terms in the index, for the fuzzy search. It is cleared when the index is rebuilt.
+var searchVocab struct {
+	sync.Mutex
+	terms map[string]int
+}
+
+// foldName folds the text to lower case letters and digits, dropping everything else.
+func foldName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// alienFold folds the text further than foldName, so that names spelt alike fold the same: doubled letters are
+// single, "ph" is "f", "c" and "q" are "k" and "y" is "i".
+func alienFold(s string) string {
+	s = strings.NewReplacer("ph", "f", "c", "k", "q", "k", "y", "i").Replace(foldName(s))
+	var b strings.Builder
+	var last rune
+	for _, r := range s {
+		if r != last {
+			b.WriteRune(r)
+		}
+		last = r
+	}
+	return b.String()
+}
+
+// sophontsFromRemarks returns the names of the sophonts in the remarks: the four letter codes with population
+// digits, and the homeworld remarks such as "(Aslan)" and "[Solomani]".
+func sophontsFromRemarks(remarks string) string {
+	var names []string
+	add := func(name string) {
+		for _, n := range names {
+			if n == name {
+				return
+			}
+		}
+		names = append(names, name)
+	}
+	for _, r := range strings.Fields(remarks) {
+		switch {
+		case strings.HasPrefix(r, "(") || strings.HasPrefix(r, "[") || strings.HasPrefix(r, "Di("):
+			name := strings.Trim(strings.TrimPrefix(r, "Di"), "()[]0123456789")
+			if name != "" && name != "minor" {
+				add(name)
+			}
+		case len(r) == 5 && strings.ContainsRune("0123456789W", rune(r[4])):
+			if name, ok := sophontCodes[r[:4]]; ok {
+				add(name)
+			}
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// readAllNotes reads the referee's notes in the directory, keyed by the names of their files without the ".txt",
+// eg "Foreven 0101".
+func readAllNotes(dir string) map[string]string {
+	notes := make(map[string]string)
+	if dir == "" {
+		return notes
+	}
+	fs, err := os.ReadDir(dir)
+	if err != nil {
+		return notes
+	}
+	for _, f := range fs {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".txt" {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(dir, f.Name())); err == nil {
+			notes[strings.TrimSuffix(f.Name(), ".txt")] = string(data)
+		}
+	}
+	return notes
+}
+
+// rebuildSearchIndex rebuilds the world_search table from every world of the OTU in the database, using the names
+// given by the latest versions of the campaign's overlays and the referee's notes in notesDir. Generated sectors are
+// left out, so they are never mixed with the canon. It returns the number of worlds indexed.
+func rebuildSearchIndex(ctx context.Context, campaign, notesDir string) (int, error) {
+	if err := repo.createSearchIndex(ctx); err != nil {
+		return 0, err
+	}
+	ds, err := repo.getOTUWorldDtos(ctx)
+	if err != nil {
+		return 0, err
+	}
+	altNames, err := repo.getAllSectorAltNames(ctx)
+	if err != nil {
+		return 0, err
+	}
+	overlayNames, err := repo.getOverlayNames(ctx, campaign)
+	if err != nil {
+		return 0, err
+	}
+	notes := readAllNotes(notesDir)
+
+	es := make([]searchEntry, 0, len(ds))
+	for _, d := range ds {
+		name := d.name
+		if n, ok := overlayNames[d.id]; ok {
+			name = n
+		}
+		es = append(es, searchEntry{worldID: d.id, name: name, folded: foldName(name),
+			sector: d.sector + " " + d.sectorNameAbbr, altNames: strings.Join(altNames[d.sectorID], " "), remarks: d.remarks,
+			sophonts: sophontsFromRemarks(d.remarks), notes: notes[d.sector+" "+d.hexLoc]})
+	}
+	if err = repo.replaceSearchIndex(ctx, campaign, es); err != nil {
+		return 0, err
+	}
+	searchVocab.Lock()
+	searchVocab.terms = nil
+	searchVocab.Unlock()
+	log.Printf("Indexed %d worlds for searching", len(es))
+	return len(es), nil
+}
+
+// ensureSearchIndex creates the search index if it is not in the database, and rebuilds it if it was built for
+// another campaign or the worlds, sectors or overlays have changed since it was built.
+func ensureSearchIndex(ctx context.Context) error {
+	if err := repo.createSearchIndex(ctx); err != nil {
+		return err
+	}
+	current, err := repo.searchIndexCurrent(ctx, config.Campaign)
+	if err != nil || current {
+		return err
+	}
+	_, err = rebuildSearchIndex(ctx, config.Campaign, config.NotesDir)
+	return err
+}
+
+// searchTerm is a term of a search, limited to the columns if there are any.
+type searchTerm struct {
+	columns []string
+	text    string
+}
+
+// parseSearch splits the search into its terms. Text in double quotes is one term, and a term can be limited to a
+// column by starting it with the column's name and a colon, eg "sector:foreven".
+func parseSearch(s string) (ts []searchTerm) {
+	var fields []string
+	for i, part := range strings.Split(s, `"`) {
+		if i%2 == 1 {
+			fields = append(fields, part)
+		} else {
+			fields = append(fields, strings.Fields(part)...)
+		}
+	}
+	for _, f := range fields {
+		t := searchTerm{text: f}
+		if i := strings.Index(f, ":"); i > 0 {
+			if col, ok := searchColumns[strings.ToLower(f[:i])]; ok {
+				t.columns, t.text = []string{col}, f[i+1:]
+			}
+		}
+		if foldName(t.text) != "" {
+			ts = append(ts, t)
+		}
+	}
+	return
+}
+
+// ftsPhrase quotes the text as an FTS5 phrase.
+func ftsPhrase(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// ftsQuery builds the FTS5 query for the terms, all of which must match. Each term matches as a prefix, or as a
+// prefix of a folded name, or as any of its widened terms.
+func ftsQuery(ts []searchTerm, widened [][]string) string {
+	clauses := make([]string, len(ts))
+	for i, t := range ts {
+		filter := ""
+		if len(t.columns) > 0 {
+			filter = "{" + strings.Join(t.columns, " ") + "} : "
+		}
+		alts := []string{filter + ftsPhrase(t.text) + "*"}
+		if len(t.columns) == 0 || t.columns[0] == "name" {
+			alts = append(alts, "folded : "+ftsPhrase(foldName(t.text))+"*")
+		}
+		if widened != nil {
+			for _, w := range widened[i] {
+				alts = append(alts, filter+ftsPhrase(w))
+			}
+		}
+		clauses[i] = "(" + strings.Join(alts, " OR ") + ")"
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// widenTerm returns the terms in the index that are close to the text: spelt alike, or within a letter (two letters
+// for terms of six or more) of it or of its start. The most common are returned first, up to ten of them.
+func widenTerm(text string, vocab map[string]int) []string {
+	f := foldName(text)
+	n := len([]rune(f))
+	if n < 3 {
+		return nil
+	}
+	maxEdits := 1
+	if n > 5 {
+		maxEdits = 2
+	}
+	af := alienFold(f)
+	var ws []string
+	for t := range vocab {
+		rt := []rune(t)
+		if len(rt) < n-maxEdits {
+			continue
+		}
+		start := t
+		if len(rt) > n+maxEdits {
+			start = string(rt[:n])
+		}
+		if alienFold(t) == af || nameDistance(f, start) <= maxEdits {
+			ws = append(ws, t)
+		}
+	}
+	sort.Slice(ws, func(i, j int) bool {
+		if vocab[ws[i]] != vocab[ws[j]] {
+			return vocab[ws[i]] > vocab[ws[j]]
+		}
+		return ws[i] < ws[j]
+	})
+	if len(ws) > 10 {
+		ws = ws[:10]
+	}
+	return ws
+}
+
+// searchWorlds searches for the worlds matching the search, best first. Worlds matching every term are returned
+// first, then, if there are fewer than the limit, worlds found by widening the terms.
+func searchWorlds(ctx context.Context, search string, limit int) (rs []searchResult, e error) {
+	ts := parseSearch(search)
+	if len(ts) == 0 {
+		return nil, ErrEmptySearch
+	}
+	if e = ensureSearchIndex(ctx); e != nil {
+		return nil, e
+	}
+	found := make(map[int]bool)
+	add := func(match string, fuzzy bool) error {
+		ds, names, err := repo.searchWorldDtos(ctx, match, limit)
+		if err != nil {
+			return err
+		}
+		for i, d := range ds {
+			if found[d.id] || len(rs) >= limit {
+				continue
+			}
+			found[d.id] = true
+			w := d.convertToWorld()
+			w.name = names[i]
+			rs = append(rs, searchResult{world: w, fuzzy: fuzzy})
+		}
+		return nil
+	}
+	if e = add(ftsQuery(ts, nil), false); e != nil || len(rs) >= limit {
+		return rs, e
+	}
+
+	searchVocab.Lock()
+	if searchVocab.terms == nil {
+		if searchVocab.terms, e = repo.getSearchTerms(ctx); e != nil {
+			searchVocab.Unlock()
+			return rs, e
+		}
+	}
+	vocab := searchVocab.terms
+	searchVocab.Unlock()
+
+	widened := make([][]string, len(ts))
+	for i, t := range ts {
+		widened[i] = widenTerm(t.text, vocab)
+	}
+	e = add(ftsQuery(ts, widened), true)
+	return rs, e
+}
+
+// String describes the result in one line, eg "Foreven 0101 Regina A788899-C Ri Pa Ph", with a "~" before worlds
+// found by widening the terms.
+func (r searchResult) String() string {
+	mark := " "
+	if r.fuzzy {
+		mark = "~"
+	}
+	w := r.world
+	return fmt.Sprintf("%s%s %s %s %s %s", mark, w.sector, w.hexLoc.String(), w.name, w.uwp.String(), w.remarks)
+}
+
+// printSearch searches for worlds from the command line and prints them.
+func printSearch(search string) error {
+	rs, err := searchWorlds(context.Background(), search, searchLimit)
+	if err != nil {
+		return err
+	}
+	for _, r := range rs {
+		fmt.Println(r.String())
+	}
+	fmt.Printf("%d worlds found\n", len(rs))
+	return nil
+}
+
+// exportSearch searches for worlds from the command line and writes them to the file, in the format chosen by its
+// extension.
+func exportSearch(search, filename string) error {
+	rs, err := searchWorlds(context.Background(), search, searchLimit)
+	if err != nil {
+		return err
+	}
+	ws := make([]world, len(rs))
+	for i, r := range rs {
+		ws[i] = r.world
+	}
+	if err = exportWorlds("Search results", ws, filename); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d worlds found to %s\n", len(ws), filename)
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"trav2/cmd/traveller/metadata"
+)
+
+// sector.go contains code for sectors and subsectors.
+
+// sectorDTO stores details about a system mapping sector in the database.
+type sectorDTO struct {
+	id     int    // The Sector's ID from the database.
+	name   string // The (official) name of the Sector.
+	abbrev string // A four-letter abbreviation for the Sector (usually first 4 chars of the name).
+	xLoc   int    // The travellermap.com x offset from Core sector.
+	yLoc   int    // The travellermap.com y offset from Core sector.
+	milieu string // The abbreviation of the sector's milieu, eg "M1105".
+	tags   string // The sector's tags, eg "Official OTU".
+	otu    bool   // Whether the sector is part of the Official Traveller Universe, rather than generated.
+}
+
+// subsector stores details about a subsector, which can contain up to 80 systems. There are 16 subsectors to  sector in a 4x4 grid.
+type subsector struct {
+	id        int    // The subsector's ID from the database.
+	name      string // The Subsector's name.
+	remarks   string // Any remarks for the subsector.
+	language  string // The majority language and language used to name the Subsector.
+	capitalID int    // The ID of the mainworld that is the subsector capital.
+}
+
+// subsectorDTO is used for collecting subsector information from the database.
+type subsectorDTO struct {
+	id             int    // The subsector's ID from the database.
+	name           string // The Subsector's name
+	sectorID       int    // The database ID of the Sector containing this Subsector.
+	subsectorIndex string // The "index" (A through P) of the subsector within the sector. See map.
+	remarks        string // Any remarks for the Subsector.s
+	langID         int    // The databse ID of the majority language that is used in the Subsector. This will be the language that the Subsector name is in.
+	capitalID      int    // The database ID of the mainworld that is the subsector capital if any.
+}
+
+// sector contains an ordered collection of worlds in a grid.
+type sector struct {
+	id         int           // The Sector's ID from the database if it is from the OTU, or -1 if not.
+	name       string        // The name of the sector
+	abbrev     string        // The four-letter abbreviation for the sector (usually first 4 characters of the name).
+	worlds     []world       // The list of worlds
+	saved      bool          // If the sector has been saved.
+	subsectors [16]subsector // The subsectors (in order from A to P) for this sector if known.
+	otu        bool          // Whether the sector belongs to the "Official Traveller Universe"
+
+	lockedNames      map[string]bool // The hexes of the worlds whose names the user has accepted, and are kept on renaming
+	lockedSubsectors [16]bool        // The subsectors whose names the user has accepted, and are kept on renaming
+	polities         []polity        // The polities grown in a generated sector, whose codes are the worlds' allegiances
+	routes           []route         // The xboat and trade routes between the worlds (see generateRoutes)
+}
+
+// getAbbreviationForSector gets the abbreviation for a Sector. If it finds it in the
+// database it uses that, if not, it uses the first four characters of the sector name.
+func getAbbreviationForSector(s string) (a string) {
+
+	if len(s) == 0 {
+		return ""
+	}
+
+	sectorMap, _ := repo.getAllDetailedSectorAbbrev(context.Background())
+	a = sectorMap[s]
+
+	if len(a) == 0 {
+		if len(s) >= 4 {
+			a = s[0:4]
+		} else {
+			a = s
+		}
+	}
+	return
+}
+
+// loadSector loads the named sector, canonical or generated, from the database, with its worlds and subsectors. It
+// returns ErrSectorNotFound if the sector has no worlds in the database.
+func loadSector(name string) (*sector, error) {
+	ds, err := repo.getWorldDtosBySector(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	if len(ds) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSectorNotFound, name)
+	}
+	sd, err := repo.getSectorByName(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	s := &sector{id: ds[0].sectorID, name: ds[0].sector, abbrev: ds[0].sectorNameAbbr, saved: true, otu: sd.otu}
+	for _, d := range ds {
+		s.worlds = append(s.worlds, d.convertToWorld())
+	}
+	if s.subsectors, err = repo.getSubsectorsForSector(context.Background(), name); err != nil {
+		log.Printf("Unable to get subsectors for %s: %v", name, err)
+	}
+	return s, nil
+}
+
+// sectorFilename returns the name of the tab file for the named sector, eg "Foreven.tab".
+func sectorFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = "Unknown"
+	}
+	return name + ".tab"
+}
+
+/* ssIndex[16] is the definitive map from array to string. */
+
+// // subsectorIndex returns the string for the given index.
+// func subsectorIndex(idx int) string {
+// 	return [...]string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M", "N", "O", "P"}
+// }
+
+// readSectorFile reads a sector from a file in any of the registered formats, such as one written by
+// writeSectorFile or downloaded from travellermap.com. The sector is named after the file, eg "Foreven.tab" is
+// "Foreven", unless the file holds its name. Subsector names are taken from the database if the sector is there,
+// and routes from the Travellermap metadata file next to the sector file if there is one. The lines that could not
+// be read are returned with the sector.
+func readSectorFile(fn string) (*sector, []lineError, error) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, errs, err := readSector(data, fn)
+	if err != nil {
+		return nil, errs, err
+	}
+
+	if ss, err := repo.getSubsectorsForSector(context.Background(), s.name); err == nil {
+		s.subsectors = ss
+	}
+	if m, err := metadata.ReadFile(metadataFilename(fn)); err == nil {
+		s.routes = routesFromMetadata(m.Routes)
+		for _, sub := range m.Subsectors {
+			if i := strings.Index("ABCDEFGHIJKLMNOP", sub.Index); i >= 0 && len(sub.Index) == 1 && sub.Name != "" {
+				s.subsectors[i].name = sub.Name
+			}
+		}
+	}
+	return s, errs, nil
+}
@@ -0,0 +1,319 @@
+package main
+
+// sectorCompletion.go contains the workflow for completing a canonical sector, such as the Foreven referee's
+// preserve, whose canon has positions and UWPs but is missing names, extensions and so on. Every canonical field
+// is kept; only the missing fields are filled in. The referee reviews each world, accepting or rerolling it, and
+// the accepted worlds are saved as a new version of the campaign's overlay for the sector (see saveSectorOverlay).
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"trav2/cmd/traveller/tools"
+)
+
+// forevenSector is the name of the Foreven sector, the referee's preserve.
+const forevenSector = "Foreven"
+
+// completionField is a set of flags for the fields of a canonical world that can be filled in.
+type completionField int
+
+// Flags for the fields that can be filled in.
+const (
+	cfName       completionField = 1 << iota // The world's name
+	cfStars                                  // The stars of the system
+	cfImportance                             // The importance extension
+	cfEconomics                              // The economic extension and RU
+	cfCulture                                // The cultural extension
+	cfWorlds                                 // The number of worlds in the system
+	cfNobility                               // The (Imperial) nobility
+	cfCapital                                // The world is made its subsector's capital
+)
+
+// String returns the names of the fields, eg "Name, Stars".
+func (f completionField) String() string {
+	var names []string
+	for i, name := range [...]string{"Name", "Stars", "Importance", "Economics", "Culture", "Worlds", "Nobility", "Capital"} {
+		if f&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// completedWorld contains a canonical world and the same world with its missing fields filled in.
+type completedWorld struct {
+	canon    world           // The world as it is in canon
+	world    world           // The world with the missing fields filled in
+	missing  completionField // The fields missing from the canon, which are filled in
+	accepted bool            // Whether the referee has accepted the filled in world
+}
+
+// sectorCompletion contains the worlds of a canonical sector being completed for a campaign.
+type sectorCompletion struct {
+	campaign string           // The name of the campaign the overlay is for
+	sectorID int              // The database ID of the sector
+	sector   sector           // The sector, holding the canonical subsectors and worlds
+	worlds   []completedWorld // The worlds being completed, in the same order as the sector's worlds
+	namer    sectorNamer      // The names in use in the sector
+}
+
+// isMissingName returns whether a canonical name is missing, as in Foreven where many worlds are named "????".
+func isMissingName(name string) bool {
+	return strings.TrimSpace(name) == "" || strings.Contains(name, "?")
+}
+
+// missingFields works out which fields of the world are missing from its database row.
+func (d worldDto) missingFields() (f completionField) {
+	if isMissingName(d.name) {
+		f |= cfName
+	}
+	if strings.TrimSpace(d.stars) == "" {
+		f |= cfStars
+	}
+	if strings.TrimSpace(d.importance) == "" {
+		f |= cfImportance
+	}
+	// The RU is worked out from the economic extension, so it is missing with it.
+	if strings.TrimSpace(d.economics) == "" {
+		f |= cfEconomics
+	}
+	if strings.TrimSpace(d.culture) == "" {
+		f |= cfCulture
+	}
+	// Every system has at least its mainworld, so no worlds means the count is missing.
+	if d.worlds <= 0 {
+		f |= cfWorlds
+	}
+	if strings.TrimSpace(d.nobility) == "" && strings.Contains(d.allegiance, "Im") {
+		f |= cfNobility
+	}
+	return
+}
+
+// newSectorCompletion loads the named canonical sector from the database and fills in the fields missing from its
+// worlds. It returns ErrSectorNotFound if the sector has no worlds in the database.
+func newSectorCompletion(sectorName, campaign string) (*sectorCompletion, error) {
+	ds, err := repo.getWorldDtosBySector(context.Background(), sectorName)
+	if err != nil {
+		return nil, err
+	}
+	if len(ds) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSectorNotFound, sectorName)
+	}
+	c := &sectorCompletion{campaign: campaign, sectorID: ds[0].sectorID}
+	c.sector = sector{id: ds[0].sectorID, name: ds[0].sector, abbrev: ds[0].sectorNameAbbr, saved: true, otu: true}
+	if c.sector.subsectors, err = repo.getSubsectorsForSector(context.Background(), sectorName); err != nil {
+		log.Printf("Unable to get subsectors for %s: %v", sectorName, err)
+	}
+
+	// Canonical names must be in use before any are generated.
+	for _, d := range ds {
+		w := d.convertToWorld()
+		c.sector.worlds = append(c.sector.worlds, w)
+		c.worlds = append(c.worlds, completedWorld{canon: w, missing: d.missingFields()})
+		if !isMissingName(w.name) {
+			c.namer.use(w.name)
+		}
+	}
+	for i := range c.sector.subsectors {
+		c.namer.use(c.sector.subsectors[i].name)
+	}
+
+	for i := range c.worlds {
+		c.fill(i)
+	}
+	c.chooseCapitals()
+	log.Printf("Loaded %d worlds of %s for completion", len(c.worlds), sectorName)
+	return c, nil
+}
+
+// fill fills in the missing fields of the world at index i from a newly extended copy of the canonical world.
+// Every canonical field is kept.
+func (c *sectorCompletion) fill(i int) {
+	r := tools.RNG()
+	cw := &c.worlds[i]
+	gen := cw.canon
+	gen.stars = append([]*starDetail(nil), cw.canon.stars...)
+	gen.extendWorld(r)
+
+	w := cw.canon
+	w.genType = WgtT5ss
+	if cw.missing&cfName != 0 {
+		w.name = c.namer.name(r, c.sector.subsectorMix(w.hexLoc.IntIndex(), ""))
+	}
+	if cw.missing&cfStars != 0 {
+		w.stars = gen.stars
+	}
+	if cw.missing&cfImportance != 0 {
+		w.importance = gen.importance
+	}
+	if cw.missing&cfEconomics != 0 {
+		w.economics, w.ru = gen.economics, gen.ru
+	}
+	if cw.missing&cfCulture != 0 {
+		w.culture = gen.culture
+	}
+	if cw.missing&cfWorlds != 0 {
+		w.worlds = gen.worlds
+	}
+	if cw.missing&cfNobility != 0 {
+		w.getNobility()
+	}
+	cw.world = w
+	c.sector.worlds[i] = w
+}
+
+// reroll fills in the missing fields of the world at index i again, and works out the capitals again. The new
+// world must be accepted again.
+func (c *sectorCompletion) reroll(i int) {
+	if i < 0 || i >= len(c.worlds) {
+		return
+	}
+	cw := &c.worlds[i]
+	if cw.missing&cfName != 0 {
+		// The old name can be used again elsewhere, so take it out of use first.
+		old := normaliseName(cw.world.name)
+		for j, u := range c.namer.used {
+			if u == old {
+				c.namer.used = append(c.namer.used[:j], c.namer.used[j+1:]...)
+				break
+			}
+		}
+	}
+	c.fill(i)
+	cw.accepted = false
+	c.chooseCapitals()
+}
+
+// chooseCapitals makes the most important world (then the most populous) the capital of each subsector that has
+// no capital in canon. A canonical capital is shown by the subsector's capital ID or "Cp" in a world's remarks.
+func (c *sectorCompletion) chooseCapitals() {
+	for i := range c.worlds {
+		if c.worlds[i].missing&cfCapital != 0 {
+			c.worlds[i].missing &^= cfCapital
+			c.worlds[i].world.remarks = c.worlds[i].canon.remarks
+		}
+	}
+	for ss := range ssIndex {
+		best := -1
+		hasCapital := c.sector.subsectors[ss].capitalID > 0
+		for i, cw := range c.worlds {
+			if cw.canon.hexLoc.IntIndex() != ss {
+				continue
+			}
+			if strings.Contains(cw.canon.remarks, "Cp") {
+				hasCapital = true
+			}
+			if best < 0 || cw.world.importance.Importance > c.worlds[best].world.importance.Importance ||
+				(cw.world.importance.Importance == c.worlds[best].world.importance.Importance && cw.world.uwp.popInt > c.worlds[best].world.uwp.popInt) {
+				best = i
+			}
+		}
+		if hasCapital || best < 0 || c.worlds[best].world.uwp.popInt == 0 {
+			continue
+		}
+		c.worlds[best].missing |= cfCapital
+		c.worlds[best].world.remarks = strings.TrimSpace(c.worlds[best].world.remarks + " Cp")
+	}
+	for i := range c.worlds {
+		c.sector.worlds[i] = c.worlds[i].world
+	}
+}
+
+// acceptAll accepts every world.
+func (c *sectorCompletion) acceptAll() {
+	for i := range c.worlds {
+		c.worlds[i].accepted = true
+	}
+}
+
+// save saves the accepted worlds as a new version of the campaign's overlay for the sector, and writes the whole
+// sector (with the accepted worlds filled in) to the tab file, with its metadata alongside. It returns a status
+// message for display.
+func (c *sectorCompletion) save(filename string) string {
+	var msgs []string
+	if version, err := repo.saveSectorOverlay(context.Background(), c); err != nil {
+		log.Printf("Unable to save the %s overlay for %s: %v", c.campaign, c.sector.name, err)
+		msgs = append(msgs, fmt.Sprintf("Overlay not saved: %v", err))
+	} else {
+		msgs = append(msgs, fmt.Sprintf("Saved %s overlay version %d", c.campaign, version))
+	}
+
+	out := sector{name: c.sector.name, abbrev: c.sector.abbrev, subsectors: c.sector.subsectors, otu: true}
+	for _, cw := range c.worlds {
+		if cw.accepted {
+			out.worlds = append(out.worlds, cw.world)
+		} else {
+			out.worlds = append(out.worlds, cw.canon)
+		}
+	}
+	if err := writeSectorFile(&out, filename); err != nil {
+		msgs = append(msgs, fmt.Sprintf("Unable to write %s", filename))
+	} else {
+		msgs = append(msgs, fmt.Sprintf("Written to %s", filename))
+	}
+	if err := out.writeMetadata(metadataFilename(filename)); err != nil {
+		log.Printf("Unable to write the metadata for %s: %v", c.sector.name, err)
+		msgs = append(msgs, fmt.Sprintf("Unable to write %s", metadataFilename(filename)))
+	}
+	return strings.Join(msgs, ". ")
+}
+
+// worldOverlay holds the fields of a world filled in by a campaign overlay. Empty strings, and worlds and RU of -1,
+// are the fields that the overlay leaves as they are in canon.
+type worldOverlay struct {
+	name, stars, importance, economics, culture, nobility string
+	worlds, ru                                            int
+	capital                                               bool
+}
+
+// loadCampaignSector loads the named sector from the database with the latest version of the campaign's overlay
+// applied to its worlds. A sector with no overlay for the campaign, or an empty campaign, is loaded as it is in canon.
+func loadCampaignSector(name, campaign string) (*sector, error) {
+	s, err := loadSector(name)
+	if err != nil || campaign == "" {
+		return s, err
+	}
+	overlays, err := repo.getLatestOverlay(context.Background(), campaign, s.id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range s.worlds {
+		o, ok := overlays[s.worlds[i].id]
+		if !ok {
+			continue
+		}
+		w := &s.worlds[i]
+		if o.name != "" {
+			w.name = o.name
+		}
+		if o.stars != "" {
+			w.stars = parseStars(o.stars)
+		}
+		if o.importance != "" {
+			w.importance = parseImportanceExt(o.importance)
+		}
+		if o.economics != "" {
+			w.economics = parseEconomicEx(o.economics)
+		}
+		if o.culture != "" {
+			w.culture = parseCultureEx(o.culture)
+		}
+		if o.nobility != "" {
+			w.nobility = o.nobility
+		}
+		if o.worlds >= 0 {
+			w.worlds = o.worlds
+		}
+		if o.ru >= 0 {
+			w.ru = o.ru
+		}
+		if o.capital && !strings.Contains(w.remarks, "Cp") {
+			w.remarks = strings.TrimSpace(w.remarks + " Cp")
+		}
+	}
+	log.Printf("Loaded %s with %d worlds from the %s overlay", name, len(overlays), campaign)
+	return s, nil
+}
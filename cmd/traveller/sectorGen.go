@@ -0,0 +1,263 @@
+package main
+
+// sectorGen.go contains code for generating a whole random sector. Every hex of the sector is checked for a
+// system using the star density, and a mainworld is generated for each system using the chosen ruleset. The
+// subsectors and worlds are then named (see naming.go). Generation runs in the background so that the user
+// interface can show its progress.
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"trav2/cmd/traveller/tools"
+)
+
+// Sector dimensions, in hexes.
+const (
+	sectorWidth  = 32
+	sectorHeight = 40
+)
+
+// sectorRulesets are the rulesets that a sector can be generated with.
+var sectorRulesets = [...]Ruleset{RulesClassic, RulesMegaTraveller, RulesTraveller5}
+
+// sectorColumns are the columns shown for a generated sector. The worlds can be sorted on any of them.
+var sectorColumns = [...]string{"Hex", "Name", "UWP", "Bases", "Remarks", "Zone", "PBG", "Allegiance", "Stars"}
+
+// sectorGenOptions contains the options for generating a sector.
+type sectorGenOptions struct {
+	Name       string  // The name of the sector, eg "Foreven"
+	Allegiance string  // The basic allegiance of the worlds, a key of basicAllegianceMap, eg "Imperial"
+	Density    int     // The star density, one of the "sd" constants
+	Traffic    int     // The subsector traffic level (starport sparcity), one of the "ss" constants
+	Rules      Ruleset // The ruleset used to generate the mainworlds
+	Seed       int64   // The seed for the RNG, so the sector can be generated again. 0 for a random seed.
+
+	Challenge25 bool       // Use the 1986 Challenge #25 generator, which ignores the density, traffic and ruleset
+	Polities    bool       // Grow polities across the sector (see growPolities), rather than give every world the allegiance
+	Routes      bool       // Generate xboat and trade routes between the worlds (see generateRoutes)
+	Imperial    bool       // Generate a new, long settled Imperial sector outside the OTU (see imperial.go)
+	Languages   [16]string // The language mix for each subsector (A to P), eg "70% Vilani, 30% Vargr". Empty mixes are worked out.
+}
+
+// sectorGeneration tracks the generation of a sector in the background.
+type sectorGeneration struct {
+	sync.Mutex
+	options  sectorGenOptions // The options used, with the seed filled in
+	sector   sector           // The sector generated, set when generation has finished
+	total    int              // The number of systems to generate
+	done     int              // The number of systems generated so far
+	finished bool             // Whether generation has finished
+}
+
+// hasSystem rolls to see if a hex contains a system, for the star density.
+func hasSystem(r *rand.Rand, density int) bool {
+	switch density {
+	case sdRift:
+		return tools.RollD6(r)+tools.RollD6(r) <= 2
+	case sdSparse:
+		return tools.RollD6(r) >= 6
+	case sdScattered:
+		return tools.RollD6(r) >= 5
+	case sdDense:
+		return tools.RollD6(r) >= 3
+	default:
+		return tools.RollD6(r) >= 4
+	}
+}
+
+// startSectorGeneration starts generating a sector with the options in the background. Use progress to check
+// on it, and result to get the sector once it has finished.
+func startSectorGeneration(opts sectorGenOptions) *sectorGeneration {
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano() % 1000000
+	}
+	if strings.TrimSpace(opts.Name) == "" {
+		opts.Name = "Unknown"
+	}
+	if _, ok := basicAllegianceMap[opts.Allegiance]; !ok {
+		opts.Allegiance = "Imperial"
+	}
+	if opts.Imperial {
+		// A new Imperial sector belongs to the Imperium, with an xboat network, so has no polities of its own.
+		opts.Allegiance, opts.Polities, opts.Routes, opts.Challenge25 = "Imperial", false, true, false
+	}
+	g := &sectorGeneration{options: opts}
+	g.sector = sector{id: -1, name: opts.Name, abbrev: getAbbreviationForSector(opts.Name)}
+
+	// Subsectors already in the database keep their names and languages.
+	if ss, err := repo.getSubsectorsForSector(context.Background(), opts.Name); err == nil {
+		g.sector.subsectors = ss
+		for i := range ss {
+			g.sector.lockedSubsectors[i] = ss[i].name != ""
+		}
+	}
+	go g.generate()
+	return g
+}
+
+// generate generates the sector. It is run in the background by startSectorGeneration. Every roll is made with an
+// RNG of its own, seeded from the options, so the same seed and options always generate the same sector.
+func (g *sectorGeneration) generate() {
+	opts := g.options
+	if opts.Challenge25 {
+		log.Printf("Generating %s sector (Challenge #25, %s), seed %d", opts.Name, opts.Allegiance, opts.Seed)
+	} else {
+		log.Printf("Generating %s sector (%s, %s, %s, %s), seed %d", opts.Name, opts.Rules.Abbr(), opts.Allegiance,
+			mtSectorStarDensity[opts.Density], mtSubsectorTrafficArr[opts.Traffic], opts.Seed)
+	}
+	r := rand.New(rand.NewSource(opts.Seed))
+
+	// Work out which hexes have systems first, so that progress can be shown.
+	var locs []HexLoc
+	for x := 1; x <= sectorWidth; x++ {
+		for y := 1; y <= sectorHeight; y++ {
+			if (opts.Challenge25 && c25HasWorld(r)) || (!opts.Challenge25 && hasSystem(r, opts.Density)) {
+				locs = append(locs, HexLoc{x: x, y: y, sector: true})
+			}
+		}
+	}
+	sort.Sort(ByLoc(locs))
+	g.Lock()
+	g.total = len(locs)
+	s := g.sector
+	g.Unlock()
+
+	// The sector is built up without the lock held, as nothing else looks at it until generation has finished, so
+	// that progress can be checked while the worlds are named and the polities and routes are worked out.
+	for _, h := range locs {
+		s.worlds = append(s.worlds, generateSectorWorld(r, opts, h))
+		g.Lock()
+		g.done++
+		g.Unlock()
+	}
+
+	s.nameSector(r, opts.Languages)
+	if opts.Polities {
+		s.growPolities(r, opts.Allegiance, opts.Languages)
+	}
+	if opts.Imperial {
+		s.markCapitals()
+	}
+	if opts.Routes {
+		s.generateRoutes()
+	}
+	if opts.Imperial {
+		s.addWayStations()
+	}
+
+	g.Lock()
+	g.sector = s
+	g.finished = true
+	g.Unlock()
+	log.Printf("Generated %d worlds for %s sector", len(locs), opts.Name)
+}
+
+// generateSectorWorld generates the mainworld in the hex of the sector, using the ruleset in the options.
+func generateSectorWorld(r *rand.Rand, opts sectorGenOptions, h HexLoc) (w world) {
+	code := basicAllegianceMap[opts.Allegiance]
+	hex := h.String()
+	if opts.Challenge25 {
+		return generateC25World(r, h.x, h.y, code).toWorld(opts.Name)
+	}
+	switch opts.Rules {
+	case RulesClassic:
+		w = generateCT03World(r, unnamed, hex, opts.Name)
+		w.allegiance = code
+	case RulesTraveller5:
+		w = generateT5World(r, unnamed, hex, opts.Name, code)
+	default:
+		w = generateMTWorld(r, unnamed, hex, opts.Name, opts.Allegiance, mtSubsectorTrafficArr[opts.Traffic])
+	}
+	if opts.Imperial {
+		w.imperialise(r)
+	}
+	return
+}
+
+// progress returns the number of systems generated so far, the number to generate, and whether generation
+// has finished.
+func (g *sectorGeneration) progress() (done, total int, finished bool) {
+	g.Lock()
+	defer g.Unlock()
+	return g.done, g.total, g.finished
+}
+
+// result returns the generated sector and the options used to generate it. It returns nil if generation has
+// not finished yet.
+func (g *sectorGeneration) result() (*sector, sectorGenOptions) {
+	g.Lock()
+	defer g.Unlock()
+	if !g.finished {
+		return nil, g.options
+	}
+	return &g.sector, g.options
+}
+
+// sectorColumnValue returns the value of the column (an index into sectorColumns) for the world.
+func sectorColumnValue(w *world, col int) string {
+	switch col {
+	case 0:
+		return w.hexLoc.String()
+	case 1:
+		return w.name
+	case 2:
+		return w.uwp.String()
+	case 3:
+		return w.bases
+	case 4:
+		return w.remarks
+	case 5:
+		return w.zone.String()
+	case 6:
+		return w.pbg.String()
+	case 7:
+		return w.allegiance
+	case 8:
+		return w.systemStarString()
+	}
+	return ""
+}
+
+// sortSectorWorlds sorts the worlds on the column (an index into sectorColumns), keeping the worlds grouped by
+// subsector. Ties are kept in hex order.
+func sortSectorWorlds(ws []world, col int, ascending bool) {
+	sort.SliceStable(ws, func(i, j int) bool {
+		if ws[i].subsectorIndex != ws[j].subsectorIndex {
+			return ws[i].subsectorIndex < ws[j].subsectorIndex
+		}
+		vi, vj := sectorColumnValue(&ws[i], col), sectorColumnValue(&ws[j], col)
+		if vi == vj {
+			return ws[i].hexLoc.String() < ws[j].hexLoc.String()
+		}
+		return (vi < vj) == ascending
+	})
+}
+
+// saveSector saves a generated sector to the database, and to a tab file and Travellermap metadata file named after
+// the sector. It returns a status message for display.
+func saveSector(s *sector) string {
+	var msgs []string
+	if err := repo.saveGeneratedSector(context.Background(), s); err != nil {
+		log.Printf("Unable to save %s sector to the database: %v", s.name, err)
+		msgs = append(msgs, fmt.Sprintf("Not saved to database: %v", err))
+	} else {
+		msgs = append(msgs, "Saved to database")
+	}
+	filename := sectorFilename(s.name)
+	if err := writeSectorFile(s, filename); err != nil {
+		msgs = append(msgs, fmt.Sprintf("Unable to write %s", filename))
+	} else {
+		msgs = append(msgs, fmt.Sprintf("Written to %s", filename))
+	}
+	if err := s.writeMetadata(metadataFilename(filename)); err != nil {
+		log.Printf("Unable to write the metadata for %s sector: %v", s.name, err)
+		msgs = append(msgs, fmt.Sprintf("Unable to write %s", metadataFilename(filename)))
+	}
+	return strings.Join(msgs, ". ")
+}
@@ -0,0 +1,259 @@
+package main
+
+// sectorImport.go contains the import wizard, which loads sector files supplied by the user, such as
+// data/Joyner_worlds.tab, into the database. The file is parsed in any of the registered formats, or as tab
+// delimited lines under a column mapping if it has no header line, and each row is validated. The valid worlds are
+// staged in the world_staging table, and any conflicts with the worlds already in the database are shown before the
+// staged worlds are committed to the world table.
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// defaultImportColumns is the column mapping for files without a header line, the columns of a T5 tab file.
+var defaultImportColumns = strings.Join(recordColumns, ",")
+
+// importConflict is a world being imported into a hex that already has a world in the database.
+type importConflict struct {
+	existing world    // The world in the database
+	staged   world    // The world being imported
+	differs  []string // The columns whose values differ
+}
+
+// sectorImport is the state of an import of a sector file into the database.
+type sectorImport struct {
+	filename  string
+	sectorID  int              // The ID of the sector the worlds are imported into
+	sector    string           // The name of the sector
+	abbrev    string           // The abbreviation of the sector
+	worlds    []world          // The valid worlds read from the file
+	errs      []lineError      // The rows that could not be read or are not valid
+	staged    bool             // Whether the worlds have been staged in world_staging
+	conflicts []importConflict // The staged worlds whose hexes already have worlds
+}
+
+// parseImportColumns parses a comma separated column mapping, such as defaultImportColumns. Columns named as they
+// are in files, eg "Stellar", are accepted.
+func parseImportColumns(s string) (cols []string) {
+	for _, c := range strings.Split(s, ",") {
+		cols = append(cols, canonicalColumn(c))
+	}
+	return
+}
+
+// resolveImportSector finds the sector in the database that worlds are being imported into, by its name or
+// abbreviation. The sector given is tried first, then the sector abbreviation in the file's first world. It returns
+// ErrSectorNotFound if neither is in the database.
+func resolveImportSector(given string, rs []worldRecord) (sectorDTO, error) {
+	candidates := []string{strings.TrimSpace(given)}
+	if len(rs) > 0 {
+		candidates = append(candidates, rs[0].Sector)
+	}
+	sm, err := repo.getAllDetailedSectorAbbrev(context.Background())
+	if err != nil {
+		return sectorDTO{}, err
+	}
+	names := make([]string, 0, len(sm))
+	for n := range sm {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, c := range candidates {
+		if c == "" {
+			continue
+		}
+		name := ""
+		for _, n := range names {
+			if strings.EqualFold(n, c) {
+				name = n
+				break
+			}
+		}
+		for _, n := range names {
+			if name == "" && strings.EqualFold(sm[n], c) {
+				name = n
+			}
+		}
+		if name == "" {
+			// Sectors that have no worlds yet are not detailed, but can still be found by their full name.
+			name = c
+		}
+		if d, err := repo.getSectorByName(context.Background(), name); err == nil {
+			return d, nil
+		}
+	}
+	return sectorDTO{}, fmt.Errorf("%w: %s", ErrSectorNotFound, strings.Join(candidates, " or "))
+}
+
+// validateRecord checks the fields of a world being imported that toWorld does not. It returns nil if it is valid.
+func validateRecord(r *worldRecord) error {
+	if !parseUwp(r.UWP).validate() {
+		return fmt.Errorf("%w %q", ErrInvalidUWP, r.UWP)
+	}
+	if _, err := ZoneFromString(r.Zone); err != nil {
+		return fmt.Errorf("%w %q", ErrInvalidZone, r.Zone)
+	}
+	if r.PBG != "" && (len(r.PBG) != 3 || strings.ContainsRune(r.PBG, '?')) {
+		return fmt.Errorf("%w %q", ErrInvalidPBG, r.PBG)
+	}
+	if h := NewHexLoc(r.Hex, true); h != nil && r.SS != "" && r.SS != h.GetIndex() {
+		return fmt.Errorf("%w: subsector %s for hex %s", ErrSubsectorMismatch, r.SS, r.Hex)
+	}
+	return nil
+}
+
+// parseImportFile reads and validates a sector file to be imported into the sector, given by name or abbreviation,
+// or by the file itself if sectorName is empty. Files without a header line are read under the columns.
+func parseImportFile(filename, sectorName string, columns []string) (*sectorImport, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var fr formatResult
+	if f := detectContent(data); f != nil {
+		if fr, err = f.read(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+	} else {
+		if !hasColumn(columns, "Hex") || !hasColumn(columns, "UWP") {
+			return nil, fmt.Errorf("%w: the columns must include Hex and UWP", ErrInvalidTabFile)
+		}
+		fr = readDelimited(splitLines(data), 0, columns)
+	}
+
+	d, err := resolveImportSector(sectorName, fr.records)
+	if err != nil {
+		return nil, err
+	}
+	si := &sectorImport{filename: filename, sectorID: d.id, sector: d.name, abbrev: d.abbrev}
+	gt := recordsGenType(fr.records)
+	hexes := make(map[string]int)
+	for i, r := range fr.records {
+		if err := validateRecord(&r); err != nil {
+			fr.addError(fr.lines[i], err)
+			continue
+		}
+		w, err := r.toWorld(d.name, gt)
+		if err != nil {
+			fr.addError(fr.lines[i], err)
+			continue
+		}
+		if line, ok := hexes[r.Hex]; ok {
+			fr.addError(fr.lines[i], fmt.Errorf("%w %s, first on %s %d", ErrDuplicateHex, r.Hex, fr.what, line))
+			continue
+		}
+		hexes[r.Hex] = fr.lines[i]
+		w.sectorAbbrev = d.abbrev
+		si.worlds = append(si.worlds, w)
+	}
+	sort.SliceStable(fr.errs, func(i, j int) bool { return fr.errs[i].line < fr.errs[j].line })
+	si.errs = fr.errs
+	log.Printf("Parsed %d worlds for %s sector from %s, with %d errors", len(si.worlds), si.sector, filename, len(si.errs))
+	return si, nil
+}
+
+// stage saves the valid worlds in the world_staging table, replacing any already staged for the sector, and finds
+// the worlds already in the database that they conflict with.
+func (si *sectorImport) stage() error {
+	if err := repo.stageWorlds(context.Background(), si.sectorID, si.abbrev, si.worlds); err != nil {
+		return err
+	}
+	si.staged = true
+	existing, err := repo.getWorldsInStagedHexes(context.Background(), si.sectorID)
+	if err != nil {
+		return err
+	}
+	staged := make(map[string]*world)
+	for i := range si.worlds {
+		staged[si.worlds[i].hexLoc.String()] = &si.worlds[i]
+	}
+	si.conflicts = nil
+	for _, e := range existing {
+		s := staged[e.hexLoc.String()]
+		if s == nil {
+			continue
+		}
+		c := importConflict{existing: e, staged: *s}
+		er, sr := recordFromWorld(&e), recordFromWorld(s)
+		for _, col := range genTypeColumns(s.genType)[1:] {
+			if er.field(col) != sr.field(col) {
+				c.differs = append(c.differs, col)
+			}
+		}
+		si.conflicts = append(si.conflicts, c)
+	}
+	log.Printf("Staged %d worlds for %s sector, %d in hexes that already have worlds", len(si.worlds), si.sector,
+		len(si.conflicts))
+	return nil
+}
+
+// commit moves the staged worlds into the world table. Worlds already in the hexes of staged worlds are replaced if
+// replace is true; otherwise they are kept and the staged worlds in their hexes are dropped. It returns a status
+// message for display.
+func (si *sectorImport) commit(replace bool) string {
+	n, err := repo.commitStagedWorlds(context.Background(), si.sectorID, replace)
+	if err != nil {
+		log.Printf("Unable to commit the worlds imported from %s: %v", si.filename, err)
+		return fmt.Sprintf("Unable to commit: %v", err)
+	}
+	si.staged = false
+	msg := fmt.Sprintf("Committed %d worlds to %s", n, si.sector)
+	if len(si.conflicts) > 0 {
+		if replace {
+			msg += fmt.Sprintf(", replacing %d", len(si.conflicts))
+		} else {
+			msg += fmt.Sprintf(", keeping %d existing worlds", len(si.conflicts))
+		}
+	}
+	log.Print(msg)
+	return msg
+}
+
+// summary describes the result of parsing the file and staging its worlds.
+func (si *sectorImport) summary() string {
+	msg := fmt.Sprintf("%d valid worlds for %s (%s), %d rows with errors", len(si.worlds), si.sector, si.abbrev,
+		len(si.errs))
+	if si.staged {
+		msg += fmt.Sprintf(". Staged, %d conflicting with existing worlds", len(si.conflicts))
+	}
+	return msg
+}
+
+// String describes the conflict in one line, eg "0101 Regina A788899-C -> Regina A788899-D (UWP)".
+func (c importConflict) String() string {
+	if len(c.differs) == 0 {
+		return fmt.Sprintf("%s %s %s (unchanged)", c.existing.hexLoc.String(), c.existing.name, c.existing.uwp.String())
+	}
+	return fmt.Sprintf("%s %s %s -> %s %s (%s)", c.existing.hexLoc.String(), c.existing.name, c.existing.uwp.String(),
+		c.staged.name, c.staged.uwp.String(), strings.Join(c.differs, ", "))
+}
+
+// importSectorFile imports the file into the sector from the command line, printing the rows with errors and the
+// conflicts. Worlds in hexes that already have worlds are only committed if replace is true.
+func importSectorFile(filename, sectorName string, replace bool) error {
+	si, err := parseImportFile(filename, sectorName, parseImportColumns(defaultImportColumns))
+	if err != nil {
+		return err
+	}
+	for _, e := range si.errs {
+		fmt.Printf("%s: %v\n", filename, e)
+	}
+	if err = si.stage(); err != nil {
+		return err
+	}
+	fmt.Println(si.summary())
+	for _, c := range si.conflicts {
+		fmt.Println("  " + c.String())
+	}
+	if len(si.conflicts) > 0 && !replace {
+		fmt.Println("Existing worlds are kept. Use --import-replace to replace them with the imported worlds.")
+	}
+	fmt.Println(si.commit(replace))
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+// sectorMetadata.go contains code for exporting a sector's Travellermap metadata next to its tab data, and for
+// importing metadata into the database. See the metadata package for the file format.
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"trav2/cmd/traveller/metadata"
+)
+
+// defaultMilieu is the milieu given to sectors that are not in the database.
+const defaultMilieu = "M1105"
+
+// metadataFilename returns the name of the metadata file that goes with the tab file, eg "Foreven.tab" gives
+// "Foreven-metadata.xml".
+func metadataFilename(tabFile string) string {
+	return strings.TrimSuffix(tabFile, filepath.Ext(tabFile)) + "-metadata.xml"
+}
+
+// toMetadata builds the Travellermap metadata for the sector. Details that the sector does not hold, such as its
+// location and other names, are taken from the database if the sector is there.
+func (s *sector) toMetadata() *metadata.Sector {
+	m := &metadata.Sector{Abbreviation: s.abbrev, Names: []metadata.Name{{Text: s.name}}, Tags: generatedSectorTag,
+		DataFile: &metadata.DataFile{Milieu: defaultMilieu}}
+	if d, err := repo.getSectorByName(context.Background(), s.name); err == nil {
+		m.X, m.Y, m.Tags = d.xLoc, d.yLoc, d.tags
+		if d.milieu != "" {
+			m.DataFile.Milieu = d.milieu
+		}
+		if names, err := repo.getSectorAltNames(context.Background(), d.id); err == nil {
+			m.Names = append(m.Names, names...)
+		}
+	}
+	if !s.otu {
+		m.Credits = fmt.Sprintf("Generated by The Travellers Tool %s.", appVersion)
+		m.DataFile.Source = "The Travellers Tool"
+	}
+
+	for i, sub := range s.subsectors {
+		if sub.name != "" {
+			m.Subsectors = append(m.Subsectors, metadata.Subsector{Index: ssIndex[i], Name: sub.name})
+		}
+	}
+
+	// Every allegiance used by the worlds is defined, from the sector's polities or the allegiance table.
+	names, err := repo.getAllegianceNames(context.Background())
+	if err != nil {
+		log.Printf("Unable to get the allegiance names for %s: %v", s.name, err)
+		names = make(map[string]string)
+	}
+	for _, p := range s.polities {
+		names[p.code] = p.name
+	}
+	seen := make(map[string]bool)
+	for _, w := range s.worlds {
+		if w.allegiance == "" || seen[w.allegiance] {
+			continue
+		}
+		seen[w.allegiance] = true
+		name, ok := names[w.allegiance]
+		if !ok {
+			name = "Unknown"
+		}
+		m.Allegiances = append(m.Allegiances, metadata.Allegiance{Code: w.allegiance, Name: name})
+	}
+
+	for _, p := range s.polities {
+		if p.capital != "" {
+			m.Labels = append(m.Labels, metadata.Label{Hex: p.capital, Size: "small", Text: p.name})
+		}
+	}
+	m.Routes = metadataRoutes(s.routes)
+	return m
+}
+
+// writeMetadata writes the sector's Travellermap metadata to the named file.
+func (s *sector) writeMetadata(filename string) error {
+	return s.toMetadata().WriteFile(filename)
+}
+
+// importMetadataFile reads the named Travellermap metadata file, and saves its subsectors, allegiances and other
+// names to the database. It returns a status message for display.
+func importMetadataFile(filename string) string {
+	m, err := metadata.ReadFile(filename)
+	if err != nil {
+		log.Printf("Unable to read metadata from %s: %v", filename, err)
+		return fmt.Sprintf("Unable to read %s: %v", filename, err)
+	}
+	if err = repo.importSectorMetadata(context.Background(), m); err != nil {
+		log.Printf("Unable to import metadata for %s: %v", m.Name(), err)
+		return fmt.Sprintf("Unable to import %s: %v", filename, err)
+	}
+	return fmt.Sprintf("Imported %s: %d subsectors, %d allegiances, %d names", m.Name(), len(m.Subsectors),
+		len(m.Allegiances), len(m.Names))
+}
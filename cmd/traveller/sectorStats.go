@@ -0,0 +1,295 @@
+package main
+
+// sectorStats.go contains code for working out the statistics of a sector and each of its subsectors, such as the
+// population, starports, bases, tech levels and trade codes, so that generated sectors can be compared with canon
+// ones. The statistics can be shown as a text report, or exported as text and CSV files.
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// uwpDigitNames are the names of the UWP digits that are counted, in UWP order.
+var uwpDigitNames = [...]string{"Size", "Atmosphere", "Hydrographics", "Population", "Government", "Law level"}
+
+// statsBarWidth is the most characters used for a bar of a histogram in the text report.
+const statsBarWidth = 40
+
+// sectorStats holds the statistics for a sector or subsector. The histograms are counts of worlds, keyed by value.
+type sectorStats struct {
+	name             string                          // The name of the sector or subsector
+	worlds           int                             // The number of worlds
+	population       float64                         // The total population
+	allegianceWorlds map[string]int                  // The number of worlds of each allegiance
+	allegiancePop    map[string]float64              // The population of each allegiance
+	starports        map[string]int                  // The starports, eg "A"
+	bases            map[string]int                  // The bases, eg "N", counting each base of a world
+	zones            map[string]int                  // The travel zones, eg "Amber"
+	techLevels       map[int]int                     // The tech levels
+	uwpDigits        [len(uwpDigitNames)]map[int]int // The UWP digits, in the order of uwpDigitNames
+	tradeCodes       map[string]int                  // The trade codes and other remarks, eg "Ag"
+	gasGiantWorlds   int                             // The number of worlds with a gas giant in the system
+	gasGiants        int                             // The number of gas giants
+	ru               int                             // The total Resource Units
+	gwp              float64                         // The estimated Gross World Product, in credits
+}
+
+// newSectorStats returns empty statistics with the name.
+func newSectorStats(name string) *sectorStats {
+	st := &sectorStats{name: name, allegianceWorlds: make(map[string]int), allegiancePop: make(map[string]float64),
+		starports: make(map[string]int), bases: make(map[string]int), zones: make(map[string]int),
+		techLevels: make(map[int]int), tradeCodes: make(map[string]int)}
+	for i := range st.uwpDigits {
+		st.uwpDigits[i] = make(map[int]int)
+	}
+	return st
+}
+
+// worldPopulation works out the population of the world from the population digit of its PBG and its population
+// code, eg a PBG of 3xx and population code 7 is 30 million. A missing population digit counts as 1.
+func worldPopulation(w *world) float64 {
+	if w.uwp.popInt <= 0 {
+		return 0
+	}
+	digit := w.pbg.populationDigit
+	if digit <= 0 {
+		digit = 1
+	}
+	return float64(digit) * math.Pow10(w.uwp.popInt)
+}
+
+// worldGWP estimates the Gross World Product of the world, in credits, as its population times a per capita income
+// of Cr1000 at a World Trade Number of 5, which doubles for each point of WTN above 5 and halves for each below.
+func worldGWP(w *world) float64 {
+	return worldPopulation(w) * 1000 * math.Pow(2, worldTradeNumber(w)-5)
+}
+
+// add adds the world to the statistics.
+func (st *sectorStats) add(w *world) {
+	u := w.uwp
+	pop := worldPopulation(w)
+	st.worlds++
+	st.population += pop
+	st.allegianceWorlds[w.allegiance]++
+	st.allegiancePop[w.allegiance] += pop
+	st.starports[u.starport]++
+	for _, b := range w.bases {
+		st.bases[string(b)]++
+	}
+	st.zones[w.zone.Desc()]++
+	st.techLevels[u.techInt]++
+	for i, d := range [...]int{u.sizeInt, u.atmInt, u.hydInt, u.popInt, u.govInt, u.lawInt} {
+		st.uwpDigits[i][d]++
+	}
+	for _, tc := range strings.Fields(w.remarks) {
+		st.tradeCodes[tc]++
+	}
+	if w.pbg.gasGiants > 0 {
+		st.gasGiantWorlds++
+		st.gasGiants += w.pbg.gasGiants
+	}
+	st.ru += w.ru
+	st.gwp += worldGWP(w)
+}
+
+// statistics works out the statistics for the whole sector, and for each of its subsectors (in order from A to P).
+func (s *sector) statistics() (total *sectorStats, subsectors [16]*sectorStats) {
+	total = newSectorStats(s.name)
+	for i := range subsectors {
+		name := s.subsectors[i].name
+		if name == "" {
+			name = "Subsector " + ssIndex[i]
+		}
+		subsectors[i] = newSectorStats(fmt.Sprintf("%s (%s)", name, ssIndex[i]))
+	}
+	for i := range s.worlds {
+		w := &s.worlds[i]
+		total.add(w)
+		if ss := w.hexLoc.IntIndex(); ss >= 0 && ss < len(subsectors) {
+			subsectors[ss].add(w)
+		}
+	}
+	return
+}
+
+// formatPopulation formats a population or amount for display, eg 32500000 is "32.5 million".
+func formatPopulation(p float64) string {
+	switch {
+	case p >= 1e12:
+		return fmt.Sprintf("%.1f trillion", p/1e12)
+	case p >= 1e9:
+		return fmt.Sprintf("%.1f billion", p/1e9)
+	case p >= 1e6:
+		return fmt.Sprintf("%.1f million", p/1e6)
+	}
+	return fmt.Sprintf("%.0f", p)
+}
+
+// sortedKeys returns the keys of the count map, in order.
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedDigits returns the keys of the histogram, in order.
+func sortedDigits(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// writeHistogram writes a histogram as rows of a label, a count and a bar, with the bars scaled to the biggest count.
+func writeHistogram(b *strings.Builder, title string, labels []string, counts []int) {
+	fmt.Fprintf(b, "\n%s\n", title)
+	most := 0
+	for _, c := range counts {
+		most = maxInt(most, c)
+	}
+	for i, l := range labels {
+		bar := counts[i]
+		if most > statsBarWidth {
+			bar = (counts[i]*statsBarWidth + most - 1) / most
+		}
+		fmt.Fprintf(b, "  %-6s %5d %s\n", l, counts[i], strings.Repeat("#", bar))
+	}
+}
+
+// writeCounts writes a histogram of the count map, in key order.
+func writeCounts(b *strings.Builder, title string, m map[string]int) {
+	keys := sortedKeys(m)
+	counts := make([]int, len(keys))
+	for i, k := range keys {
+		counts[i] = m[k]
+	}
+	writeHistogram(b, title, keys, counts)
+}
+
+// writeDigits writes a histogram of the UWP digits or tech levels, in order.
+func writeDigits(b *strings.Builder, title string, m map[int]int) {
+	keys := sortedDigits(m)
+	labels := make([]string, len(keys))
+	counts := make([]int, len(keys))
+	for i, k := range keys {
+		labels[i], counts[i] = Ehex(k).String(), m[k]
+	}
+	writeHistogram(b, title, labels, counts)
+}
+
+// text returns the statistics as a text report.
+func (st *sectorStats) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s\n", st.name, strings.Repeat("=", len(st.name)))
+	fmt.Fprintf(&b, "Worlds:      %d\n", st.worlds)
+	fmt.Fprintf(&b, "Population:  %s\n", formatPopulation(st.population))
+	fmt.Fprintf(&b, "Gas giants:  %d in %d systems (%d without)\n", st.gasGiants, st.gasGiantWorlds,
+		st.worlds-st.gasGiantWorlds)
+	fmt.Fprintf(&b, "Total RU:    %d\n", st.ru)
+	fmt.Fprintf(&b, "Total GWP:   Cr %s\n", formatPopulation(st.gwp))
+	if st.worlds == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\nAllegiance       Worlds  Population\n")
+	for _, a := range sortedKeys(st.allegianceWorlds) {
+		fmt.Fprintf(&b, "  %-14s %6d  %s\n", a, st.allegianceWorlds[a], formatPopulation(st.allegiancePop[a]))
+	}
+	writeCounts(&b, "Starports", st.starports)
+	writeCounts(&b, "Bases", st.bases)
+	writeCounts(&b, "Zones", st.zones)
+	writeDigits(&b, "Tech levels", st.techLevels)
+	for i, name := range uwpDigitNames {
+		writeDigits(&b, name, st.uwpDigits[i])
+	}
+	writeCounts(&b, "Trade codes", st.tradeCodes)
+	return b.String()
+}
+
+// csvRecords returns the statistics as CSV records of scope, statistic, key and value, eg
+// "Foreven,Starport,A,12". Statistics with no key, such as the total population, have an empty key.
+func (st *sectorStats) csvRecords() (rs [][]string) {
+	add := func(stat, key string, value interface{}) {
+		rs = append(rs, []string{st.name, stat, key, fmt.Sprint(value)})
+	}
+	add("Worlds", "", st.worlds)
+	add("Population", "", fmt.Sprintf("%.0f", st.population))
+	add("Gas giant systems", "", st.gasGiantWorlds)
+	add("Gas giants", "", st.gasGiants)
+	add("RU", "", st.ru)
+	add("GWP", "", fmt.Sprintf("%.0f", st.gwp))
+	for _, a := range sortedKeys(st.allegianceWorlds) {
+		add("Allegiance worlds", a, st.allegianceWorlds[a])
+		add("Allegiance population", a, fmt.Sprintf("%.0f", st.allegiancePop[a]))
+	}
+	for _, c := range []struct {
+		stat string
+		m    map[string]int
+	}{{"Starport", st.starports}, {"Base", st.bases}, {"Zone", st.zones}, {"Trade code", st.tradeCodes}} {
+		for _, k := range sortedKeys(c.m) {
+			add(c.stat, k, c.m[k])
+		}
+	}
+	for _, k := range sortedDigits(st.techLevels) {
+		add("Tech level", Ehex(k).String(), st.techLevels[k])
+	}
+	for i, name := range uwpDigitNames {
+		for _, k := range sortedDigits(st.uwpDigits[i]) {
+			add(name, Ehex(k).String(), st.uwpDigits[i][k])
+		}
+	}
+	return
+}
+
+// statsFilename returns the name of the statistics file that goes with the tab file, with the extension, eg
+// "Foreven.tab" and ".csv" give "Foreven-stats.csv".
+func statsFilename(tabFile, ext string) string {
+	return strings.TrimSuffix(tabFile, filepath.Ext(tabFile)) + "-stats" + ext
+}
+
+// writeStatistics writes the statistics for the sector and its subsectors to a text file and a CSV file, named
+// after the sector. It returns a status message for display.
+func (s *sector) writeStatistics() string {
+	total, subsectors := s.statistics()
+	all := append([]*sectorStats{total}, subsectors[:]...)
+	tabFile := sectorFilename(s.name)
+
+	texts := make([]string, len(all))
+	for i, st := range all {
+		texts[i] = st.text()
+	}
+	txtFile := statsFilename(tabFile, ".txt")
+	if err := os.WriteFile(txtFile, []byte(strings.Join(texts, "\n\n")), 0644); err != nil {
+		log.Printf("Unable to write the statistics for %s sector: %v", s.name, err)
+		return fmt.Sprintf("Unable to write %s: %v", txtFile, err)
+	}
+
+	csvFile := statsFilename(tabFile, ".csv")
+	f, err := os.Create(csvFile)
+	if err != nil {
+		log.Printf("Unable to write the statistics for %s sector: %v", s.name, err)
+		return fmt.Sprintf("Unable to write %s: %v", csvFile, err)
+	}
+	defer f.Close()
+	cw := csv.NewWriter(f)
+	cw.Write([]string{"Scope", "Statistic", "Key", "Value"})
+	for _, st := range all {
+		cw.WriteAll(st.csvRecords())
+	}
+	if err = cw.Error(); err != nil {
+		log.Printf("Unable to write the statistics for %s sector: %v", s.name, err)
+		return fmt.Sprintf("Unable to write %s: %v", csvFile, err)
+	}
+	return fmt.Sprintf("Written to %s and %s", txtFile, csvFile)
+}
@@ -1,151 +1,811 @@
 package main
 
-// sqliteDb.go contains specific code for accessing the sqlite database.
+// sqliteDb.go contains specific code for accessing the sqlite database. The queries are methods of the repository
+// (see repository.go), and are all parameterised prepared statements.
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"trav2/cmd/traveller/metadata"
 
 	_ "github.com/mattn/go-sqlite3" // Blank import used for importing sqlite3
 )
 
-// dbType constant defines what type of database we are using.
-//const sdbType string = "sqlite3"
+// scanStrings scans rows of a single text column into a slice of strings.
+func scanStrings(rows *sql.Rows) (ss []string, e error) {
+	defer rows.Close()
+	for rows.Next() {
+		var s string
+		if e = rows.Scan(&s); e != nil {
+			return nil, e
+		}
+		ss = append(ss, s)
+	}
+	return ss, rows.Err()
+}
+
+// getAllValidSectors gets all the canonical (OTU) sectors from the database and returns a slice of strings with their
+// names. Generated sectors are left out.
+func (r *repository) getAllValidSectors(ctx context.Context) ([]string, error) {
+	rows, e := r.query(ctx, "SELECT DISTINCT name FROM sector WHERE is_detailed=1 AND otu=1 ORDER BY name")
+	if e != nil {
+		return nil, e
+	}
+	return scanStrings(rows)
+}
 
-var dbFile string
+// getAllDetailedSectorAbbrev gets all the sectors from the database and returns a map with fullnames
+// mapping to abbreviations. For example sm["Foreven"] = "Fore"
+func (r *repository) getAllDetailedSectorAbbrev(ctx context.Context) (sm map[string]string, e error) {
+	rows, e := r.query(ctx, "SELECT DISTINCT name, abbreviation FROM sector WHERE is_detailed=1 ORDER BY name")
+	if e != nil {
+		return nil, e
+	}
+	defer rows.Close()
+
+	sm = make(map[string]string)
+	for rows.Next() {
+		var name, abbreviation string
+		if e = rows.Scan(&name, &abbreviation); e != nil {
+			return nil, e
+		}
+		sm[name] = abbreviation
+	}
+	return sm, rows.Err()
+}
 
-// SetDbFile sets the name of the sqlite3 database file.
-func SetDbFile(dbf string) {
-	dbFile = dbf
+// getSubsectorBySectorNameAndIndex gets the subsector that matches the sector and subsectorIndex.
+// It returns a subsector object, or a blank one if the subsector is not in the database.
+func (r *repository) getSubsectorBySectorNameAndIndex(ctx context.Context, sector, idx string) (ss subsector, e error) {
+	e = r.queryRow(ctx, "SELECT subsector.id, subsector.name, COALESCE(subsector.remarks, ''), language.name,"+
+		" COALESCE(subsector.capital_id, -1) FROM subsector, sector, language"+
+		" WHERE subsector.sector_id = sector.id AND subsector.lang_id = language.id AND"+
+		" sector.name = ? AND subsector.subsector_index = ?", []interface{}{sector, idx},
+		&ss.id, &ss.name, &ss.remarks, &ss.language, &ss.capitalID)
+	if e == sql.ErrNoRows {
+		return subsector{}, nil
+	}
+	return
 }
 
-// GetAllValidSectors gets all the sectors from the database and returns a slice of strings with their names.
-func GetAllValidSectors() (ss []string, e error) {
+// getSubsectorsForSector gets the subsectors of the named sector, in order from A to P. Subsectors that are not in
+// the database are left blank.
+func (r *repository) getSubsectorsForSector(ctx context.Context, sector string) (ss [16]subsector, e error) {
+	rows, e := r.query(ctx, "SELECT subsector.id, subsector.subsector_index, subsector.name, COALESCE(subsector.remarks, ''),"+
+		" COALESCE(language.name, ''), COALESCE(subsector.capital_id, -1)"+
+		" FROM subsector JOIN sector ON subsector.sector_id = sector.id"+
+		" LEFT JOIN language ON subsector.lang_id = language.id"+
+		" WHERE sector.name = ?", sector)
+	if e != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sub subsector
+		var idx string
+		if e = rows.Scan(&sub.id, &idx, &sub.name, &sub.remarks, &sub.language, &sub.capitalID); e != nil {
+			return
+		}
+		for i, s := range ssIndex {
+			if s == idx {
+				ss[i] = sub
+			}
+		}
+	}
+	return ss, rows.Err()
+}
 
-	// Get the database connection
-	db, e := sql.Open(dbType, dbFile)
+// getAllMajorRaces gets all the major races from the database and returns a slice of strings with their names.
+func (r *repository) getAllMajorRaces(ctx context.Context) ([]string, error) {
+	rows, e := r.query(ctx, "SELECT DISTINCT race_name FROM race WHERE is_major=1 ORDER BY race_name")
 	if e != nil {
 		return nil, e
 	}
-	defer db.Close()
+	return scanStrings(rows)
+}
 
-	rows, e := db.Query("SELECT DISTINCT name FROM sector where is_detailed=1 ORDER BY name")
+// getAllegianceNames gets all the allegiances from the allegiance table, as a map from code to name.
+func (r *repository) getAllegianceNames(ctx context.Context) (names map[string]string, e error) {
+	rows, e := r.query(ctx, "SELECT code, allegiance_name FROM allegiance")
 	if e != nil {
 		return nil, e
 	}
 	defer rows.Close()
 
-	var name string
+	names = make(map[string]string)
+	for rows.Next() {
+		var code, name string
+		if e = rows.Scan(&code, &name); e != nil {
+			return nil, e
+		}
+		names[code] = name
+	}
+	return names, rows.Err()
+}
+
+// getSectorByName gets the sector's details, including its milieu, tags and whether it is part of the OTU, from the
+// sector table.
+func (r *repository) getSectorByName(ctx context.Context, name string) (d sectorDTO, e error) {
+	e = r.queryRow(ctx, "SELECT sector.id, sector.name, sector.abbreviation, sector.x_loc, sector.y_loc,"+
+		" COALESCE(sector.tags, ''), COALESCE(milieu.abbreviation, ''), sector.otu"+
+		" FROM sector LEFT JOIN milieu ON milieu.id = sector.milieu_id WHERE sector.name = ?", []interface{}{name},
+		&d.id, &d.name, &d.abbrev, &d.xLoc, &d.yLoc, &d.tags, &d.milieu, &d.otu)
+	return d, e
+}
+
+// getSectorAltNames gets the other names of the sector, such as its Zhodani name, from the sector_altname table.
+func (r *repository) getSectorAltNames(ctx context.Context, sectorID int) (ns []metadata.Name, e error) {
+	rows, e := r.query(ctx, "SELECT sector_altname.name, COALESCE(language.abbreviation, '') FROM sector_altname"+
+		" LEFT JOIN language ON language.id = sector_altname.lang_id WHERE sector_altname.sector_id = ?", sectorID)
+	if e != nil {
+		return nil, e
+	}
+	defer rows.Close()
 
 	for rows.Next() {
-		rows.Scan(&name)
-		ss = append(ss, name)
+		var n metadata.Name
+		if e = rows.Scan(&n.Text, &n.Lang); e != nil {
+			return nil, e
+		}
+		ns = append(ns, n)
 	}
-	return ss, nil
+	return ns, rows.Err()
 }
 
-// GetAllDetailedSectorAbbrev gets all the sectors from the database and returns a map with fullnames
-// mapping to abbreviations. For example sm["Foreven"] = "Fore"
-func GetAllDetailedSectorAbbrev() (sm map[string]string, e error) {
+// getAllCTSkills gets all the non-cascade skills of the Classic Traveller rulebooks from the database and returns a
+// slice of strings.
+func (r *repository) getAllCTSkills(ctx context.Context) ([]string, error) {
+	rows, e := r.query(ctx, "SELECT skill_name FROM skill WHERE is_virtual=0 AND rulebook_id IN (SELECT rulebook.id"+
+		" FROM rulebook JOIN ruleset ON rulebook.ruleset_id = ruleset.id WHERE ruleset.abbreviation = ?) ORDER BY skill_name", "CT")
+	if e != nil {
+		return nil, e
+	}
+	return scanStrings(rows)
+}
 
-	sm = make(map[string]string)
+// worldSelectFields and worldSelectTables make up worldSelect, the common SELECT clause used for retrieving worlds
+// into a worldDto. Note that the subsector is a left join, as not every sector in the database has its subsectors
+// detailed.
+const (
+	worldSelectFields = "world.id, world.sector_id, sector.name, sector.abbreviation, world.subsector_index, COALESCE(subsector.name, ''), world.hex," +
+		" world.name, world.UWP, COALESCE(world.bases, ''), COALESCE(world.remarks, ''), COALESCE(world.zone, ''), COALESCE(world.PBG, ''), COALESCE(world.allegiance, '')," +
+		" COALESCE(world.stars, ''), COALESCE(world.importance, ''), COALESCE(world.economics, ''), COALESCE(world.culture, ''), COALESCE(world.nobility, '')," +
+		" COALESCE(world.worlds, 0), COALESCE(world.RU, 0)"
+	worldSelectTables = " FROM world JOIN sector ON world.sector_id = sector.id" +
+		" LEFT JOIN subsector ON subsector.sector_id = world.sector_id AND subsector.subsector_index = world.subsector_index"
+	worldSelect = "SELECT " + worldSelectFields + worldSelectTables
+)
+
+// scanWorldDto scans the current row of a query built on worldSelect into a worldDto.
+func scanWorldDto(rows *sql.Rows) (d worldDto, e error) {
+	e = rows.Scan(&d.id, &d.sectorID, &d.sector, &d.sectorNameAbbr, &d.subsectorIndex, &d.subsector, &d.hexLoc, &d.name, &d.uwp,
+		&d.bases, &d.remarks, &d.zone, &d.pbg, &d.allegiance, &d.stars, &d.importance, &d.economics, &d.culture, &d.nobility,
+		&d.worlds, &d.ru)
+	return
+}
+
+// scanWorldDtos scans all the rows of a query built on worldSelect into worldDtos.
+func scanWorldDtos(rows *sql.Rows) (ds []worldDto, e error) {
+	defer rows.Close()
+	for rows.Next() {
+		d, e := scanWorldDto(rows)
+		if e != nil {
+			return nil, e
+		}
+		ds = append(ds, d)
+	}
+	return ds, rows.Err()
+}
 
-	// Get the database connection
-	db, e := sql.Open(dbType, dbFile)
+// getWorldByID gets the (canonical) world with the given database ID. It returns the world, or
+// ErrWorldNotFound if the world cannot be found.
+func (r *repository) getWorldByID(ctx context.Context, id int) (w world, e error) {
+	rows, e := r.query(ctx, worldSelect+" WHERE world.id = ?", id)
+	if e != nil {
+		return
+	}
+	ds, e := scanWorldDtos(rows)
+	if e != nil {
+		return
+	}
+	if len(ds) == 0 {
+		return w, ErrWorldNotFound
+	}
+	return ds[0].convertToWorld(), nil
+}
+
+// getWorldsBySector gets all the (canonical) worlds for the named sector, in hex order.
+// It returns a slice of worlds.
+func (r *repository) getWorldsBySector(ctx context.Context, sector string) (ws []world, e error) {
+	ds, e := r.getWorldDtosBySector(ctx, sector)
+	for _, d := range ds {
+		ws = append(ws, d.convertToWorld())
+	}
+	return ws, e
+}
+
+// getWorldDtosBySector gets the database rows of all the (canonical) worlds for the named sector, in hex order.
+// Use this rather than getWorldsBySector when it matters which fields are empty in the database.
+func (r *repository) getWorldDtosBySector(ctx context.Context, sector string) ([]worldDto, error) {
+	rows, e := r.query(ctx, worldSelect+" WHERE sector.name = ? ORDER BY world.subsector_index, world.hex", sector)
 	if e != nil {
 		return nil, e
 	}
-	defer db.Close()
+	return scanWorldDtos(rows)
+}
+
+// getStellarDetail gets info for a particular star (for example "G2 V") from the stellar_detail table of the
+// database. It returns the detail in a stellarDto, or ErrStarNotFound if the star is not in the table.
+func (r *repository) getStellarDetail(ctx context.Context, star string) (s stellarDto, e error) {
+	// Brown dwarfs have no luminosity class, hence the left join.
+	e = r.queryRow(ctx, "SELECT stellar_detail.id, stellar_detail.name, COALESCE(stellar_luminosity.name, '') AS luminosity,"+
+		" stellar_spectral.name AS spectral, spectral_decimal, habitable_zone, min_zone, mass, stellar_detail.luminosity"+
+		" FROM stellar_detail JOIN stellar_spectral ON spectral_id=stellar_spectral.id"+
+		" LEFT JOIN stellar_luminosity ON luminosity_id=stellar_luminosity.id"+
+		" WHERE stellar_detail.name = ?", []interface{}{strings.ToUpper(star)},
+		&s.id, &s.name, &s.luminosity, &s.spectral, &s.spectralDecimal, &s.habitableZone, &s.minOrbit, &s.mass, &s.solarLuminosity)
+	if e == sql.ErrNoRows {
+		return s, ErrStarNotFound
+	}
+	return
+}
 
-	rows, e := db.Query("SELECT DISTINCT name, abbreviation FROM sector where is_detailed=1 ORDER BY name")
+// saveSystemDetail stores the generated detail for a star system alongside the canonical world it was generated
+// for. Any detail previously stored for the world is replaced. The canonical world row is never changed.
+func (r *repository) saveSystemDetail(ctx context.Context, sys *starSystem) error {
+	if sys.WorldID <= 0 {
+		return ErrWorldNotFound
+	}
+	js, e := json.Marshal(sys)
+	if e != nil {
+		return e
+	}
+	_, e = r.exec(ctx, "INSERT OR REPLACE INTO world_system (world_id, generated, system_json) VALUES (?, datetime('now'), ?)",
+		sys.WorldID, string(js))
+	return e
+}
+
+// getSystemDetail gets the generated star system detail stored for the world with the given database ID.
+// If no detail has been stored, ErrSystemNotFound is returned.
+func (r *repository) getSystemDetail(ctx context.Context, worldID int) (sys *starSystem, e error) {
+	var js string
+	e = r.queryRow(ctx, "SELECT system_json FROM world_system WHERE world_id = ?", []interface{}{worldID}, &js)
+	if e == sql.ErrNoRows {
+		return nil, ErrSystemNotFound
+	}
 	if e != nil {
 		return nil, e
 	}
-	defer rows.Close()
+	sys = &starSystem{}
+	if e = json.Unmarshal([]byte(js), sys); e != nil {
+		return nil, e
+	}
+	return sys, nil
+}
 
-	var name, abbreviation string
+// generatedSectorTag is the tag given to sectors created by the sector generator, to tell them apart from
+// the canonical sectors.
+const generatedSectorTag = "Generated"
 
-	for rows.Next() {
-		rows.Scan(&name, &abbreviation)
-		sm[name] = abbreviation
+// saveGeneratedSector saves a generated sector and its worlds to the database. If a generated sector of the same
+// name is already there, its worlds are replaced, and their stored systems and any campaign overlays are deleted.
+// Canonical sectors are never overwritten: ErrSectorCanonical is returned instead. The sector's ID is set from the
+// database.
+func (r *repository) saveGeneratedSector(ctx context.Context, s *sector) error {
+	var id int64
+	e := r.inTx(ctx, func(tx *repoTx) error {
+		var tags string
+		e := tx.queryRow("SELECT id, COALESCE(tags, '') FROM sector WHERE name = ?", []interface{}{s.name}, &id, &tags)
+		switch {
+		case e == sql.ErrNoRows:
+			// New sectors are placed in the M1105 milieu, named in Anglic, and are never part of the OTU.
+			res, e := tx.exec("INSERT INTO sector (name, abbreviation, x_loc, y_loc, milieu_id, lang_id, tags, is_detailed, otu)"+
+				" VALUES (?, ?, 0, 0, 6, 1, ?, 1, 0)", s.name, s.abbrev, generatedSectorTag)
+			if e != nil {
+				return e
+			}
+			if id, e = res.LastInsertId(); e != nil {
+				return e
+			}
+		case e != nil:
+			return e
+		case !strings.Contains(tags, generatedSectorTag):
+			return ErrSectorCanonical
+		default:
+			// The rows that belong to the old worlds go with them, as the new worlds are given new IDs.
+			for _, q := range []string{
+				"DELETE FROM world_system WHERE world_id IN (SELECT id FROM world WHERE sector_id = ?)",
+				"DELETE FROM world_overlay WHERE overlay_id IN (SELECT id FROM campaign_overlay WHERE sector_id = ?)",
+				"DELETE FROM campaign_overlay WHERE sector_id = ?",
+				"DELETE FROM world WHERE sector_id = ?",
+			} {
+				if _, e = tx.exec(q, id); e != nil {
+					return e
+				}
+			}
+			if _, e = tx.exec("DELETE FROM subsector WHERE sector_id = ?", id); e != nil {
+				return e
+			}
+		}
+
+		// Allegiances already in the table (such as the non-aligned codes) are left as they are.
+		for _, p := range s.polities {
+			if _, e = tx.exec("INSERT OR IGNORE INTO allegiance (code, legacy_code, allegiance_name) VALUES (?, ?, ?)",
+				p.code, p.legacyCode, p.name); e != nil {
+				return e
+			}
+		}
+
+		// Subsectors are saved in the first language of their mix. Languages not in the database are saved as Anglic.
+		for i, sub := range s.subsectors {
+			if sub.name == "" {
+				continue
+			}
+			lang := "Anglic"
+			if mix, err := parseLanguageMix(sub.language); err == nil && len(mix) > 0 {
+				lang = languageDbName(mix[0].Language)
+			}
+			if _, e = tx.exec("INSERT INTO subsector (name, lang_id, sector_id, subsector_index, capital_id, remarks)"+
+				" VALUES (?, COALESCE((SELECT id FROM language WHERE name = ?), 1), ?, ?, -1, ?)",
+				sub.name, lang, id, ssIndex[i], sub.remarks); e != nil {
+				return e
+			}
+		}
+
+		stmt, e := tx.stmt("INSERT INTO world (sector_id, subsector_index, hex, name, UWP, bases, remarks, zone, PBG, allegiance," +
+			" stars, importance, economics, culture, nobility, worlds, RU) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		if e != nil {
+			return e
+		}
+		for _, w := range s.worlds {
+			if _, e = stmt.ExecContext(ctx, id, w.subsectorIndex, w.hexLoc.String(), w.name, w.uwp.String(), w.bases,
+				w.remarks, w.zone.String(), w.pbg.String(), w.allegiance, w.systemStarString(), w.importance.String(),
+				w.economics.String(), w.culture.String(), w.nobility, w.worlds, w.ru); e != nil {
+				return e
+			}
+		}
+		return nil
+	})
+	if e != nil {
+		return e
 	}
+	s.id = int(id)
+	s.saved = true
+	return nil
+}
 
-	return sm, nil
+// saveSectorOverlay saves the accepted worlds of a sector completion as a new version of the campaign's overlay for
+// the sector. Only the fields that were missing from the canon are saved; the canonical world rows are never changed.
+// It returns the version saved.
+func (r *repository) saveSectorOverlay(ctx context.Context, c *sectorCompletion) (version int, e error) {
+	e = r.inTx(ctx, func(tx *repoTx) error {
+		if e := tx.queryRow("SELECT COALESCE(MAX(version), 0) + 1 FROM campaign_overlay WHERE campaign = ? AND sector_id = ?",
+			[]interface{}{c.campaign, c.sectorID}, &version); e != nil {
+			return e
+		}
+		res, e := tx.exec("INSERT INTO campaign_overlay (campaign, sector_id, version, created) VALUES (?, ?, ?, datetime('now'))",
+			c.campaign, c.sectorID, version)
+		if e != nil {
+			return e
+		}
+		overlayID, e := res.LastInsertId()
+		if e != nil {
+			return e
+		}
 
+		stmt, e := tx.stmt("INSERT INTO world_overlay (overlay_id, world_id, name, stars, importance, economics, culture," +
+			" nobility, worlds, RU, capital) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		if e != nil {
+			return e
+		}
+		for _, cw := range c.worlds {
+			if !cw.accepted || cw.missing == 0 {
+				continue
+			}
+			w := cw.world
+			// A nil interface is saved as NULL, so the canonical value is used.
+			field := func(f completionField, v interface{}) interface{} {
+				if cw.missing&f == 0 {
+					return nil
+				}
+				return v
+			}
+			capital := 0
+			if cw.missing&cfCapital != 0 {
+				capital = 1
+			}
+			if _, e = stmt.ExecContext(ctx, overlayID, cw.canon.id, field(cfName, w.name), field(cfStars, w.systemStarString()),
+				field(cfImportance, w.importance.String()), field(cfEconomics, w.economics.String()),
+				field(cfCulture, w.culture.String()), field(cfNobility, w.nobility), field(cfWorlds, w.worlds),
+				field(cfEconomics, w.ru), capital); e != nil {
+				return e
+			}
+		}
+		return nil
+	})
+	if e != nil {
+		return 0, e
+	}
+	return version, nil
 }
 
-// getSubsectorBySectorNameAndIndex gets the subsector that matches the sector and subsectorIndex.
-// It returns a subsector object or a blank one with error set.
-// func getSubsectorBySectorNameAndIndex(sector, idx string) (ss subsector, e error) {
+// importSectorMetadata saves the subsector names, allegiances and other names of the sector from Travellermap
+// metadata into the subsector, allegiance and sector_altname tables. A sector not in the database is added, but
+// the details of a sector already there are left as they are. Allegiances and names already there are kept.
+func (r *repository) importSectorMetadata(ctx context.Context, m *metadata.Sector) error {
+	name := m.Name()
+	if name == "" {
+		return ErrMetadataNoName
+	}
 
-// 	// Get the database connection
-// 	db, e := sql.Open(dbType, config.DatabaseFile)
-// 	if e != nil {
-// 		return
-// 	}
-// 	defer db.Close()
+	return r.inTx(ctx, func(tx *repoTx) error {
+		// Sectors without a known milieu are placed in M1105.
+		milieuID := 6
+		if e := tx.queryRow("SELECT id FROM milieu WHERE abbreviation = ?", []interface{}{m.Milieu()}, &milieuID); e != nil &&
+			e != sql.ErrNoRows {
+			return e
+		}
 
-// 	queryString := "SELECT subsector.id, subsector.name, subsector.remarks, language.name, subsector.capital_id" +
-// 		" FROM subsector,sector,language" +
-// 		" WHERE subsector.sector_id = sector.id AND" +
-// 		" subsector.lang_id = language.id AND" +
-// 		" sector.name = '" + sector + "' AND subsector.subsector_index = '" + idx + "'"
-// 	rows, e := db.Query(queryString)
-// 	if e != nil {
-// 		return
-// 	}
-// 	defer rows.Close()
+		var id int64
+		e := tx.queryRow("SELECT id FROM sector WHERE name = ?", []interface{}{name}, &id)
+		switch {
+		case e == sql.ErrNoRows:
+			abbrev := m.Abbreviation
+			if abbrev == "" {
+				abbrev = getAbbreviationForSector(name)
+			}
+			otu := 0
+			if strings.Contains(m.Tags, "OTU") {
+				otu = 1
+			}
+			res, e := tx.exec("INSERT INTO sector (name, abbreviation, x_loc, y_loc, milieu_id, lang_id, tags, is_detailed, otu)"+
+				" VALUES (?, ?, ?, ?, ?, 1, ?, 0, ?)", name, abbrev, m.X, m.Y, milieuID, m.Tags, otu)
+			if e != nil {
+				return e
+			}
+			if id, e = res.LastInsertId(); e != nil {
+				return e
+			}
+		case e != nil:
+			return e
+		}
 
-// 	for rows.Next() {
-// 		sub := &ss
-// 		rows.Scan(&sub.id, &sub.name, &sub.remarks, &sub.language, &sub.capitalID)
-// 	}
-// 	return
-// }
+		// New subsectors are named in Anglic, as the metadata does not give the language.
+		for _, sub := range m.Subsectors {
+			res, e := tx.exec("UPDATE subsector SET name = ? WHERE sector_id = ? AND subsector_index = ?", sub.Name, id, sub.Index)
+			if e != nil {
+				return e
+			}
+			n, e := res.RowsAffected()
+			if e != nil {
+				return e
+			}
+			if n > 0 {
+				continue
+			}
+			if _, e = tx.exec("INSERT INTO subsector (name, lang_id, sector_id, subsector_index, capital_id, remarks)"+
+				" VALUES (?, 1, ?, ?, -1, '')", sub.Name, id, sub.Index); e != nil {
+				return e
+			}
+		}
 
-// GetAllMajorRaces gets all the major races from the database and returns a slice of strings with their names.
-func GetAllMajorRaces() (rs []string, e error) {
+		for _, a := range m.Allegiances {
+			if _, e = tx.exec("INSERT OR IGNORE INTO allegiance (code, legacy_code, allegiance_name) VALUES (?, NULLIF(?, ''), ?)",
+				a.Code, a.Base, strings.TrimSpace(a.Name)); e != nil {
+				return e
+			}
+		}
 
-	// Get the database connection
-	db, e := sql.Open(dbType, dbFile)
+		for _, n := range m.Names {
+			if n.Lang == "" {
+				continue
+			}
+			if _, e = tx.exec("INSERT INTO sector_altname (sector_id, tags, milieu_id, name, lang_id)"+
+				" SELECT ?, ?, ?, ?, COALESCE((SELECT id FROM language WHERE abbreviation = ?), 1)"+
+				" WHERE NOT EXISTS (SELECT 1 FROM sector_altname WHERE sector_id = ? AND name = ?)",
+				id, m.Tags, milieuID, n.Text, n.Lang, id, n.Text); e != nil {
+				return e
+			}
+		}
+		return nil
+	})
+}
+
+// stageWorlds saves worlds being imported into the sector in the world_staging table, replacing any worlds already
+// staged for the sector.
+func (r *repository) stageWorlds(ctx context.Context, sectorID int, abbrev string, ws []world) error {
+	return r.inTx(ctx, func(tx *repoTx) error {
+		if _, e := tx.exec("DELETE FROM world_staging WHERE sector_id = ?", sectorID); e != nil {
+			return e
+		}
+		stmt, e := tx.stmt("INSERT INTO world_staging (sector_id, sector_code, subsector_index, hex, name, UWP, bases, remarks," +
+			" zone, PBG, allegiance, stars, importance, economics, culture, nobility, worlds, RU)" +
+			" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		if e != nil {
+			return e
+		}
+		for i := range ws {
+			rec := recordFromWorld(&ws[i])
+			if _, e = stmt.ExecContext(ctx, sectorID, abbrev, rec.SS, rec.Hex, rec.Name, rec.UWP, rec.Bases, rec.Remarks,
+				rec.Zone, rec.PBG, rec.Allegiance, rec.Stars, rec.Ix, rec.Ex, rec.Cx, rec.Nobility, rec.W, rec.RU); e != nil {
+				return e
+			}
+		}
+		return nil
+	})
+}
+
+// getWorldsInStagedHexes gets the worlds of the sector in the world table that are in the same hexes as worlds
+// staged for the sector in the world_staging table.
+func (r *repository) getWorldsInStagedHexes(ctx context.Context, sectorID int) (ws []world, e error) {
+	rows, e := r.query(ctx, worldSelect+" WHERE world.sector_id = ? AND world.hex IN"+
+		" (SELECT hex FROM world_staging WHERE sector_id = ?) ORDER BY world.hex", sectorID, sectorID)
 	if e != nil {
 		return nil, e
 	}
-	defer db.Close()
+	ds, e := scanWorldDtos(rows)
+	if e != nil {
+		return nil, e
+	}
+	for _, d := range ds {
+		ws = append(ws, d.convertToWorld())
+	}
+	return ws, nil
+}
 
-	rows, e := db.Query("SELECT DISTINCT race_name FROM race where is_major=1 ORDER BY race_name")
+// commitStagedWorlds moves the worlds staged for the sector from the world_staging table to the world table, and
+// marks the sector as detailed. Worlds already in the hexes of staged worlds are replaced if replace is true, or
+// else the staged worlds in those hexes are dropped. It returns the number of worlds committed.
+//
+// Worlds are replaced in place, keeping their IDs, so that campaign overlays for them are kept. Their stored systems
+// are deleted, as they were generated for the old data.
+func (r *repository) commitStagedWorlds(ctx context.Context, sectorID int, replace bool) (n int, e error) {
+	e = r.inTx(ctx, func(tx *repoTx) error {
+		if replace {
+			if _, e := tx.exec("DELETE FROM world_system WHERE world_id IN (SELECT id FROM world WHERE sector_id = ? AND"+
+				" hex IN (SELECT hex FROM world_staging WHERE sector_id = ?))", sectorID, sectorID); e != nil {
+				return e
+			}
+			res, e := tx.exec("UPDATE world SET (subsector_index, name, UWP, bases, remarks, zone, PBG, allegiance, stars,"+
+				" importance, economics, culture, nobility, worlds, RU) = (SELECT subsector_index, name, UWP, bases, remarks,"+
+				" zone, PBG, allegiance, stars, importance, economics, culture, nobility, worlds, RU FROM world_staging"+
+				" WHERE world_staging.sector_id = world.sector_id AND world_staging.hex = world.hex)"+
+				" WHERE sector_id = ? AND hex IN (SELECT hex FROM world_staging WHERE sector_id = ?)", sectorID, sectorID)
+			if e != nil {
+				return e
+			}
+			replaced, e := res.RowsAffected()
+			if e != nil {
+				return e
+			}
+			n = int(replaced)
+		}
+		if _, e := tx.exec("DELETE FROM world_staging WHERE sector_id = ? AND hex IN (SELECT hex FROM world WHERE sector_id = ?)",
+			sectorID, sectorID); e != nil {
+			return e
+		}
+		res, e := tx.exec("INSERT INTO world (sector_id, subsector_index, hex, name, UWP, bases, remarks, zone, PBG, allegiance,"+
+			" stars, importance, economics, culture, nobility, worlds, RU) SELECT sector_id, subsector_index, hex, name, UWP,"+
+			" bases, remarks, zone, PBG, allegiance, stars, importance, economics, culture, nobility, worlds, RU"+
+			" FROM world_staging WHERE sector_id = ?", sectorID)
+		if e != nil {
+			return e
+		}
+		affected, e := res.RowsAffected()
+		if e != nil {
+			return e
+		}
+		n += int(affected)
+		if _, e = tx.exec("UPDATE sector SET is_detailed = 1 WHERE id = ?", sectorID); e != nil {
+			return e
+		}
+		_, e = tx.exec("DELETE FROM world_staging WHERE sector_id = ?", sectorID)
+		return e
+	})
+	if e != nil {
+		return 0, e
+	}
+	return n, nil
+}
+
+// getLatestOverlay gets the worlds of the latest version of the campaign's overlay for the sector, keyed by world
+// ID. It returns an empty map if the campaign has no overlay for the sector.
+func (r *repository) getLatestOverlay(ctx context.Context, campaign string, sectorID int) (ovs map[int]worldOverlay, e error) {
+	rows, e := r.query(ctx, "SELECT world_id, COALESCE(name, ''), COALESCE(stars, ''), COALESCE(importance, ''),"+
+		" COALESCE(economics, ''), COALESCE(culture, ''), COALESCE(nobility, ''), COALESCE(worlds, -1), COALESCE(RU, -1),"+
+		" capital FROM world_overlay WHERE overlay_id = (SELECT id FROM campaign_overlay WHERE campaign = ? AND sector_id = ?"+
+		" ORDER BY version DESC LIMIT 1)", campaign, sectorID)
 	if e != nil {
 		return nil, e
 	}
 	defer rows.Close()
 
-	var name string
+	ovs = make(map[int]worldOverlay)
 	for rows.Next() {
-		rows.Scan(&name)
-		rs = append(rs, name)
+		var id, capital int
+		var o worldOverlay
+		if e = rows.Scan(&id, &o.name, &o.stars, &o.importance, &o.economics, &o.culture, &o.nobility, &o.worlds,
+			&o.ru, &capital); e != nil {
+			return nil, e
+		}
+		o.capital = capital != 0
+		ovs[id] = o
+	}
+	return ovs, rows.Err()
+}
+
+// getOTUWorldDtos gets the database rows of all the worlds in sectors of the Official Traveller Universe, leaving out
+// generated sectors.
+func (r *repository) getOTUWorldDtos(ctx context.Context) ([]worldDto, error) {
+	rows, e := r.query(ctx, worldSelect+" WHERE sector.otu = 1 ORDER BY world.id")
+	if e != nil {
+		return nil, e
 	}
-	return rs, nil
+	return scanWorldDtos(rows)
 }
 
-// GetAllCTSkills gets all the non-cascade skills from the database and returns a slice of strings.
-func GetAllCTSkills() (ss []string, e error) {
+// getAllSectorAltNames gets the other names of all the sectors from the sector_altname table, keyed by sector ID.
+func (r *repository) getAllSectorAltNames(ctx context.Context) (names map[int][]string, e error) {
+	rows, e := r.query(ctx, "SELECT sector_id, name FROM sector_altname ORDER BY sector_id, name")
+	if e != nil {
+		return nil, e
+	}
+	defer rows.Close()
+
+	names = make(map[int][]string)
+	for rows.Next() {
+		var id int
+		var name string
+		if e = rows.Scan(&id, &name); e != nil {
+			return nil, e
+		}
+		names[id] = append(names[id], name)
+	}
+	return names, rows.Err()
+}
 
-	// Get the database connection
-	db, e := sql.Open(dbType, dbFile)
+// getOverlayNames gets the names given to worlds by the latest versions of the campaign's overlays, for all
+// sectors, keyed by world ID.
+func (r *repository) getOverlayNames(ctx context.Context, campaign string) (names map[int]string, e error) {
+	rows, e := r.query(ctx, "SELECT world_overlay.world_id, world_overlay.name FROM world_overlay"+
+		" JOIN campaign_overlay ON campaign_overlay.id = world_overlay.overlay_id"+
+		" WHERE campaign_overlay.campaign = ? AND world_overlay.name IS NOT NULL AND campaign_overlay.version ="+
+		" (SELECT MAX(version) FROM campaign_overlay AS latest WHERE latest.campaign = campaign_overlay.campaign"+
+		" AND latest.sector_id = campaign_overlay.sector_id)", campaign)
 	if e != nil {
 		return nil, e
 	}
-	defer db.Close()
+	defer rows.Close()
+
+	names = make(map[int]string)
+	for rows.Next() {
+		var id int
+		var name string
+		if e = rows.Scan(&id, &name); e != nil {
+			return nil, e
+		}
+		names[id] = name
+	}
+	return names, rows.Err()
+}
 
-	rows, e := db.Query("SELECT skill_name FROM skill WHERE is_virtual=0 AND ruleset = (SELECT DISTINCT ID FROM ruleset WHERE abbreviation = 'CT') ORDER BY skill_name")
+// The world_search table is a full-text (FTS5) index of the worlds, with each world's row ID being its ID in the
+// world table. It is derived from the other tables, so it is created when first needed and can be rebuilt at any
+// time. world_search_vocab lists the terms in the index, for the fuzzy search. world_search_state has one row, with
+// the campaign the index was built for and whether it is stale; the triggers on the tables the index is built from
+// mark it stale whenever they change, however they are written to.
+const (
+	searchTableSQL = "CREATE VIRTUAL TABLE IF NOT EXISTS world_search USING fts5(name, folded, sector, altnames, remarks," +
+		" sophonts, notes, tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3')"
+	searchVocabSQL = "CREATE VIRTUAL TABLE IF NOT EXISTS world_search_vocab USING fts5vocab(world_search, row)"
+	searchStateSQL = "CREATE TABLE IF NOT EXISTS world_search_state (campaign TEXT, stale INTEGER NOT NULL)"
+	searchStateRow = "INSERT INTO world_search_state (campaign, stale) SELECT NULL, 1" +
+		" WHERE NOT EXISTS (SELECT 1 FROM world_search_state)"
+)
+
+// searchSourceTables are the tables the world_search table is built from.
+var searchSourceTables = []string{"world", "sector", "sector_altname", "campaign_overlay", "world_overlay"}
+
+// createSearchIndex creates the world_search table, its vocabulary and its state if they are not in the database
+// yet, with the triggers that mark it stale. A new index is stale until it is first built.
+func (r *repository) createSearchIndex(ctx context.Context) error {
+	qs := []string{searchTableSQL, searchVocabSQL, searchStateSQL, searchStateRow}
+	for _, t := range searchSourceTables {
+		for _, op := range []string{"INSERT", "UPDATE", "DELETE"} {
+			qs = append(qs, fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS world_search_stale_%s_%s AFTER %s ON %s"+
+				" BEGIN UPDATE world_search_state SET stale = 1; END", t, strings.ToLower(op), op, t))
+		}
+	}
+	db, e := r.conn(ctx)
+	if e != nil {
+		return e
+	}
+	for _, q := range qs {
+		// Statements that change the schema are not kept prepared.
+		if _, e = db.ExecContext(ctx, q); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// searchIndexCurrent reports whether the world_search table was built for the campaign and nothing it is built from
+// has changed since.
+func (r *repository) searchIndexCurrent(ctx context.Context, campaign string) (current bool, e error) {
+	e = r.queryRow(ctx, "SELECT COUNT(*) > 0 FROM world_search_state WHERE stale = 0 AND campaign = ?",
+		[]interface{}{campaign}, &current)
+	return
+}
+
+// replaceSearchIndex replaces everything in the world_search table with the entries, built for the campaign, and
+// records that the index is current.
+func (r *repository) replaceSearchIndex(ctx context.Context, campaign string, es []searchEntry) error {
+	return r.inTx(ctx, func(tx *repoTx) error {
+		if _, e := tx.exec("DELETE FROM world_search"); e != nil {
+			return e
+		}
+		stmt, e := tx.stmt("INSERT INTO world_search (rowid, name, folded, sector, altnames, remarks, sophonts, notes)" +
+			" VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+		if e != nil {
+			return e
+		}
+		for _, s := range es {
+			if _, e = stmt.ExecContext(ctx, s.worldID, s.name, s.folded, s.sector, s.altNames, s.remarks, s.sophonts,
+				s.notes); e != nil {
+				return e
+			}
+		}
+		_, e = tx.exec("UPDATE world_search_state SET campaign = ?, stale = 0", campaign)
+		return e
+	})
+}
+
+// getSearchTerms gets all the terms in the world_search table, with the number of worlds each is in.
+func (r *repository) getSearchTerms(ctx context.Context) (terms map[string]int, e error) {
+	rows, e := r.query(ctx, "SELECT term, doc FROM world_search_vocab")
 	if e != nil {
 		return nil, e
 	}
 	defer rows.Close()
 
-	var name string
+	terms = make(map[string]int)
+	for rows.Next() {
+		var term string
+		var n int
+		if e = rows.Scan(&term, &n); e != nil {
+			return nil, e
+		}
+		terms[term] = n
+	}
+	return terms, rows.Err()
+}
+
+// searchWorldDtos gets the database rows of the worlds matching the FTS5 query, best first, with the names they are
+// indexed under (which may be the names given by a campaign's overlay). Names are weighted most in the ranking,
+// then sectors, sophonts, remarks and notes. Worlds in generated sectors are never returned, as they are not canon.
+func (r *repository) searchWorldDtos(ctx context.Context, match string, limit int) (ds []worldDto, names []string, e error) {
+	rows, e := r.query(ctx, "SELECT "+worldSelectFields+", world_search.name"+worldSelectTables+
+		" JOIN world_search ON world_search.rowid = world.id WHERE world_search MATCH ? AND sector.otu = 1"+
+		" ORDER BY bm25(world_search, 10.0, 8.0, 3.0, 3.0, 1.0, 2.0, 1.0) LIMIT ?", match, limit)
+	if e != nil {
+		return nil, nil, e
+	}
+	defer rows.Close()
+
 	for rows.Next() {
-		rows.Scan(&name)
-		ss = append(ss, name)
+		var d worldDto
+		var name string
+		if e = rows.Scan(&d.id, &d.sectorID, &d.sector, &d.sectorNameAbbr, &d.subsectorIndex, &d.subsector, &d.hexLoc,
+			&d.name, &d.uwp, &d.bases, &d.remarks, &d.zone, &d.pbg, &d.allegiance, &d.stars, &d.importance, &d.economics,
+			&d.culture, &d.nobility, &d.worlds, &d.ru, &name); e != nil {
+			return nil, nil, e
+		}
+		ds = append(ds, d)
+		names = append(names, name)
 	}
-	return ss, nil
+	return ds, names, rows.Err()
 }
@@ -0,0 +1,180 @@
+package main
+
+// starSystem.go contains code for star systems, generating and encoding/decoding in JSON.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// starSystem defines a struct for a Star System. Fields are exported to enable encoding/decoding into/from JSON.
+// Examples are for Regina (where known or made up).
+type starSystem struct {
+	WorldID              int          // The database ID of the mainworld the system was generated for, eg 1234. Zero if not from the database.
+	Name                 string       // The name of the Star System, which is the name of the Homeworld, eg "Regina"
+	SectorAbbrev         string       // The canonical 4-letter abbreviation for the Sector, eg "Spin". Case insensitive (I think)
+	Sector               string       // The full name of the Sector, eg "Spinward Marches"
+	SectorHex            string       // The hex that the Star System occupies in the Sector, eg 1910
+	UWP                  string       // The mainworld Universal World Profile, eg "A788899-C"
+	TravelZone           string       // This will be "Red", "Amber" or (for Regina) "Green"
+	Bases                string       // This will list all the bases, eg "NS" (for both Navy and Scout)
+	TradeClassifications []string     // A slice of strings of the Trade Classifications for the Mainworld only, eg  {"Ri","Pa","Ph","An","Cp"}
+	PopulationDigit      int          // The Population digit (multiplier), eg 7
+	PlanetoidBelts       int          // The number of planetoid belts in the System, eg 0
+	GasGiants            int          // The number of Gass Giants in the System, eg 3
+	TotalWorlds          int          // Total number of worlds in the System, = Mainworld + gas giants + belts + other worlds (not satellites), eg 8
+	Allegiance           string       // A four- or two-character string denoting mainworld allegiance, eg "Im" or "",
+	Importance           int          // The Importance (integer value) for the mainworld, eg 4
+	Economic             economicExt  // The Economic extension for a world, eg {Resources:13,Labour:7,Infrastructure:14,Efficiency:4}
+	Cultural             cultureExt   // The Culturaul extension for the mainworld, eg {Homogenity:9,Acceptance:12,Strangeness:6,Symbols:14}
+	Nobility             string       // The Nobility string for the mainworld, eg "BcCeF"
+	ResourceUnits        int          // The calculated resource Units for the mainworld, eg
+	HabitalZoneVariance  int          // The variance from the Habitable Zone orbit, eg 0
+	Climate              string       // The world climate, eg Temperate
+	MainworldType        string       // Whether the world is a Planet or Close or Far Satellite of a Gas Giant or Big Planet, eg "Far Satellite"
+	SatelliteOrbit       string       // Iff the world is a Satellite, the Orbit designator, eg "Arr"
+	NativeStatus         string       // Indicates the type of Native Intelligent Life present, if any, eg "Natives"
+	Stars                []systemStar // The stars in the system, the Primary first, eg {"Regina-alpha", "F7 V"}
+	Bodies               []systemBody // The bodies (worlds, gas giants and belts) orbiting the stars of the system.
+}
+
+// systemStar defines a single star within a star system.
+type systemStar struct {
+	Name               string  // The name of the star, eg "Regina-alpha"
+	Position           string  // The position of the star in the system, one of the starPos constants, eg "Primary"
+	Spectral           string  // The spectral type and size of the star as it appears in the stars string, eg "F7 V"
+	Orbit              int     // The orbit around the Primary. The Primary and companion stars have an orbit of -1.
+	HabitableZoneOrbit int     // The habitable zone orbit for the star, eg 3
+	MinOrbit           int     // The innermost orbit available around the star, eg 0
+	Mass               float64 // The mass of the star in standard (Sol) masses, eg 1.3
+	Luminosity         float64 // The luminosity of the star in standard (Sol) luminosities, eg 2.1
+}
+
+// systemBody defines a body (planet, gas giant or planetoid belt) within a star system, or a satellite of one.
+type systemBody struct {
+	Star       int          // The index (into the system's Stars) of the star that the body orbits
+	Orbit      int          // The orbit number around the star
+	Name       string       // The name of the body, eg "Regina-alpha-4"
+	Type       string       // The world type, eg "Iceworld", "Large Gas Giant" or "Planetoid Belt"
+	UWP        string       // The UWP for the body, blank for gas giants
+	Mainworld  bool         // true if this body is the mainworld
+	SatOrbit   string       // Iff the body is a satellite, the satellite orbit designator, eg "Arr"
+	Satellites []systemBody // Any satellites of the body
+}
+
+// Constants for the positions of stars in a system.
+const (
+	starPosPrimary   = "Primary"
+	starPosClose     = "Close"
+	starPosNear      = "Near"
+	starPosFar       = "Far"
+	starPosCompanion = "Companion"
+)
+
+// Constants for the bodies in a system that are not worlds.
+const (
+	btLargeGasGiant = "Large Gas Giant"
+	btSmallGasGiant = "Small Gas Giant"
+	btIceGiant      = "Ice Giant"
+	btPlanetoidBelt = "Planetoid Belt"
+)
+
+// starGreek contains the suffixes used for naming the stars of a system, in order.
+var starGreek = [...]string{"alpha", "beta", "gamma", "delta", "epsilon", "zeta", "eta", "theta"}
+
+// isGasGiant returns true if the body is a gas giant of any type.
+func (b systemBody) isGasGiant() bool {
+	return b.Type == btLargeGasGiant || b.Type == btSmallGasGiant || b.Type == btIceGiant
+}
+
+// mainworld returns a pointer to the mainworld body of the system, which may be a satellite,
+// or nil if the system has no mainworld.
+func (s *starSystem) mainworld() *systemBody {
+	for i := range s.Bodies {
+		if s.Bodies[i].Mainworld {
+			return &s.Bodies[i]
+		}
+		for j := range s.Bodies[i].Satellites {
+			if s.Bodies[i].Satellites[j].Mainworld {
+				return &s.Bodies[i].Satellites[j]
+			}
+		}
+	}
+	return nil
+}
+
+// countBodies counts the gas giants, planetoid belts and total worlds in the system. Satellites are not counted
+// as worlds, except for the mainworld, which is always counted.
+func (s *starSystem) countBodies() (gasGiants, belts, worlds int) {
+	for _, b := range s.Bodies {
+		switch {
+		case b.isGasGiant():
+			gasGiants++
+		case b.Type == btPlanetoidBelt:
+			belts++
+		}
+		worlds++
+		for _, sat := range b.Satellites {
+			if sat.Mainworld {
+				worlds++
+			}
+		}
+	}
+	return
+}
+
+// checkConsistent checks that the system does not contradict the canonical data for the world it was
+// generated for. It returns ErrSystemInconsistent (wrapped with the reason) if it does. The stars are only checked
+// if the world has canonical stars.
+func (s *starSystem) checkConsistent(w world) error {
+	gg, belts, worlds := s.countBodies()
+	if gg != w.pbg.gasGiants {
+		return fmt.Errorf("%w: %d gas giants, expected %d", ErrSystemInconsistent, gg, w.pbg.gasGiants)
+	}
+	if belts != w.pbg.planetoids {
+		return fmt.Errorf("%w: %d planetoid belts, expected %d", ErrSystemInconsistent, belts, w.pbg.planetoids)
+	}
+	if w.worlds > 0 && worlds != w.worlds {
+		return fmt.Errorf("%w: %d worlds, expected %d", ErrSystemInconsistent, worlds, w.worlds)
+	}
+	if mw := s.mainworld(); mw == nil || mw.UWP != w.uwp.String() {
+		return fmt.Errorf("%w: mainworld missing or UWP changed", ErrSystemInconsistent)
+	}
+	// A world with no canonical stars is given a generated primary, so there is nothing to compare it with.
+	canon := flattenStars(w.stars)
+	if len(canon) == 0 {
+		return nil
+	}
+	var stars, expected []string
+	for _, st := range s.Stars {
+		stars = append(stars, st.Spectral)
+	}
+	for _, star := range canon {
+		expected = append(expected, star.String())
+	}
+	if strings.Join(stars, " ") != strings.Join(expected, " ") {
+		return fmt.Errorf("%w: stars %q, expected %q", ErrSystemInconsistent, strings.Join(stars, " "), w.systemStarString())
+	}
+	return nil
+}
+
+// String returns a short multi-line listing of the system, one line per star and body.
+func (s *starSystem) String() (str string) {
+	for i, st := range s.Stars {
+		str += fmt.Sprintf("%s (%s) %s", st.Name, st.Position, st.Spectral)
+		if st.Orbit >= 0 {
+			str += fmt.Sprintf(" in orbit %d", st.Orbit)
+		}
+		str += "\n"
+		for _, b := range s.Bodies {
+			if b.Star != i {
+				continue
+			}
+			str += fmt.Sprintf("  %2d %-16s %-10s %s\n", b.Orbit, b.Type, b.UWP, b.Name)
+			for _, sat := range b.Satellites {
+				str += fmt.Sprintf("     %-4s %-11s %-10s %s\n", sat.SatOrbit, sat.Type, sat.UWP, sat.Name)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,539 @@
+package main
+
+// stars.go contains code for stars and star systems
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"trav2/cmd/traveller/tools"
+)
+
+// starDetail stores details of a star in a system
+type starDetail struct {
+	spectralType    string      // The Star Type from : O B A F G K M BD. If BD, size will be zero.
+	spectralDecimal int         // The decimal value for spectral 0 to 9. This will be 0 for D (dwarf) size stars
+	size            string      // The star size from one of these luminosity classes : Ia Ib II III IV V VI D
+	description     string      // The description of the star
+	companion       *starDetail // The companion star
+	orbit           int         // Orbit number (in the Primary's system) for Non-Primary stars
+	position        string      // The position of the star in the system, one of the starPos constants
+	notation        string      // The star as it appeared in the stars string it was parsed from, if any
+	//	mass            float64     // The mass of the star (in earth masses)
+}
+
+// satelliteOrbit is an array of names for Satellite Orbits.
+var satelliteOrbit [26]string
+
+// satelliteOrbitMultiplier is a corresponding array for Orbit multipliers.
+var satelliteOrbitMultiplier [26]int
+
+// init intialises the structures needed for this module.
+func init() {
+	satelliteOrbit[0] = "Ay"
+	satelliteOrbit[1] = "Bee"
+	satelliteOrbit[2] = "Cee"
+	satelliteOrbit[3] = "Dee"
+	satelliteOrbit[4] = "Ee"
+	satelliteOrbit[5] = "Eff"
+	satelliteOrbit[6] = "Gee"
+	satelliteOrbit[7] = "Aitch"
+	satelliteOrbit[8] = "Eye"
+	satelliteOrbit[9] = "Jay"
+	satelliteOrbit[10] = "Kay"
+	satelliteOrbit[11] = "Ell"
+	satelliteOrbit[12] = "Em"
+	satelliteOrbit[13] = "En"
+	satelliteOrbit[14] = "Oh"
+	satelliteOrbit[15] = "Pee"
+	satelliteOrbit[16] = "Que"
+	satelliteOrbit[17] = "Arr"
+	satelliteOrbit[18] = "Ess"
+	satelliteOrbit[19] = "Tee"
+	satelliteOrbit[20] = "Yu"
+	satelliteOrbit[21] = "Vee"
+	satelliteOrbit[22] = "Dub"
+	satelliteOrbit[23] = "Ex"
+	satelliteOrbit[24] = "Wye"
+	satelliteOrbit[25] = "Zee"
+
+	satelliteOrbitMultiplier[0] = 1
+	satelliteOrbitMultiplier[1] = 2
+	satelliteOrbitMultiplier[2] = 3
+	satelliteOrbitMultiplier[3] = 4
+	satelliteOrbitMultiplier[4] = 5
+	satelliteOrbitMultiplier[5] = 6
+	satelliteOrbitMultiplier[6] = 8
+	satelliteOrbitMultiplier[7] = 10
+	satelliteOrbitMultiplier[8] = 20
+	satelliteOrbitMultiplier[9] = 30
+	satelliteOrbitMultiplier[10] = 40
+	satelliteOrbitMultiplier[11] = 50
+	satelliteOrbitMultiplier[12] = 60
+	satelliteOrbitMultiplier[13] = 70
+	satelliteOrbitMultiplier[14] = 80
+	satelliteOrbitMultiplier[15] = 100
+	satelliteOrbitMultiplier[16] = 150
+	satelliteOrbitMultiplier[17] = 200
+	satelliteOrbitMultiplier[18] = 250
+	satelliteOrbitMultiplier[19] = 300
+	satelliteOrbitMultiplier[20] = 400
+	satelliteOrbitMultiplier[21] = 500
+	satelliteOrbitMultiplier[22] = 600
+	satelliteOrbitMultiplier[23] = 700
+	satelliteOrbitMultiplier[24] = 800
+	satelliteOrbitMultiplier[25] = 1000
+}
+
+// getDescription gets the description of the star based on its size.
+func (s starDetail) getDescription() string {
+	switch s.size {
+	case "Ia":
+		return "Bright Supergiant"
+	case "Ib":
+		return "Supergiant"
+	case "II":
+		return "Bright Giant"
+	case "III":
+		return "Giant"
+	case "IV":
+		return "Sub-giant"
+	case "V":
+		return "Main Sequence"
+	case "VI":
+		return "Sub-dwarf"
+	case "D":
+		return "White Dwarf"
+	default:
+		if s.spectralType == "BD" {
+			return "Brown Dwarf"
+		}
+		return "Unknown"
+	}
+}
+
+// String shows brief star type (luminosity and size) info for a star.
+func (s starDetail) String() string {
+	if s.spectralType == "BD" {
+		return s.spectralType
+	}
+	if s.size == "D" {
+		return s.size + s.spectralType
+	}
+	decimal := strconv.Itoa(s.spectralDecimal)
+	return s.spectralType + decimal + " " + s.size
+}
+
+// StarString shows brief details about all of a system's stars.
+func StarString(ss []*starDetail) (ret string) {
+	ret = ""
+	for i, star := range ss {
+		if i != 0 {
+			ret = ret + " "
+		}
+		ret = ret + star.canonString()
+		// Print out the companion(s) -- could potentially be a endless linked list.
+		for {
+			if star.companion == nil {
+				break
+			}
+			star = star.companion
+			ret = ret + " " + star.canonString()
+		}
+	}
+	return
+}
+
+// determineStar generates Homestar spectral class and luminosity (or type and size). A DM (usually -1 to +1) can be added to the rolls, and flux for the
+// Primary star is given. Set isHomestar to true if homestar (Primary). Returns a starDetail structure containing the type/spectral and size/luminosity
+// details for the star.
+func determineStar(r *rand.Rand, dm int, specFlux int, sizeFlux int, isHomestar bool) (s starDetail) {
+
+	// Our "roll" for the Star Spectral Type
+	roll := dm + specFlux
+	if !isHomestar {
+		roll = specFlux + tools.RollD6(r) + 1
+	}
+	if roll < -6 {
+		roll = -6
+	}
+	if roll > 6 {
+		roll = 6
+	}
+
+	// Our roll for the Star Spectal decimal
+	s.spectralDecimal = tools.RollDice(r, 10) - 1 // May be ignored for Dwarfs
+
+	// Determine the star spectral type
+	if isHomestar {
+		switch roll {
+		case -6:
+			s.spectralType = "O"
+		case -5:
+			if tools.RollDice(r, 2) == 2 {
+				s.spectralType = "O"
+			} else {
+				s.spectralType = "B"
+			}
+		case -4, -3:
+			s.spectralType = "A"
+		case -2, -1:
+			s.spectralType = "F"
+		case 0:
+			s.spectralType = "G"
+		case 1, 2:
+			s.spectralType = "K"
+		default:
+			s.spectralType = "M"
+		}
+	} else {
+		switch roll {
+		case -6:
+			if tools.RollDice(r, 2) == 2 {
+				s.spectralType = "O"
+			} else {
+				s.spectralType = "B"
+			}
+		case -5, -4:
+			s.spectralType = "A"
+		case -3, -2:
+			s.spectralType = "F"
+		case -1, 0:
+			s.spectralType = "G"
+		case 1, 2:
+			s.spectralType = "K"
+		case 3, 4, 5:
+			s.spectralType = "M"
+		default:
+			s.spectralType = "BD"
+			// Ignore remaining rolls
+			s.size = ""
+			s.spectralDecimal = 0
+			s.description = "Brown Dwarf"
+			//s.mass = s.getMass()
+			return
+		}
+	}
+
+	// Now determine the star size
+	roll = sizeFlux
+	if !isHomestar {
+		roll = sizeFlux + tools.RollD6(r) + 2
+	}
+	if roll > 6 {
+		roll = 6
+	}
+	if roll < -5 {
+		roll = -5
+	}
+
+	switch roll {
+	case -5:
+		switch s.spectralType {
+		case "O", "B", "A":
+			s.size = "Ia"
+		default:
+			s.size = "II"
+		}
+	case -4:
+		switch s.spectralType {
+		case "O", "B", "A":
+			s.size = "Ib"
+		case "M":
+			s.size = "II"
+		default:
+			s.size = "III"
+		}
+	case -3:
+		if s.spectralType == "F" || s.spectralType == "G" {
+			s.size = "IV"
+		} else {
+			s.size = "II"
+			if s.spectralType == "K" {
+				if s.spectralDecimal >= 5 {
+					s.size = "V"
+				} else {
+					s.size = "IV"
+				}
+			}
+		}
+	case -2:
+		if s.spectralType == "F" || s.spectralType == "G" || s.spectralType == "K" {
+			s.size = "V"
+		} else {
+			s.size = "III"
+		}
+	case -1:
+		switch s.spectralType {
+		case "O", "B":
+			s.size = "III"
+		case "A":
+			s.size = "IV"
+		default:
+			s.size = "V"
+		}
+	case 0:
+		if s.spectralType == "O" || s.spectralType == "B" {
+			s.size = "III"
+		} else {
+			s.size = "V"
+		}
+	case 1:
+		if s.spectralType == "B" {
+			s.size = "III"
+		} else {
+			s.size = "V"
+		}
+	case 2, 3:
+		s.size = "V"
+	case 4:
+		switch s.spectralType {
+		case "A":
+			s.size = "V"
+		case "O", "B":
+			s.size = "IV"
+		case "F":
+			if s.spectralDecimal < 5 {
+				s.size = "V"
+			} else {
+				s.size = "VI"
+			}
+		default:
+			s.size = "VI"
+		}
+	case 5:
+		s.size = "D"
+	default:
+		if isHomestar {
+			s.size = "D"
+		} else {
+			switch s.spectralType { // Stars other than primary
+			case "O", "B":
+				s.size = "IV"
+			case "A":
+				s.size = "V"
+			default:
+				s.size = "VI"
+				if s.spectralDecimal < 5 {
+					s.size = "V"
+				}
+			}
+		}
+	}
+	if s.size == "D" {
+		s.spectralDecimal = 0
+	}
+	s.description = s.getDescription()
+	//s.mass = s.getMass()
+	if isHomestar {
+		s.orbit = -1
+		s.position = starPosPrimary
+	}
+	return
+}
+
+// parseStars takes a string containing the list of star(s) for a world, and populates a slice of pointers to starDetail structs. This slice is returned.
+// The difficulty (or note to be taken) is that the string containing a list of stars for a system contains no other information other than the type and
+// size, and the number. Information about how far any companion stars are from the primary, or in fact whether a particular star is a companion star, or
+// the orbits occupied by particular stars are not found in the string, and so has to be guessed or determined. The stars are arranged using T5 rules,
+// see arrangeStars. Any parts of the string that cannot be parsed are logged and skipped.
+func parseStars(s string) (ss []*starDetail) {
+	flat, err := parseStarsString(s)
+	if err != nil {
+		log.Printf("Problem parsing stars %q: %v", s, err)
+	}
+	return arrangeStars(tools.RNG(), flat, RulesTraveller5)
+}
+
+// parseStarsString parses a stars string into the list of stars, in the order they appear. The stars may appear in any of these formats
+// (where x is the spectral type O B A F G K M, y the spectral decimal, and z the size Ia Ib II III IV V VI):
+//
+//	"xy z"  "xyz"  "x z"  - a normal star, eg "G2 V", "M5V" or "K V" (decimal 0)
+//	"D"  "Dx"  "xy D"     - a white dwarf, eg "D", "DM" or (legacy) "M2 D"
+//	"BD"                  - a brown dwarf
+//
+// Parts that cannot be parsed are skipped, and returned in an ErrInvalidStars error along with the stars that could be parsed.
+func parseStarsString(s string) (ss []*starDetail, err error) {
+
+	var bad []string
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' })
+
+	for i := 0; i < len(parts); i++ {
+		str := parts[i]
+		star := &starDetail{notation: str}
+
+		switch {
+		case str == "BD":
+			star.spectralType = "BD"
+		case str[0] == 'D' && (len(str) == 1 || (len(str) == 2 && isSpectralType(str[1:]))):
+			star.size = "D"
+			star.spectralType = str[1:]
+		case isSpectralType(str[0:1]):
+			star.spectralType = str[0:1]
+			rest := str[1:]
+			// Spectral decimal, which may be missing or (rarely) fractional.
+			if len(rest) > 0 && rest[0] >= '0' && rest[0] <= '9' {
+				star.spectralDecimal = int(rest[0] - '0')
+				rest = strings.TrimLeft(rest[1:], ".0123456789")
+			}
+			// Size, which may be attached or the next part of the string.
+			if rest == "" && i+1 < len(parts) && isStarSize(parts[i+1]) {
+				i++
+				rest = parts[i]
+				star.notation += " " + rest
+			}
+			if !isStarSize(rest) {
+				bad = append(bad, star.notation)
+				continue
+			}
+			star.size = rest
+			if star.size == "D" {
+				// Legacy white dwarf notation.
+				star.spectralDecimal = 0
+			}
+		default:
+			bad = append(bad, str)
+			continue
+		}
+		star.description = star.getDescription()
+		ss = append(ss, star)
+	}
+
+	if len(bad) > 0 {
+		err = fmt.Errorf("%w: %s", ErrInvalidStars, strings.Join(bad, ", "))
+	}
+	return
+}
+
+// isSpectralType returns true if the string is one of the spectral types O B A F G K M.
+func isSpectralType(s string) bool {
+	return len(s) == 1 && strings.Contains("OBAFGKM", s)
+}
+
+// isStarSize returns true if the string is one of the star sizes (luminosity classes).
+func isStarSize(s string) bool {
+	switch s {
+	case "O", "Ia", "Ib", "II", "III", "IV", "V", "VI", "D":
+		return true
+	}
+	return false
+}
+
+// arrangeStars arranges a list of stars (in stars string order) into a hierarchy: the primary first, then the close, near and far
+// stars, each followed by its companion if it has one. The stars string does not say which stars are companions, so they are
+// decided by the rules, and orbits placed:
+//
+//   - Traveller5 (and others): up to three stars after the primary are the close (orbit 1D-1), near (1D+5) and far (1D+11) stars,
+//     and any more are companions, to the primary first.
+//   - Classic Traveller (Book 6): up to two stars after the primary are placed on the companion orbit table, and any more are
+//     companions, to the far stars first and then the primary. Should there be too many stars for this, a third is placed.
+//
+// The order of the stars is kept, so StarString rebuilds the original stars string. It returns the top-level stars, with any
+// companions linked from them.
+func arrangeStars(r *rand.Rand, flat []*starDetail, rules Ruleset) (ss []*starDetail) {
+
+	if len(flat) == 0 {
+		return nil
+	}
+
+	// Work out the top-level stars, with their positions and orbits.
+	maxSecondaries := 3
+	if rules == RulesClassic && len(flat) <= 6 {
+		maxSecondaries = 2
+	}
+	if len(flat) > 2*(1+maxSecondaries) {
+		log.Printf("Too many stars (%d) to arrange, ignoring %s", len(flat), StarString(flat[2*(1+maxSecondaries):]))
+		flat = flat[:2*(1+maxSecondaries)]
+	}
+	secondaries := len(flat) - 1
+	if secondaries > maxSecondaries {
+		secondaries = maxSecondaries
+	}
+	top := []starDetail{{position: starPosPrimary, orbit: -1}}
+	for i := 0; i < secondaries; i++ {
+		var t starDetail
+		if rules == RulesClassic {
+			t.orbit = ctCompanionOrbit(r, i == 1)
+			t.position = positionForOrbit(t.orbit)
+		} else {
+			t.position = [...]string{starPosClose, starPosNear, starPosFar}[i]
+			t.orbit = [...]int{tools.RollD6(r) - 1, tools.RollD6(r) + 5, tools.RollD6(r) + 11}[i]
+		}
+		top = append(top, t)
+	}
+
+	// Decide which of the top-level stars have companions.
+	hasCompanion := make([]bool, len(top))
+	companions := len(flat) - len(top)
+	if rules == RulesClassic {
+		for i := range top {
+			if companions > 0 && top[i].position == starPosFar {
+				hasCompanion[i] = true
+				companions--
+			}
+		}
+	}
+	for i := range top {
+		if companions > 0 && !hasCompanion[i] {
+			hasCompanion[i] = true
+			companions--
+		}
+	}
+
+	// Now lay out the stars in order.
+	next := 0
+	for i, t := range top {
+		star := flat[next]
+		star.position, star.orbit, star.companion = t.position, t.orbit, nil
+		ss = append(ss, star)
+		next++
+		if hasCompanion[i] {
+			comp := flat[next]
+			comp.position, comp.orbit, comp.companion = starPosCompanion, -1, nil
+			star.companion = comp
+			next++
+		}
+	}
+	return
+}
+
+// ctCompanionOrbit rolls the orbit of a companion star on the Classic Traveller Book 6 companion orbit table. The tertiary
+// star gets DM+4. Close companions are in orbit 0, and far companions are placed beyond orbit 13.
+func ctCompanionOrbit(r *rand.Rand, tertiary bool) int {
+	roll := tools.RollD6(r) + tools.RollD6(r)
+	if tertiary {
+		roll += 4
+	}
+	switch {
+	case roll <= 3:
+		return 0
+	case roll <= 6:
+		return roll - 3
+	case roll <= 11:
+		return roll - 3 + tools.RollD6(r)
+	default:
+		return 13 + tools.RollD6(r)/2
+	}
+}
+
+// positionForOrbit returns the position (close, near or far) of a star in the given orbit around the primary.
+func positionForOrbit(orbit int) string {
+	switch {
+	case orbit <= 5:
+		return starPosClose
+	case orbit <= 11:
+		return starPosNear
+	default:
+		return starPosFar
+	}
+}
+
+// canonString returns the star as it appeared in the stars string it was parsed from, or the standard form if it was not parsed.
+func (s starDetail) canonString() string {
+	if s.notation != "" {
+		return s.notation
+	}
+	return s.String()
+}
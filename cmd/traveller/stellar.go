@@ -0,0 +1,212 @@
+package main
+
+// stellar.go contains the physical model for stars: luminosity, temperature, radius, mass and lifetime.
+// The model is backed by the stellar_detail table, with values interpolated across spectral decimals
+// where the table has gaps. Orbit distances (in AU) for each orbit number are also here.
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// solarTemperature is the effective surface temperature of Sol, in K.
+const solarTemperature = 5772
+
+// solarLifetime is the main sequence lifetime of Sol, in billions of years.
+const solarLifetime = 10.0
+
+// Radii (in solar radii) for the degenerate stars, which do not follow the luminosity and temperature relation.
+const (
+	whiteDwarfRadius = 0.0126
+	brownDwarfRadius = 0.1
+)
+
+// Temperatures (in K) for stars that have no spectral class.
+const (
+	whiteDwarfTemperature = 10000
+	brownDwarfTemperature = 1500
+)
+
+// spectralTemperatures contains the effective surface temperature (in K) at decimal 0 of each spectral class,
+// in OBAFGKM order, followed by the temperature at M9.
+var spectralTemperatures = [...]float64{40000, 30000, 9700, 7200, 5900, 5250, 3850, 2400}
+
+// orbitAU contains the mean distance (in AU) of each orbit number.
+var orbitAU = [...]float64{0.2, 0.4, 0.7, 1.0, 1.6, 2.8, 5.2, 10.0, 19.6, 38.8, 77.2, 154.0, 307.6, 614.8, 1229.2, 2458.0, 4915.6, 9830.8, 19661.2, 39322.0}
+
+// stellarModel contains the physical details of a star. Fields are exported to enable encoding into JSON.
+type stellarModel struct {
+	Spectral        string  // The spectral type and size of the star, eg "G2 V"
+	Description     string  // The description of the star's size, eg "Main Sequence"
+	Mass            float64 // The mass of the star, in solar masses
+	Luminosity      float64 // The luminosity of the star, in solar luminosities
+	Temperature     float64 // The effective surface temperature, in K
+	Radius          float64 // The radius of the star, in solar radii
+	Lifetime        float64 // The lifetime of the star on the main sequence, in billions of years. 0 for dwarf remnants.
+	HabitableZone   int     // The habitable zone orbit number
+	MinOrbit        int     // The innermost orbit number available
+	HabitableZoneAU float64 // The distance (in AU) at which a world receives the same light as Earth
+}
+
+// stellarCache holds the stellar models already worked out, keyed on spectral type.
+var stellarCache = struct {
+	sync.Mutex
+	models map[string]stellarModel
+}{models: make(map[string]stellarModel)}
+
+// newStellarModel works out the physical model for the star with the given spectral type and size, for
+// example "G2 V", "DA" (or just "D") for a white dwarf, or "BD" for a brown dwarf. If the table has no row
+// for the star, the nearest spectral decimal with the same class and size is used. It returns ErrStarNotFound
+// if the star cannot be modelled.
+func newStellarModel(spectral string) (sm stellarModel, err error) {
+
+	spectral = strings.ToUpper(strings.TrimSpace(spectral))
+	if spectral == "D" {
+		spectral = "DA"
+	}
+	stellarCache.Lock()
+	defer stellarCache.Unlock()
+	if sm, ok := stellarCache.models[spectral]; ok {
+		return sm, nil
+	}
+
+	detail, err := repo.getStellarDetail(context.Background(), spectral)
+	decimal := -1
+	if err == ErrStarNotFound {
+		detail, decimal, err = nearestStellarDetail(spectral)
+	}
+	if err != nil {
+		return sm, err
+	}
+	if decimal < 0 {
+		decimal = detail.spectralDecimal
+	}
+
+	sm.Spectral = spectral
+	sm.Mass = detail.mass
+	sm.Luminosity = detail.solarLuminosity
+	sm.HabitableZone = detail.habitableZone
+	sm.MinOrbit = detail.minOrbit
+	sm.HabitableZoneAU = math.Sqrt(sm.Luminosity)
+
+	switch {
+	case spectral == "BD":
+		sm.Description = "Brown Dwarf"
+		sm.Radius = brownDwarfRadius
+		sm.Temperature = temperatureFromRadius(sm.Luminosity, sm.Radius)
+	case strings.HasPrefix(spectral, "D"):
+		sm.Description = "White Dwarf"
+		sm.Radius = whiteDwarfRadius * math.Pow(math.Max(sm.Mass, 0.1), -1.0/3)
+		sm.Temperature = temperatureFromRadius(sm.Luminosity, sm.Radius)
+	default:
+		sm.Description = starDetail{size: detail.luminosity}.getDescription()
+		sm.Temperature = spectralTemperature(detail.spectral, decimal)
+		sm.Radius = math.Sqrt(sm.Luminosity) * math.Pow(solarTemperature/sm.Temperature, 2)
+		if sm.Luminosity > 0 {
+			sm.Lifetime = solarLifetime * sm.Mass / sm.Luminosity
+		}
+	}
+
+	stellarCache.models[spectral] = sm
+	return sm, nil
+}
+
+// nearestStellarDetail finds the row of the stellar_detail table with the same spectral class and size as
+// the star, and the nearest spectral decimal. It returns the row and the decimal that was asked for.
+func nearestStellarDetail(spectral string) (detail stellarDto, decimal int, err error) {
+	var class, size string
+	if n, _ := fmt.Sscanf(spectral, "%1s%1d %s", &class, &decimal, &size); n != 3 {
+		return detail, -1, ErrStarNotFound
+	}
+	for d := 1; d <= 9; d++ {
+		for _, try := range []int{decimal - d, decimal + d} {
+			if try < 0 || try > 9 {
+				continue
+			}
+			if detail, err = repo.getStellarDetail(context.Background(), fmt.Sprintf("%s%d %s", class, try, size)); err == nil {
+				return detail, decimal, nil
+			}
+		}
+	}
+	return detail, -1, ErrStarNotFound
+}
+
+// spectralTemperature returns the effective surface temperature (in K) for the spectral class and decimal,
+// interpolated between the classes. Stars without a spectral class are given a brown dwarf temperature.
+func spectralTemperature(spectral string, decimal int) float64 {
+	idx := strings.Index("OBAFGKM", spectral)
+	if idx < 0 || spectral == "" {
+		return brownDwarfTemperature
+	}
+	steps := 10.0
+	if spectral == "M" {
+		steps = 9
+	}
+	t0, t1 := spectralTemperatures[idx], spectralTemperatures[idx+1]
+	return t0 + (t1-t0)*float64(decimal)/steps
+}
+
+// temperatureFromRadius returns the effective surface temperature (in K) of a star with the luminosity and
+// radius (both in solar units), from the Stefan-Boltzmann law.
+func temperatureFromRadius(luminosity, radius float64) float64 {
+	if luminosity <= 0 || radius <= 0 {
+		return 0
+	}
+	return solarTemperature * math.Pow(luminosity/(radius*radius), 0.25)
+}
+
+// orbitToAU converts an orbit number to a distance in AU. Fractional orbits are interpolated between orbits.
+func orbitToAU(orbit float64) float64 {
+	if orbit <= 0 {
+		return orbitAU[0] * (1 + orbit)
+	}
+	last := float64(len(orbitAU) - 1)
+	if orbit >= last {
+		return orbitAU[len(orbitAU)-1] * math.Pow(2, orbit-last)
+	}
+	i := int(orbit)
+	return orbitAU[i] + (orbitAU[i+1]-orbitAU[i])*(orbit-float64(i))
+}
+
+// auToOrbit converts a distance in AU to a (fractional) orbit number. It is the inverse of orbitToAU.
+func auToOrbit(au float64) float64 {
+	if au <= orbitAU[0] {
+		return au/orbitAU[0] - 1
+	}
+	last := len(orbitAU) - 1
+	if au >= orbitAU[last] {
+		return float64(last) + math.Log2(au/orbitAU[last])
+	}
+	i := 0
+	for au > orbitAU[i+1] {
+		i++
+	}
+	return float64(i) + (au-orbitAU[i])/(orbitAU[i+1]-orbitAU[i])
+}
+
+// starDiameterKm returns the diameter of a star with the given spectral type (for example "G2 V"), in km.
+func starDiameterKm(spectral string) (float64, error) {
+	sm, err := newStellarModel(spectral)
+	if err != nil {
+		return 0, err
+	}
+	return sm.Radius * solarDiameterKm, nil
+}
+
+// model returns the physical model for the star.
+func (s starDetail) model() (stellarModel, error) {
+	return newStellarModel(s.String())
+}
+
+// String returns the stellar model as a short multi-line description.
+func (sm stellarModel) String() string {
+	str := fmt.Sprintf("%s (%s)\nMass %.3g, luminosity %.3g, radius %.3g (solar)\nTemperature %.0f K, HZ orbit %d (%.2f AU)",
+		sm.Spectral, sm.Description, sm.Mass, sm.Luminosity, sm.Radius, sm.Temperature, sm.HabitableZone, sm.HabitableZoneAU)
+	if sm.Lifetime > 0 {
+		str += fmt.Sprintf("\nLifetime %.3g billion years", sm.Lifetime)
+	}
+	return str
+}
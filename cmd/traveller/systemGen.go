@@ -0,0 +1,436 @@
+package main
+
+// systemGen.go contains code for generating the full star system for a mainworld. Canonical (OTU)
+// mainworlds already have their UWP, stars, PBG and number of worlds determined, so the system is
+// built around those fields and never contradicts them.
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"trav2/cmd/traveller/tools"
+)
+
+// maxSystemOrbit is the outermost orbit that bodies may be placed in around the primary.
+const maxSystemOrbit = 19
+
+// systemGenAttempts is the number of attempts made at generating a consistent system before giving up.
+const systemGenAttempts = 5
+
+// orbitSlots tracks which orbits around each of the stars of a system are available to be filled.
+type orbitSlots struct {
+	free [][]bool // Indexed by star, then orbit. true if the orbit is available.
+	hz   []int    // The habitable zone orbit for each star.
+}
+
+// generateCanonSystemForWorld generates the full star system for the canonical world with the given
+// database ID, and stores it alongside the world. The world itself is not changed. It returns the
+// system generated.
+func generateCanonSystemForWorld(worldID int) (*starSystem, error) {
+
+	w, err := repo.getWorldByID(context.Background(), worldID)
+	if err != nil {
+		return nil, err
+	}
+	sys, err := generateCanonSystem(w)
+	if err != nil {
+		return nil, err
+	}
+	if err = repo.saveSystemDetail(context.Background(), sys); err != nil {
+		return nil, err
+	}
+	log.Printf("System generated for %s (%s %s)", w.name, w.sector, w.hexLoc.String())
+	return sys, nil
+}
+
+// generateCanonSystem generates the full star system for a world whose mainworld UWP, stars, PBG and
+// number of worlds are already known. The given stars become the primary and companions, the numbers of
+// gas giants, planetoid belts and worlds match the world, and the mainworld is placed in an orbit that
+// suits its atmosphere. It returns the system, or an error if no consistent system could be generated.
+func generateCanonSystem(w world) (sys *starSystem, err error) {
+
+	r := tools.RNG()
+	for i := 0; i < systemGenAttempts; i++ {
+		if sys, err = buildCanonSystem(r, w); err != nil {
+			continue
+		}
+		if err = sys.checkConsistent(w); err == nil {
+			return sys, nil
+		}
+	}
+	log.Printf("Unable to generate system for %s: %v", w.name, err)
+	return nil, err
+}
+
+// buildCanonSystem makes a single attempt at generating the system for the world. See generateCanonSystem.
+func buildCanonSystem(r *rand.Rand, w world) (*starSystem, error) {
+
+	sys := newStarSystem(w)
+
+	// ---- Stars. Use the canonical stars, or generate a lone primary if there are none.
+	stars := flattenStars(w.stars)
+	if len(stars) == 0 {
+		primary := determineStar(r, 0, tools.RollFlux(r, 0), tools.RollFlux(r, 0), true)
+		stars = append(stars, &primary)
+	}
+	sys.placeStars(r, stars)
+	slots := sys.newOrbitSlots()
+
+	// ---- Mainworld. Work out whether it is a planet or satellite, and what it orbits.
+	others := w.worlds - 1 - w.pbg.gasGiants - w.pbg.planetoids
+	mwBelt := w.uwp.sizeInt == 0 && w.pbg.planetoids > 0
+	if mwBelt {
+		others++
+	}
+	if w.worlds <= 0 {
+		others = tools.RollD6(r) + tools.RollD6(r)
+	}
+	if others < 0 {
+		return nil, fmt.Errorf("%w: %d worlds is too few for PBG %s", ErrSystemInconsistent, w.worlds, w.pbg.String())
+	}
+	gasGiants := w.pbg.gasGiants
+	belts := w.pbg.planetoids
+
+	sys.MainworldType = mwTypePlanet
+	if !mwBelt {
+		if strings.Contains(w.remarks, "Sa") {
+			sys.MainworldType = mwTypeFarSatellite
+		} else if strings.Contains(w.remarks, "Lk") {
+			sys.MainworldType = mwTypeCloseSatellite
+		}
+	}
+	hostGG := false
+	if sys.MainworldType != mwTypePlanet {
+		switch {
+		case gasGiants > 0 && (others == 0 || tools.RollFlux(r, 0) <= 0):
+			hostGG = true
+		case others > 0:
+			hostGG = false
+		default:
+			// Nothing for the mainworld to orbit, so it has to be a planet.
+			sys.MainworldType = mwTypePlanet
+		}
+	}
+
+	w.habZoneVar = mainworldHabZoneVariance(r, w, *stars[0])
+	orbit := slots.nearest(0, slots.hz[0]+w.habZoneVar, w.habZoneVar)
+	if orbit < 0 {
+		return nil, fmt.Errorf("%w: no orbit available for the mainworld", ErrSystemInconsistent)
+	}
+	w.habZoneVar = orbit - slots.hz[0]
+	w.orbit = orbit
+	slots.take(0, orbit)
+
+	mw := systemBody{Star: 0, Orbit: orbit, Name: w.name, Type: wtMainworld, UWP: w.uwp.String(), Mainworld: true}
+	switch {
+	case mwBelt:
+		mw.Type = btPlanetoidBelt
+		belts--
+		sys.Bodies = append(sys.Bodies, mw)
+	case sys.MainworldType == mwTypePlanet:
+		sys.Bodies = append(sys.Bodies, mw)
+	default:
+		var host systemBody
+		if hostGG {
+			host = newGasGiant(r)
+			gasGiants--
+		} else {
+			host = systemBody{Type: wtBigworld, UWP: createWorld(r, wtBigworld, w.uwp, w.habZoneVar).String()}
+			others--
+		}
+		host.Star, host.Orbit = 0, orbit
+		mw.Orbit = orbit
+		mw.SatOrbit = determineSatOrbit(r, hostGG, sys.MainworldType == mwTypeCloseSatellite)
+		host.Satellites = append(host.Satellites, mw)
+		sys.Bodies = append(sys.Bodies, host)
+		sys.SatelliteOrbit = mw.SatOrbit
+	}
+	sys.HabitalZoneVariance = w.habZoneVar
+	sys.Climate, _ = w.getClimate()
+
+	// ---- Gas giants, planetoid belts, and then the remaining worlds.
+	for i := 0; i < gasGiants; i++ {
+		b := newGasGiant(r)
+		if err := sys.placeBody(slots, &b, tools.RollD6(r)+tools.RollD6(r)-5); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < belts; i++ {
+		b := systemBody{Type: btPlanetoidBelt}
+		if err := sys.placeBody(slots, &b, tools.RollD6(r)+tools.RollD6(r)-3); err != nil {
+			return nil, err
+		}
+		b.UWP = createWorld(r, btPlanetoidBelt, w.uwp, b.Orbit-slots.hz[b.Star]).String()
+		sys.Bodies[len(sys.Bodies)-1] = b
+	}
+	for i := 0; i < others; i++ {
+		b := systemBody{}
+		if err := sys.placeBody(slots, &b, tools.RollFlux(r, 0)); err != nil {
+			return nil, err
+		}
+		b.Type = determineOtherWorldType(r, b.Orbit-slots.hz[b.Star])
+		b.UWP = createWorld(r, b.Type, w.uwp, b.Orbit-slots.hz[b.Star]).String()
+		sys.Bodies[len(sys.Bodies)-1] = b
+	}
+
+	// ---- Moons for the gas giants. These are not counted as worlds.
+	for i := range sys.Bodies {
+		if sys.Bodies[i].isGasGiant() {
+			sys.addMoons(r, &sys.Bodies[i], w.uwp, sys.Bodies[i].Orbit-slots.hz[sys.Bodies[i].Star])
+		}
+	}
+
+	sys.nameBodies()
+	_, _, sys.TotalWorlds = sys.countBodies()
+	return sys, nil
+}
+
+// newStarSystem creates a star system with the mainworld details copied from the world.
+func newStarSystem(w world) *starSystem {
+	sys := &starSystem{
+		WorldID:         w.id,
+		Name:            w.name,
+		SectorAbbrev:    w.sectorAbbrev,
+		Sector:          w.sector,
+		SectorHex:       w.hexLoc.String(),
+		UWP:             w.uwp.String(),
+		TravelZone:      w.zone.Desc(),
+		Bases:           w.bases,
+		PopulationDigit: w.pbg.populationDigit,
+		PlanetoidBelts:  w.pbg.planetoids,
+		GasGiants:       w.pbg.gasGiants,
+		TotalWorlds:     w.worlds,
+		Allegiance:      w.allegiance,
+		Importance:      w.importance.Importance,
+		Economic:        w.economics,
+		Cultural:        w.culture,
+		Nobility:        w.nobility,
+		ResourceUnits:   w.ru,
+	}
+	sys.TradeClassifications = strings.Fields(w.remarks)
+	return sys
+}
+
+// flattenStars returns the stars of a system with any companions following the star they accompany,
+// the same order that they appear in the stars string.
+func flattenStars(ss []*starDetail) (flat []*starDetail) {
+	for _, star := range ss {
+		for ; star != nil; star = star.companion {
+			flat = append(flat, star)
+		}
+	}
+	return
+}
+
+// placeStars adds the stars to the system, with the position and orbit for each from the star hierarchy. If the stars
+// have not been arranged into a hierarchy yet, they are arranged using T5 rules. The first star is always the primary.
+func (s *starSystem) placeStars(r *rand.Rand, stars []*starDetail) {
+
+	for _, star := range stars {
+		if star.position == "" {
+			arrangeStars(r, stars, RulesTraveller5)
+			break
+		}
+	}
+
+	for i, star := range stars {
+		st := systemStar{Position: star.position, Spectral: star.String(), Orbit: star.orbit}
+		if i < len(starGreek) {
+			st.Name = s.Name + "-" + starGreek[i]
+		}
+		if sm, err := star.model(); err == nil {
+			st.HabitableZoneOrbit = star.habitableZoneOrbit()
+			st.MinOrbit = sm.MinOrbit
+			st.Mass = sm.Mass
+			st.Luminosity = sm.Luminosity
+		} else {
+			log.Printf("Unable to get stellar detail for %s: %v", st.Spectral, err)
+		}
+		s.Stars = append(s.Stars, st)
+	}
+}
+
+// newOrbitSlots works out the orbits available around each star in the system. Orbits inside a star's
+// minimum orbit are unavailable, as are orbits of the primary close to those of the other stars.
+// Companion stars have no orbits of their own, and other stars have orbits out to half their distance.
+func (s *starSystem) newOrbitSlots() *orbitSlots {
+
+	slots := &orbitSlots{}
+	for i, st := range s.Stars {
+		maxOrbit := maxSystemOrbit
+		if i != 0 {
+			maxOrbit = st.Orbit/2 - 1
+		}
+		if st.Position == starPosCompanion {
+			maxOrbit = -1
+		}
+		free := make([]bool, maxSystemOrbit+1)
+		for o := 0; o <= maxOrbit; o++ {
+			free[o] = o >= st.MinOrbit
+		}
+		hz := st.HabitableZoneOrbit
+		if hz < 0 {
+			hz = 0
+		}
+		slots.free = append(slots.free, free)
+		slots.hz = append(slots.hz, hz)
+	}
+	for _, st := range s.Stars {
+		if st.Orbit < 0 {
+			continue
+		}
+		for o := st.Orbit - 1; o <= st.Orbit+1; o++ {
+			if o >= 0 && o <= maxSystemOrbit {
+				slots.free[0][o] = false
+			}
+		}
+	}
+	return slots
+}
+
+// nearest finds the free orbit around the star nearest to the target orbit. Ties are broken in the
+// direction of the bias. It returns -1 if no orbit is free.
+func (o *orbitSlots) nearest(star, target, bias int) int {
+	if target < 0 {
+		target = 0
+	}
+	if target > maxSystemOrbit {
+		target = maxSystemOrbit
+	}
+	step := 1
+	if bias < 0 {
+		step = -1
+	}
+	for d := 0; d <= maxSystemOrbit; d++ {
+		for _, orbit := range []int{target + d*step, target - d*step} {
+			if orbit >= 0 && orbit <= maxSystemOrbit && o.free[star][orbit] {
+				return orbit
+			}
+		}
+	}
+	return -1
+}
+
+// take marks the orbit around the star as filled.
+func (o *orbitSlots) take(star, orbit int) {
+	o.free[star][orbit] = false
+}
+
+// placeBody places the body in the free orbit nearest to the habitable zone plus the offset, around the
+// primary if possible and otherwise around the other stars. The body is appended to the system.
+func (s *starSystem) placeBody(slots *orbitSlots, b *systemBody, offset int) error {
+	for star := range s.Stars {
+		if orbit := slots.nearest(star, slots.hz[star]+offset, offset); orbit >= 0 {
+			slots.take(star, orbit)
+			b.Star, b.Orbit = star, orbit
+			s.Bodies = append(s.Bodies, *b)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no orbit available for %s", ErrSystemInconsistent, b.Type)
+}
+
+// addMoons adds a few small moons to a gas giant. The satellite orbits used are all different.
+func (s *starSystem) addMoons(r *rand.Rand, gg *systemBody, mw worldUwp, hzVar int) {
+	used := make(map[string]bool)
+	for _, sat := range gg.Satellites {
+		used[sat.SatOrbit] = true
+	}
+	for n := tools.RollD6(r) - 3; n > 0; n-- {
+		satOrbit := determineSatOrbit(r, true, tools.RollD6(r) <= 3)
+		if used[satOrbit] {
+			continue
+		}
+		used[satOrbit] = true
+		wt := wtWorldlet
+		if hzVar > 0 {
+			wt = wtIceworld
+		}
+		gg.Satellites = append(gg.Satellites, systemBody{Star: gg.Star, Orbit: gg.Orbit, Type: wt, SatOrbit: satOrbit,
+			UWP: createWorld(r, wt, mw, hzVar).String()})
+	}
+}
+
+// nameBodies sorts the bodies of the system into orbit order and names those that are not the mainworld
+// after their star and orbit, for example "Regina-alpha-4". Satellites are named after their orbit.
+func (s *starSystem) nameBodies() {
+	sort.Slice(s.Bodies, func(i, j int) bool {
+		if s.Bodies[i].Star != s.Bodies[j].Star {
+			return s.Bodies[i].Star < s.Bodies[j].Star
+		}
+		return s.Bodies[i].Orbit < s.Bodies[j].Orbit
+	})
+	for i := range s.Bodies {
+		b := &s.Bodies[i]
+		if !b.Mainworld {
+			b.Name = fmt.Sprintf("%s-%d", s.Stars[b.Star].Name, b.Orbit)
+		}
+		for j := range b.Satellites {
+			if !b.Satellites[j].Mainworld {
+				b.Satellites[j].Name = b.Name + "-" + strings.ToLower(b.Satellites[j].SatOrbit)
+			}
+		}
+	}
+}
+
+// newGasGiant creates a gas giant of a random type.
+func newGasGiant(r *rand.Rand) systemBody {
+	switch tools.RollD6(r) {
+	case 1, 2, 3:
+		return systemBody{Type: btLargeGasGiant}
+	case 4, 5:
+		return systemBody{Type: btSmallGasGiant}
+	default:
+		return systemBody{Type: btIceGiant}
+	}
+}
+
+// mainworldHabZoneVariance determines the habitable zone variance for a mainworld that already has a UWP,
+// so that the orbit suits the world. Climate trade classifications are used where present, otherwise the
+// atmosphere decides. The variance is returned, negative for the inner zone and positive for the outer zone.
+func mainworldHabZoneVariance(r *rand.Rand, w world, primary starDetail) int {
+
+	switch {
+	case strings.Contains(w.remarks, "Tr") || strings.Contains(w.remarks, "Ho"):
+		return -1
+	case strings.Contains(w.remarks, "Tu") || strings.Contains(w.remarks, "Co"):
+		return 1
+	case strings.Contains(w.remarks, "Fr"):
+		return 2
+	}
+
+	switch w.uwp.atmInt {
+	case 2, 3, 4, 5, 6, 7, 8, 9, 13, 14:
+		// Breathable (or nearly so), must be in the habitable zone.
+		return 0
+	case 10, 11, 12:
+		// Exotic, corrosive and insidious atmospheres favour the hotter orbits.
+		if tools.RollD6(r) <= 3 {
+			return -1
+		}
+		return 0
+	case 0, 1:
+		// Ice-capped vacuum worlds sit outside the habitable zone.
+		if w.uwp.hydInt > 0 {
+			return 1 + tools.RollD6(r)/4
+		}
+	}
+	return determineHabitableZoneVariance(r, primary)
+}
+
+// determineOtherWorldType determines the type of a world other than the mainworld from the
+// habitable zone variance of its orbit. It returns one of the world type constants.
+func determineOtherWorldType(r *rand.Rand, hzVar int) string {
+	roll := tools.RollD6(r)
+	switch {
+	case hzVar < 0:
+		return [...]string{wtInferno, wtInnerWorld, wtBigworld, wtStormWorld, wtRadworld, wtInnerWorld}[roll-1]
+	case hzVar == 0:
+		return [...]string{wtHospitable, wtHospitable, wtBigworld, wtStormWorld, wtRadworld, wtWorldlet}[roll-1]
+	default:
+		return [...]string{wtWorldlet, wtIceworld, wtBigworld, wtIceworld, wtRadworld, wtIceworld}[roll-1]
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+// tables.go contains a bunch of the Traveller (T5) tables. For instance,
+// the description of Hydrographic C may be here. these tables, ideally, will be moved to the database.
+
+// Constants for starports and spaceports.
+const (
+	stpUnknown   = "Unknown"            // An unknown Star- or Space-port
+	stpExcellent = "Starport excellent" // Excellent quality starport
+	stpGood      = "Starport good"
+	stpRoutine   = "Starport routine"
+	stpPoor      = "Starport poor"
+	stpFrontier  = "Starport frontier"
+	stpNone      = "Starport none"
+	// Spaceports below
+	sppGood  = "Spaceport good"
+	sppPoor  = "Spaceport poor"
+	sppBasic = "Spaceport basic"
+	sppNone  = "Spaceport none"
+)
+
+// tableStarport takes a single character starport (or spaceport), and returns a description from the T5 table.
+// If the starport type is not recognised stpUnknown ("Unknown") is returned.
+func tableStarport(s string) string {
+	switch s {
+	case "A":
+		return stpExcellent
+	case "B":
+		return stpGood
+	case "C":
+		return stpRoutine
+	case "D":
+		return stpPoor
+	case "E":
+		return stpFrontier
+	case "X":
+		return stpNone
+	case "F":
+		return sppGood
+	case "G":
+		return sppPoor
+	case "H":
+		return sppBasic
+	case "Y":
+		return sppNone
+	default:
+		return stpUnknown
+	}
+}
+
+// The UWP digit descriptions from the Book 3 (Classic Traveller) tables, indexed by the digit. Digits beyond the
+// end of a table use its last entry.
+var (
+	tableSizes = [...]string{"Asteroid/planetoid belt", "1,600 km", "3,200 km", "4,800 km", "6,400 km", "8,000 km",
+		"9,600 km", "11,200 km", "12,800 km", "14,400 km", "16,000 km"}
+	tableAtmospheres = [...]string{"No atmosphere", "Trace", "Very thin, tainted", "Very thin", "Thin, tainted", "Thin",
+		"Standard", "Standard, tainted", "Dense", "Dense, tainted", "Exotic", "Corrosive", "Insidious", "Dense, high",
+		"Thin, low", "Unusual"}
+	tableHydrographics = [...]string{"No free standing water", "10% water", "20% water", "30% water", "40% water",
+		"50% water", "60% water", "70% water", "80% water", "90% water", "No land masses"}
+	tablePopulations = [...]string{"No inhabitants", "Tens", "Hundreds", "Thousands", "Tens of thousands",
+		"Hundreds of thousands", "Millions", "Tens of millions", "Hundreds of millions", "Billions", "Tens of billions",
+		"Hundreds of billions", "Trillions"}
+	tableGovernments = [...]string{"No government structure", "Company/corporation", "Participating democracy",
+		"Self-perpetuating oligarchy", "Representative democracy", "Feudal technocracy", "Captive government",
+		"Balkanization", "Civil service bureaucracy", "Impersonal bureaucracy", "Charismatic dictator",
+		"Non-charismatic leader", "Charismatic oligarchy", "Religious dictatorship", "Religious autocracy",
+		"Totalitarian oligarchy"}
+	tableLawLevels = [...]string{"No prohibitions", "Body pistols, explosives and poison gas prohibited",
+		"Portable energy weapons prohibited", "Machine guns and automatic rifles prohibited",
+		"Light assault weapons prohibited", "Personal concealable weapons prohibited",
+		"All firearms except shotguns prohibited", "Shotguns prohibited", "Long bladed weapons controlled",
+		"Possession of any weapon outside the home prohibited", "Weapon possession prohibited"}
+	tableTechLevels = [...]string{"Stone age", "Bronze and iron age", "Printing press", "Basic science",
+		"External combustion", "Mass production", "Nuclear power", "Miniaturised electronics", "Quality computers",
+		"Anti-gravity", "Interstellar community", "Lower average Imperial", "Average Imperial",
+		"Above average Imperial", "Above average Imperial", "Technical maximum Imperial", "Beyond Imperial"}
+)
+
+// tableEntry returns the description for the digit from the table. Digits beyond the end of the table use its last
+// entry, and negative digits return stpUnknown.
+func tableEntry(table []string, digit int) string {
+	if digit < 0 || len(table) == 0 {
+		return stpUnknown
+	}
+	return table[minInt(digit, len(table)-1)]
+}
+
+// tableTradeCodes contains the descriptions of the trade codes and other remarks.
+var tableTradeCodes = map[string]string{
+	"Ab": "Data repository", "Ag": "Agricultural", "An": "Ancient site", "As": "Asteroid belt", "Ba": "Barren",
+	"Co": "Cold", "Cp": "Subsector capital", "Cs": "Sector capital", "Cx": "Capital", "Cy": "Colony",
+	"Da": "Danger", "De": "Desert", "Di": "Dieback", "Fa": "Farming", "Fl": "Fluid oceans", "Fo": "Forbidden",
+	"Fr": "Frozen", "Ga": "Garden world", "He": "Hellworld", "Hi": "High population", "Ho": "Hot",
+	"Ht": "High technology", "Ic": "Ice-capped", "In": "Industrial", "Lk": "Locked", "Lo": "Low population",
+	"Lt": "Low technology", "Mi": "Mining", "Mr": "Military rule", "Na": "Non-agricultural",
+	"Ni": "Non-industrial", "Oc": "Ocean world", "Pa": "Pre-agricultural", "Pe": "Penal colony",
+	"Ph": "Pre-high population", "Pi": "Pre-industrial", "Po": "Poor", "Pr": "Pre-rich", "Px": "Prison or exile camp",
+	"Pz": "Puzzle", "Re": "Reserve", "Ri": "Rich", "Sa": "Satellite", "Tr": "Tropic", "Tu": "Tundra",
+	"Tz": "Twilight zone", "Va": "Vacuum", "Wa": "Water world",
+}
+
+// tableBases contains the descriptions of the base codes.
+var tableBases = map[rune]string{
+	'A': "Naval and scout bases", 'B': "Naval base and scout way station", 'C': "Corsair base", 'D': "Naval depot",
+	'E': "Embassy", 'K': "Naval base", 'M': "Military base", 'N': "Naval base", 'R': "Clan base",
+	'S': "Scout base", 'T': "Tlauku base", 'V': "Exploration base", 'W': "Scout way station", 'Z': "Naval and military bases",
+}
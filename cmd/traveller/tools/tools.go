@@ -23,16 +23,21 @@ func init() {
 
 ///////// Random Number Generation Tools
 
-// Dice rolls a dice of the specified size. Returns the number rolled.
-func Dice(sides int) int {
+// RNG returns the RNG shared by Dice, D6 and Flux. It is not safe for concurrent use, so it is only for the user
+// interface's goroutine; generation run in the background uses an RNG of its own, with the Roll functions.
+func RNG() *rand.Rand {
 	if randSeq == nil {
 		onlyOnce.Do(func() {
 			seed1 := rand.NewSource(time.Now().UnixNano())
 			randSeq = rand.New(seed1)
 		})
 	}
+	return randSeq
+}
 
-	return randSeq.Intn(sides) + 1
+// Dice rolls a dice of the specified size. Returns the number rolled.
+func Dice(sides int) int {
+	return RollDice(RNG(), sides)
 }
 
 // D6 returns the result of a 6-sided dice rolled.
@@ -43,7 +48,22 @@ func D6() int {
 // Flux makes a Traveller "flux" roll, which is 1d6 - 1d6, with possible addition of Dice Modifier.
 // It returns the integer result.
 func Flux(dm int) int {
-	return (D6() - D6() + dm)
+	return RollFlux(RNG(), dm)
+}
+
+// RollDice rolls a dice of the specified size, using the RNG. Returns the number rolled.
+func RollDice(r *rand.Rand, sides int) int {
+	return r.Intn(sides) + 1
+}
+
+// RollD6 returns the result of a 6-sided dice rolled, using the RNG.
+func RollD6(r *rand.Rand) int {
+	return RollDice(r, 6)
+}
+
+// RollFlux makes a Traveller "flux" roll using the RNG, as Flux does.
+func RollFlux(r *rand.Rand, dm int) int {
+	return (RollD6(r) - RollD6(r) + dm)
 }
 
 // SeedForTesting provides an opportunity to seed the RNG for testing purposes.
@@ -0,0 +1,1349 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"strings"
+	"trav2/cmd/traveller/tools"
+)
+
+// worldGen.go contains code for world generation. The code is ONLY for the world generation process,
+// not the use of the world, stars, sector or subsector objects. Basically if the dice needs to be
+// rolled for something, it is here (and the function should start with "determineXXX"). If it is
+// just retrieving or setting information about a world then it should be setXXX or getXXX and be
+// in worlds.go.
+
+// worldGenState contains infomration about the world's generation, it's current stage of generation
+// (if multi-stage) and the type of generation used.
+// type worldGenState struct {
+// 	genType         string
+// 	completionState string
+// }
+
+// WorldGenType defines the type of world generation used for this world.
+type WorldGenType int
+
+// Constants for generation type
+const (
+	// WgtCt03 is used for Classic Traveller Book 3.
+	WgtCt03 WorldGenType = iota
+	// WgtCt06 is used for Classic Traveller Book 6.
+	WgtCt06
+	// WgtMtBasic is used for MegaTraveller Basic.
+	WgtMtBasic
+	// WgtMtExtended is used for MegaTraveller Extended.
+	WgtMtExtended
+	// WgtMtWBH is used for World Builders Handbook (MT).
+	WgtMtWBH
+	// WgtT5ss is used for Traveller5 Second Survey.
+	WgtT5ss
+	// WgtInvalid is used to indicate a generation that has failed.
+	WgtInvalid
+)
+
+// String displays a string representing the type of world generation process.
+func (g WorldGenType) String() string {
+	return [...]string{"Classic Traveller Book 3", "Classic Traveller Book 6", "MegaTraveller Basic", "MegaTraveller Extended", "World Builders Handbook", "Traveller5 Second Survey"}[g]
+}
+
+// generateCT03World generates a basic Classic Traveller world with the given
+// basic information. It returns the word generated.
+func generateCT03World(r *rand.Rand, name, hexLoc, sector string) (w world) {
+
+	w.name = name
+	hloc := NewHexLoc(hexLoc, true)
+	if hloc == nil {
+		w.genType = WgtInvalid
+		return
+	}
+	w.hexLoc = *hloc
+	w.subsectorIndex = w.hexLoc.GetIndex()
+	w.sector = sector
+	w.sectorAbbrev = getAbbreviationForSector(sector)
+	w.allegiance = basicAllegianceMap["Imperial"]
+
+	ss, err := repo.getSubsectorBySectorNameAndIndex(context.Background(), sector, w.subsectorIndex)
+	log.Printf("Subsector is %v", ss)
+	if err == nil {
+		w.subsector = ss.name
+	}
+
+	w.genType = WgtCt03
+
+	// Generate System contents
+	// Starport
+	w.uwp.starport = determineStarport(r, ssStandard)
+	// Bases
+	w.determineBases(r)
+	// Gas Giant
+	if tools.RollD6(r)+tools.RollD6(r) <= 9 {
+		w.pbg.gasGiants = 1
+	}
+
+	// Generate UWP
+	w.uwp.createWorldBasic(r)
+	// Adjustments for Classic Traveller Book 3
+	if w.uwp.lawInt > 15 {
+		w.uwp.lawInt = 15
+	}
+	if w.uwp.techInt > 15 {
+		w.uwp.techInt = 15
+	}
+
+	// Determine Trade Classifications (Basic)
+	w.remarks = w.determineTradeClassifications()
+
+	// Finished - return result
+	return
+}
+
+// generateMTWorld generates a basic MegaTraveller world with the given basic information.
+// It returns the word generated.
+func generateMTWorld(r *rand.Rand, name, hexLoc, sector, allegiance, traffic string) (w world) {
+
+	w.name = name
+	hloc := NewHexLoc(hexLoc, true)
+	if hloc == nil {
+		w.genType = WgtInvalid
+		return
+	}
+	w.hexLoc = *hloc
+	w.subsectorIndex = w.hexLoc.GetIndex()
+	w.sector = sector
+	w.sectorAbbrev = getAbbreviationForSector(sector)
+	w.allegiance = basicAllegianceMap[allegiance]
+	w.genType = WgtMtBasic
+
+	if ss, err := repo.getSubsectorBySectorNameAndIndex(context.Background(), sector, w.subsectorIndex); err == nil {
+		w.subsector = ss.name
+	}
+
+	// Generate System contents
+
+	// Step 3. Starport
+	sparcity := ssStandard
+	for ss := range mtSubsectorTrafficArr {
+		if traffic == mtSubsectorTrafficArr[ss] {
+			sparcity = ss
+		}
+	}
+	w.uwp.starport = determineStarport(r, sparcity)
+
+	// Step 4 - 10. Create UWP for world
+	w.uwp.createWorldBasic(r)
+	// Adjustments for MegaTraveller
+	if w.uwp.lawInt > 20 {
+		w.uwp.lawInt = 20
+	}
+	if w.uwp.govInt == 14 || w.uwp.govInt == 15 {
+		w.uwp.techInt = w.uwp.techInt - 1
+	}
+	if w.uwp.starport == "F" {
+		w.uwp.techInt = w.uwp.techInt + 1
+	}
+	if w.uwp.techInt > 15 {
+		w.uwp.techInt = 15
+	}
+
+	// Step 11. Bases
+	w.determineBases(r)
+
+	// Step 12. Determine Trade Classifications (Basic)
+	w.remarks = w.determineTradeClassifications()
+	//displayObject(w.ObjectBasicString())
+
+	// Step 13. Supplemental Remarks (none)
+	// Step 14. Population Multiplier.
+	//w.pbg.populationDigit = tools.RollDice(r, 10) - 1
+	w.pbg.populationDigit = tools.RollDice(r, 9)
+	// Step 15. Gas Giants
+	if tools.RollD6(r)+tools.RollD6(r) >= 5 {
+		roll := tools.RollD6(r) + tools.RollD6(r)
+		switch roll {
+		case 2, 3:
+			w.pbg.gasGiants = 1
+		case 4, 5:
+			w.pbg.gasGiants = 2
+		case 6, 7:
+			w.pbg.gasGiants = 3
+		case 8, 9, 10:
+			w.pbg.gasGiants = 4
+		case 11, 12:
+			w.pbg.gasGiants = 5
+		}
+	}
+	// Step 16. Planetoid Belts
+	if tools.RollD6(r)+tools.RollD6(r)+w.pbg.gasGiants >= 8 {
+		roll := tools.RollD6(r) + tools.RollD6(r)
+		switch roll {
+		case 2, 3, 4, 5, 6, 7:
+			w.pbg.planetoids = 1
+		case 13:
+			w.pbg.planetoids = 3
+		default:
+			w.pbg.planetoids = 2
+		}
+	}
+	// Step 17. Travel Zone
+	w.determineZone(r)
+
+	// Finished, return the result.
+	return
+
+}
+
+// generateT5World generates a single Traveller5 Second Survey mainworld, based on the provided details.
+// It returns the world generated.
+func generateT5World(r *rand.Rand, name, hexLoc, sector, allegianceCode string) (w world) {
+
+	w.genType = WgtT5ss
+	w.name = name
+	hloc := NewHexLoc(hexLoc, true)
+	if hloc == nil {
+		w.genType = WgtInvalid
+		return
+	}
+	w.hexLoc = *hloc
+	w.allegiance = allegianceCode
+	w.sector = sector
+	w.subsectorIndex = w.hexLoc.GetIndex()
+	w.sectorAbbrev = getAbbreviationForSector(w.sector)
+
+	ss, err := repo.getSubsectorBySectorNameAndIndex(context.Background(), sector, w.subsectorIndex)
+	if err == nil {
+		w.subsector = ss.name
+	}
+
+	// ---- Step B ---- Basic System features
+	starSpectralFlux := tools.RollFlux(r, 0)
+	starSizeFlux := tools.RollFlux(r, 0)
+
+	//The "Primary" is the main or homeworld star in a star system.
+	primary := determineStar(r, 0, starSpectralFlux, starSizeFlux, true)
+	if primary.spectralType == "" {
+		log.Panic("Error: Primary Star generation has failed.")
+		return
+	}
+	// Add the primary star to the world's information
+	w.stars = append(w.stars, &primary)
+
+	// Get some stellar information
+	primaryDetail, err := primary.model()
+	if err != nil {
+		log.Printf("Unable to get stellar detail for %s: %v", primary, err)
+	}
+
+	// Determine the world's habitable zone and orbit
+	w.habZoneVar = determineHabitableZoneVariance(r, primary)
+	w.orbit = primary.habitableZoneOrbit() + w.habZoneVar
+	// Possibly adjust for a minimum orbit. Both habitable zone variance and orbit will need to change.
+	if w.orbit < primaryDetail.MinOrbit {
+		w.habZoneVar = w.habZoneVar + primaryDetail.MinOrbit
+		w.orbit = primaryDetail.MinOrbit
+	}
+
+	//climate, _ := w.getClimate()
+	w.planetOrSat = determineMainworldType(r)
+	w.mwSatGG = false
+	if strings.Contains(w.planetOrSat, "Sa") {
+		if tools.RollFlux(r, 0) <= 0 {
+			w.mwSatGG = true
+		}
+		w.satOrbit = determineSatOrbit(r, w.mwSatGG, strings.Contains(w.planetOrSat, "Close"))
+	}
+	w.pbg = determinePBG(r, w.mwSatGG)
+
+	// ---- Step F ---- WorldGen Additional Data (Stellar)
+	w.generateSystemStars(r, starSpectralFlux, starSizeFlux)
+
+	// ---- Step C ---- Generate the UWP
+	w.uwp = createWorld(r, wtMainworld, w.uwp, w.habZoneVar)
+
+	// Adjustments
+	if strings.Contains(w.allegiance, "Zh") {
+		if w.uwp.popInt < 4 {
+			w.uwp.popInt = 0
+		}
+		if w.uwp.techInt > 14 {
+			w.uwp.techInt = 14
+		}
+	}
+	if w.uwp.popInt == 0 {
+		w.pbg.populationDigit = 0
+	}
+
+	// ---- Step C ---- WorldGen Trade Classes and Zones
+	w.remarks = w.determineTradeClassifications()
+	w.determineZone(r)
+	w.worlds = 1 + w.pbg.gasGiants + w.pbg.planetoids + tools.RollD6(r) + tools.RollD6(r)
+
+	// Bases
+	w.determineBases(r)
+
+	// ---- Step E ---- Extensions
+	w.determineExtensions(r)
+
+	return
+}
+
+// createWorldBasic create the physical and population details of mainworld using
+// CT Book 3 rules.
+func (u *worldUwp) createWorldBasic(r *rand.Rand) {
+
+	var dm int // Generic Dice Modifier
+
+	// Size
+	u.sizeInt = tools.RollD6(r) + tools.RollD6(r) - 2
+	// Atmosphere
+	u.atmInt = tools.RollD6(r) + tools.RollD6(r) - 7 + u.sizeInt
+	if u.atmInt < 0 || u.sizeInt == 0 {
+		u.atmInt = 0
+	}
+	// Hydrographics
+	dm = 0
+	if u.atmInt < 2 || u.atmInt > 9 {
+		dm = -4
+	}
+	u.hydInt = tools.RollD6(r) + tools.RollD6(r) - 7 + u.atmInt + dm
+	if u.sizeInt == 0 || u.hydInt < 0 {
+		u.hydInt = 0
+	}
+	if u.hydInt > 10 {
+		u.hydInt = 10
+	}
+	// Population
+	u.popInt = tools.RollD6(r) + tools.RollD6(r) - 2
+	// Government
+	u.govInt = tools.RollD6(r) + tools.RollD6(r) - 7 + u.popInt
+	if u.govInt > 15 {
+		u.govInt = 15
+	}
+	if u.govInt < 0 {
+		u.govInt = 0
+	}
+	// Law Level
+	u.lawInt = tools.RollD6(r) + tools.RollD6(r) - 7 + u.govInt
+
+	// Law level limit varies depending on the generation system. Leave this to the client,
+	// as it does not affect anything else below this.
+	if u.lawInt < 0 {
+		u.lawInt = 0
+	}
+
+	// Tech Level
+	dm = 0
+	switch u.starport {
+	case "A":
+		dm += 6
+	case "B":
+		dm += 4
+	case "C":
+		dm += 2
+	case "X":
+		dm += -4
+	}
+	switch u.sizeInt {
+	case 0, 1:
+		dm += 2
+	case 2, 3, 4:
+		dm++
+	}
+	switch u.atmInt {
+	case 0, 1, 2, 3:
+		dm++
+	case 10, 11, 12, 13, 14, 15:
+		dm++
+	}
+	switch u.hydInt {
+	case 9:
+		dm++
+	case 10:
+		dm += 2
+	}
+	switch u.popInt {
+	case 1, 2, 3, 4, 5:
+		dm++
+	case 9:
+		dm += 2
+	case 10, 11, 12, 13, 14, 15:
+		dm += 4
+	}
+	switch u.govInt {
+	case 0, 5:
+		dm++
+	case 13:
+		dm += -2
+	}
+	u.techInt = tools.RollD6(r) + dm
+	if u.techInt < 0 {
+		u.techInt = 0
+	}
+
+	return
+}
+
+// createWorld creates a Mainworld or secondary world (out of almost nothing - how about that?) and returns a world UWP structure containing all the cool (but basic) stuff.
+// Parameters are the worldType, the mainworld UWP, and the habitable zone variance.
+// If you want to create a mainworld, set worldType to "" and habitable zone is ignored. hzVariance should
+// be set to negative, postive or zero, a worlds cal. Returns the new world UWP.
+func createWorld(r *rand.Rand, worldType string, uwp worldUwp, hzVariance int) (ret worldUwp) {
+
+	var dm int // Generic Dice Modifier
+
+	if worldType != wtMainworld {
+		return createSecondaryWorld(r, worldType, uwp, hzVariance)
+	}
+
+	ret.starport = determineStarport(r, ssStandard)
+
+	// Size
+	ret.sizeInt = tools.RollD6(r) + tools.RollD6(r) - 2
+	if ret.sizeInt == 10 {
+		ret.sizeInt = tools.RollD6(r) + 9
+	}
+	// Atmosphere
+	ret.atmInt = ret.sizeInt + tools.RollFlux(r, 0)
+	if ret.atmInt < 0 || ret.sizeInt == 0 {
+		ret.atmInt = 0
+	}
+	if ret.atmInt > 15 {
+		ret.atmInt = 15
+	}
+	// Hydrographics
+	dm = 0
+	if ret.atmInt < 2 || ret.atmInt > 9 {
+		dm = -4
+	}
+	ret.hydInt = tools.RollFlux(r, 0) + ret.atmInt + dm
+	if ret.sizeInt < 2 || ret.hydInt < 0 {
+		ret.hydInt = 0
+	}
+	if ret.hydInt > 10 {
+		ret.hydInt = 10
+	}
+	// Population
+	ret.popInt = tools.RollD6(r) + tools.RollD6(r) - 2
+	if ret.popInt == 10 {
+		ret.popInt = tools.RollD6(r) + tools.RollD6(r) + 3
+	}
+	// Government
+	ret.govInt = tools.RollFlux(r, 0) + ret.popInt
+	if ret.govInt > 15 {
+		ret.govInt = 15
+	}
+	if ret.govInt < 0 {
+		ret.govInt = 0
+	}
+	// Law Level
+	ret.lawInt = tools.RollFlux(r, 0) + ret.govInt
+	if ret.lawInt > 18 {
+		ret.lawInt = 18
+	}
+	if ret.lawInt < 0 {
+		ret.lawInt = 0
+	}
+	// Tech Level
+	dm = 0
+	switch ret.starport {
+	case "A":
+		dm += 6
+	case "B":
+		dm += 4
+	case "C":
+		dm += 2
+	case "X":
+		dm += -4
+	}
+	switch ret.sizeInt {
+	case 0, 1:
+		dm += 2
+	case 2, 3, 4:
+		dm++
+	}
+	switch ret.atmInt {
+	case 0, 1, 2, 3:
+		dm++
+	case 10, 11, 12, 13, 14, 15:
+		dm++
+	}
+	switch ret.hydInt {
+	case 9:
+		dm++
+	case 10:
+		dm += 2
+	}
+	switch ret.popInt {
+	case 1, 2, 3, 4, 5:
+		dm++
+	case 9:
+		dm += 2
+	case 10, 11, 12, 13, 14, 15:
+		dm += 4
+	}
+	switch ret.govInt {
+	case 0, 5:
+		dm++
+	case 13:
+		dm += -2
+	}
+	ret.techInt = tools.RollD6(r) + dm
+	if ret.techInt < 0 {
+		ret.techInt = 0
+	}
+
+	return
+}
+
+// createSecondaryWorld creates a world other than the mainworld, using (simplified) T5 rules for the given world type.
+// The secondary world population is always less than the mainworld's, and its tech level is one below the mainworld.
+// Returns the new world UWP.
+func createSecondaryWorld(r *rand.Rand, worldType string, mw worldUwp, hzVariance int) (ret worldUwp) {
+
+	// Size
+	switch worldType {
+	case btPlanetoidBelt, wtPlanetoid:
+		ret.sizeInt = 0
+	case wtInferno:
+		ret.sizeInt = tools.RollD6(r) + 6
+	case wtBigworld:
+		ret.sizeInt = tools.RollD6(r) + tools.RollD6(r) + 7
+	case wtWorldlet:
+		ret.sizeInt = tools.RollD6(r) - 3
+	case wtStormWorld, wtRadworld:
+		ret.sizeInt = tools.RollD6(r) + tools.RollD6(r)
+	default:
+		ret.sizeInt = tools.RollD6(r) + tools.RollD6(r) - 2
+	}
+	if ret.sizeInt < 1 && worldType != btPlanetoidBelt && worldType != wtPlanetoid {
+		ret.sizeInt = 1
+	}
+	// Atmosphere
+	switch {
+	case ret.sizeInt == 0:
+		ret.atmInt = 0
+	case worldType == wtInferno:
+		ret.atmInt = 11
+	default:
+		ret.atmInt = ret.sizeInt + tools.RollFlux(r, 0)
+		if worldType == wtStormWorld && ret.atmInt < 4 {
+			ret.atmInt = 4
+		}
+	}
+	if ret.atmInt < 0 || ret.sizeInt < 2 {
+		ret.atmInt = 0
+	}
+	if ret.atmInt > 15 {
+		ret.atmInt = 15
+	}
+	// Hydrographics
+	dm := 0
+	if ret.atmInt < 2 || ret.atmInt > 9 {
+		dm = -4
+	}
+	if hzVariance < 0 {
+		dm -= 2
+	}
+	ret.hydInt = tools.RollFlux(r, 0) + ret.atmInt + dm
+	if ret.sizeInt < 2 || ret.hydInt < 0 || worldType == wtInferno {
+		ret.hydInt = 0
+	}
+	if ret.hydInt > 10 {
+		ret.hydInt = 10
+	}
+	// Population
+	ret.popInt = tools.RollD6(r) + tools.RollD6(r) - 2
+	if worldType == wtInferno || worldType == wtRadworld {
+		ret.popInt = 0
+	}
+	if ret.popInt >= mw.popInt {
+		ret.popInt = mw.popInt - 1
+	}
+	if ret.popInt < 0 {
+		ret.popInt = 0
+	}
+	// Spaceport, Government, Law Level and Tech Level all depend on there being a population.
+	ret.starport = "Y"
+	if ret.popInt == 0 {
+		return
+	}
+	switch roll := ret.popInt + tools.RollFlux(r, 0)/2; {
+	case roll >= 6:
+		ret.starport = "F"
+	case roll >= 4:
+		ret.starport = "G"
+	case roll >= 1:
+		ret.starport = "H"
+	}
+	ret.govInt = tools.RollD6(r)
+	if ret.govInt > ret.popInt+tools.RollD6(r) {
+		ret.govInt = ret.popInt
+	}
+	ret.lawInt = tools.RollFlux(r, 0) + ret.govInt
+	if ret.lawInt < 0 {
+		ret.lawInt = 0
+	}
+	if ret.lawInt > 18 {
+		ret.lawInt = 18
+	}
+	ret.techInt = mw.techInt - 1
+	if ret.techInt < 0 {
+		ret.techInt = 0
+	}
+	return
+}
+
+// determineSatOrbit gets the Mainworld satellite orbit name based on mainworld host body (GG or planet) and orbit zone. Returns the orbit name as string.
+func determineSatOrbit(r *rand.Rand, gg, close bool) string {
+	var dm int
+
+	if gg {
+		dm = -2
+	} else {
+		dm = 2
+	}
+	roll := tools.RollFlux(r, dm)
+	if roll < -6 {
+		roll = -6
+	}
+	if roll > 6 {
+		roll = 6
+	}
+	if close {
+		roll = roll + 6
+	} else {
+		roll = roll + 19
+	}
+	return satelliteOrbit[roll]
+}
+
+// determineStarport determines the Starport Type. Returns a random value A thru E or X.
+// You should provide an integer indicating how well-travelled this particular subsector
+// is (aka the sparcity). Use the "ss" constants. Standard is ssStandard (=1)
+func determineStarport(r *rand.Rand, sparcity int) string {
+	roll := tools.RollD6(r) + tools.RollD6(r)
+	switch sparcity {
+	case ssBackwater:
+		switch roll {
+		case 2, 3:
+			return "A"
+		case 4, 5:
+			return "B"
+		case 6, 7, 8:
+			return "C"
+		case 9:
+			return "D"
+		case 10, 11:
+			return "E"
+		default:
+			return "X"
+		}
+	case ssCluster:
+		switch roll {
+		case 2, 3, 4, 5:
+			return "A"
+		case 6, 7:
+			return "B"
+		case 8, 9:
+			return "C"
+		case 10:
+			return "D"
+		case 11:
+			return "E"
+		default:
+			return "X"
+		}
+	default:
+		switch roll {
+		case 2, 3, 4:
+			return "A"
+		case 5, 6:
+			return "B"
+		case 7, 8:
+			return "C"
+		case 9:
+			return "D"
+		case 10, 11:
+			return "E"
+		default:
+			if sparcity == ssMature {
+				return "E"
+			}
+			return "X"
+		}
+	}
+}
+
+// determineMainworldType determines the mainworld type, Planet or Satellite(Close|Far). Returns the type as string.
+func determineMainworldType(r *rand.Rand) string {
+	switch roll := tools.RollFlux(r, 0); {
+	case roll <= -4:
+		return mwTypeFarSatellite
+	case roll == -3:
+		return mwTypeCloseSatellite
+	default:
+		return mwTypePlanet
+	}
+}
+
+// determineHabitableZoneVariance determines mainworld orbit modifier based on Star Spectral type and flux. This affects climate and Trade classifications
+// that are based on the climate. Returns value -7 to +7.
+func determineHabitableZoneVariance(r *rand.Rand, s starDetail) int {
+	var dm int
+	switch s.spectralType {
+	case "M":
+		dm = 2
+	case "O", "B":
+		dm = -2
+	default:
+		dm = 0
+	}
+
+	x := tools.RollFlux(r, dm)
+	if x <= -6 {
+		return -2
+	}
+	if x <= -3 {
+		return -1
+	}
+	if x <= 2 {
+		return 0
+	}
+	if x <= 5 {
+		return 1
+	}
+	return 2
+}
+
+// determineExtensions determines all Extensions and Nobility for a world. Returns the updated world.
+func (w *world) determineExtensions(r *rand.Rand) *world {
+	// Importance
+	w.determineImportanceExtension()
+
+	// Economic Extensions
+	w.determineEconomicExtension(r)
+	w.ru = w.economics.calcRU()
+
+	// Cultural Extension
+	w.determineCulturalExtension(r)
+
+	// Nobility
+	w.getNobility()
+
+	return w
+
+}
+
+// determineImportanceExtension gets the Importance for a world, which looks as a string like this "{ +/-x }",
+// where x is between -3 and +8.
+func (w *world) determineImportanceExtension() (i importanceExt) {
+
+	// Importance
+	importInt := 0
+	switch w.uwp.starport {
+	case "A", "B":
+		importInt++
+	case "C":
+		break
+	default:
+		importInt--
+
+	}
+	if w.uwp.techInt >= 16 {
+		importInt++
+	}
+	if w.uwp.techInt >= 10 {
+		importInt++
+	}
+	if w.uwp.techInt <= 8 {
+		importInt--
+	}
+	if strings.Contains(w.remarks, "Ag") {
+		importInt++
+	}
+	if strings.Contains(w.remarks, "Hi") {
+		importInt++
+	}
+	if strings.Contains(w.remarks, "Ri") {
+		importInt++
+	}
+	if w.uwp.popInt <= 6 {
+		importInt--
+	}
+	if strings.Contains(w.bases, "S") && (strings.ContainsAny(w.bases, "DKN")) {
+		importInt++
+	}
+	if strings.Contains(w.bases, "W") {
+		importInt++
+	}
+	w.importance.Importance = importInt
+	return w.importance
+}
+
+// determineCulturalExtension determines the Cultural extension for a world, which looks like this "[HASs]" where
+// H=homogenity, A=acceptance, S=strangeness, and s=Symbols, all expressed in Extended Hex.
+// This is returned as a cultureExt type.
+func (w *world) determineCulturalExtension(r *rand.Rand) cultureExt {
+
+	w.culture.Homogenity = tools.RollFlux(r, w.uwp.popInt)
+	if w.culture.Homogenity < 1 {
+		w.culture.Homogenity = 1
+	}
+	w.culture.Acceptance = w.uwp.popInt + w.importance.Importance
+	if w.culture.Acceptance < 1 {
+		w.culture.Acceptance = 1
+	}
+	w.culture.Strangeness = tools.RollFlux(r, 5)
+	if w.culture.Strangeness < 1 {
+		w.culture.Strangeness = 1
+	}
+	w.culture.Symbols = tools.RollFlux(r, w.uwp.techInt)
+	if w.culture.Symbols < 1 {
+		w.culture.Symbols = 1
+	}
+	if w.uwp.popInt == 0 {
+		w.culture.Homogenity = 0
+		w.culture.Acceptance = 0
+		w.culture.Strangeness = 0
+		w.culture.Symbols = 0
+	}
+	return w.culture
+}
+
+// determineEconomicExtension determines the Economic Extension for a world. Economic extension is in the form "(RLI+/-E)", where R=resources,
+// L=labour, I=infrastructure, and E=+/- efficiency. Resource units is calculated from this. Function returns a economicExt struct.
+func (w *world) determineEconomicExtension(r *rand.Rand) economicExt {
+
+	w.economics.Resource = tools.RollD6(r) + tools.RollD6(r)
+	if w.uwp.techInt >= 8 {
+		w.economics.Resource += w.pbg.gasGiants + w.pbg.planetoids
+	}
+	if w.economics.Resource < 0 {
+		w.economics.Resource = 0
+	}
+	w.economics.Labour = w.uwp.popInt - 1
+	if w.economics.Labour < 0 {
+		w.economics.Labour = 0
+	}
+	w.economics.Infrastructure = tools.RollD6(r) + tools.RollD6(r) + w.importance.Importance
+	if strings.Contains(w.remarks, "Ba") && strings.Contains(w.remarks, "Di") && strings.Contains(w.remarks, "Lo") {
+		w.economics.Infrastructure = 0
+	} else if strings.Contains(w.remarks, "Lo") {
+		w.economics.Infrastructure = 1
+	}
+	if strings.Contains(w.remarks, "Ni") {
+		w.economics.Infrastructure = tools.RollD6(r) + w.importance.Importance
+	}
+	if w.economics.Infrastructure < 0 {
+		w.economics.Infrastructure = 0
+	}
+	w.economics.Efficiency = tools.RollFlux(r, 0)
+
+	return w.economics
+}
+
+// determineBases determines the bases for a mainworld/system. It returns the Bases string
+// but also sets the bases string in the world object. It bases (heh) the generation method
+// on the worldGenState object, and does NOT ask the user for input.
+//
+// In some cases, percentage for some rolls use equivalents on 2d6, ie 10+ = 4-.
+func (w *world) determineBases(r *rand.Rand) {
+
+	w.bases = ""
+	if w.uwp.starport == "E" || w.uwp.starport == "X" {
+		return
+	}
+	imperial := false
+	if strings.Contains(w.allegiance, "Im") {
+		imperial = true
+	}
+
+	switch w.genType {
+	case WgtCt03:
+		if w.uwp.starport == "A" || w.uwp.starport == "B" {
+			if tools.RollD6(r)+tools.RollD6(r) >= 8 {
+				w.bases += "N"
+			}
+		}
+		sbDM := 0
+		switch w.uwp.starport {
+		case "C":
+			sbDM = -1
+		case "B":
+			sbDM = -2
+		case "A":
+			sbDM = -3
+		}
+		if tools.RollD6(r)+tools.RollD6(r)+sbDM >= 7 {
+			w.bases += "S"
+		}
+	case WgtMtBasic:
+		if !imperial {
+			roll := tools.RollD6(r) + tools.RollD6(r)
+			if (w.uwp.starport == "A" && roll >= 10) || (w.uwp.starport == "B" && roll >= 9) || (w.uwp.starport == "C" && roll >= 8) {
+				w.bases = "M"
+			}
+			return
+		}
+		switch w.uwp.starport {
+		case "A":
+			if tools.RollD6(r)+tools.RollD6(r) >= 8 {
+				w.bases += "N"
+			}
+			if tools.RollD6(r)+tools.RollD6(r) >= 10 {
+				w.bases += "S"
+			}
+		case "B":
+			if tools.RollD6(r)+tools.RollD6(r) >= 8 {
+				w.bases += "N"
+			}
+			if tools.RollD6(r)+tools.RollD6(r) >= 9 {
+				w.bases += "S"
+			}
+		case "C":
+			if tools.RollD6(r)+tools.RollD6(r) >= 8 {
+				w.bases += "S"
+			}
+		case "D":
+			if tools.RollD6(r)+tools.RollD6(r) >= 7 {
+				w.bases += "S"
+			}
+		}
+	case WgtT5ss:
+		switch w.uwp.starport {
+		case "A":
+			if tools.RollD6(r)+tools.RollD6(r) <= 6 {
+				w.bases += "N"
+			}
+			if tools.RollD6(r)+tools.RollD6(r) <= 4 {
+				w.bases += "S"
+			}
+		case "B":
+			if tools.RollD6(r)+tools.RollD6(r) <= 5 {
+				w.bases += "N"
+			}
+			if tools.RollD6(r)+tools.RollD6(r) <= 5 {
+				w.bases += "S"
+			}
+		case "C":
+			if tools.RollD6(r)+tools.RollD6(r) <= 6 {
+				w.bases += "S"
+			}
+		case "D":
+			if tools.RollD6(r)+tools.RollD6(r) <= 7 {
+				w.bases += "S"
+			}
+		}
+	}
+	return
+}
+
+// determineZone determines the travel zone for a mainworld based on the world characteristics.
+func (w *world) determineZone(r *rand.Rand) {
+	amberZone := false
+	redZone := false
+
+	w.zone = TzGreen
+
+	switch w.genType {
+	case WgtMtBasic:
+		if w.uwp.starport == "X" {
+			redZone = true
+		} else {
+			switch w.uwp.govInt {
+			case 10:
+				if w.uwp.lawInt == 20 {
+					amberZone = true
+				}
+			case 11:
+				if w.uwp.lawInt >= 19 {
+					amberZone = true
+				}
+			case 12:
+				if w.uwp.lawInt >= 18 {
+					amberZone = true
+				}
+			case 13:
+				if w.uwp.lawInt >= 17 && w.uwp.lawInt <= 19 {
+					amberZone = true
+				} else if w.uwp.lawInt == 20 {
+					redZone = true
+				}
+			case 14:
+				if w.uwp.lawInt == 17 || w.uwp.lawInt == 18 {
+					amberZone = true
+				} else if w.uwp.lawInt >= 19 {
+					redZone = true
+				}
+			case 15:
+				if w.uwp.lawInt == 16 || w.uwp.lawInt == 17 {
+					amberZone = true
+				} else if w.uwp.lawInt >= 18 {
+					redZone = true
+				}
+			}
+		}
+	case WgtT5ss:
+		if w.uwp.govInt+w.uwp.lawInt >= 20 {
+			amberZone = true
+		}
+		if w.uwp.govInt+w.uwp.lawInt >= 22 {
+			redZone = true
+		}
+		if w.uwp.starport == "X" {
+			redZone = true
+		}
+
+		// For Zhodani - assign some amber zones.
+		if strings.Contains(w.allegiance, basicAllegianceMap["Zhodani"]) && !redZone && (w.uwp.govInt == 0 || w.uwp.govInt == 7 || w.uwp.govInt >= 13 || w.uwp.techInt <= 7) {
+			if tools.RollDice(r, 2) == 1 {
+				amberZone = true
+			}
+		}
+	}
+	if amberZone {
+		w.zone = TzAmber
+	}
+	if redZone {
+		w.zone = TzRed
+	}
+}
+
+// determinePBG generate the PBG fields for a homeworld star system. If mwSatGG is true, then the mainworld is a satellite of a Gas Giant.
+func determinePBG(r *rand.Rand, mwSatGG bool) (p worldPBG) {
+
+	p.populationDigit = tools.RollDice(r, 9)
+	p.planetoids = tools.RollD6(r) - 3 // 1d6-3
+	if p.planetoids < 0 {
+		p.planetoids = 0
+	}
+	p.gasGiants = (tools.RollD6(r)+tools.RollD6(r))/2 - 2 // 2d6/2-2
+	if p.gasGiants <= 0 {
+		if mwSatGG {
+			p.gasGiants = 1
+		} else {
+			p.gasGiants = 0
+		}
+	}
+	return
+}
+
+// generateSystemStars generates the stars for a system, given the "flux" for the
+// Primary Spectral and Size. Returns the updated world struct.
+func (w *world) generateSystemStars(r *rand.Rand, starSpectralFlux, starSizeFlux int) *world {
+	// ---- Step F ---- WorldGen Additional Data
+	// Determine is there is a primary companion
+	var closeStar starDetail
+	var nearStar starDetail
+	var farStar starDetail
+
+	// Determine if we have a companion to the Primary star
+	if tools.RollFlux(r, 0) >= 3 {
+		pCompanion := determineStar(r, 0, starSpectralFlux, starSizeFlux, false)
+		pCompanion.position, pCompanion.orbit = starPosCompanion, -1
+		w.stars[0].companion = &pCompanion
+	}
+	// Close star and companion
+	if tools.RollFlux(r, 0) >= 3 {
+		closeStar = determineStar(r, 0, starSpectralFlux, starSizeFlux, false)
+		closeStar.orbit = tools.RollD6(r) - 1 // 1d6-1
+		closeStar.position = starPosClose
+		//closeStar.habitableZone = closeStar.getHabitableZone()
+		if tools.RollFlux(r, 0) >= 3 {
+			closeCompanion := determineStar(r, 0, starSpectralFlux, starSizeFlux, false)
+			closeCompanion.position, closeCompanion.orbit = starPosCompanion, -1
+			closeStar.companion = &closeCompanion
+		}
+		w.stars = append(w.stars, &closeStar)
+	}
+	// Near star and companion
+	if tools.RollFlux(r, 0) >= 3 {
+		nearStar = determineStar(r, 0, starSpectralFlux, starSizeFlux, false)
+		nearStar.orbit = 5 + tools.RollD6(r) // 1d6+5
+		nearStar.position = starPosNear
+		if tools.RollFlux(r, 0) >= 3 {
+			nearCompanion := determineStar(r, 0, starSpectralFlux, starSizeFlux, false)
+			nearCompanion.position, nearCompanion.orbit = starPosCompanion, -1
+			nearStar.companion = &nearCompanion
+		}
+		w.stars = append(w.stars, &nearStar)
+	}
+	// Far star and companion
+	if tools.RollFlux(r, 0) >= 3 {
+		farStar = determineStar(r, 0, starSpectralFlux, starSizeFlux, false)
+		farStar.orbit = 11 + tools.RollD6(r) // d6+11
+		farStar.position = starPosFar
+		if tools.RollFlux(r, 0) >= 3 {
+			farCompanion := determineStar(r, 0, starSpectralFlux, starSizeFlux, false)
+			farCompanion.position, farCompanion.orbit = starPosCompanion, -1
+			farStar.companion = &farCompanion
+		}
+		w.stars = append(w.stars, &farStar)
+	}
+	return w
+}
+
+// determineTradeClassificationsBasic provides the Trade Classifications (aka remarks) for
+// a Basic (Classic Traveller Book 3, MegaTraveller and T5) world. The TCs are returned as a string.
+func (w world) determineTradeClassifications() (r string) {
+
+	// Common for all types
+
+	// ---- Planetary
+	// Asteroid Belt && Vacuum
+	if w.uwp.sizeInt == 0 && w.uwp.atmInt == 0 && w.uwp.hydInt == 0 {
+		r += "As "
+	} else if w.uwp.atmInt == 0 {
+		r += "Va "
+	}
+	// Ice-capped
+	if w.uwp.atmInt <= 1 && w.uwp.hydInt != 0 {
+		r += "Ic "
+	}
+	// Agricultural
+	if w.uwp.atmInt >= 4 && w.uwp.atmInt <= 9 && w.uwp.hydInt >= 4 && w.uwp.hydInt <= 8 && w.uwp.popInt >= 5 && w.uwp.popInt <= 7 {
+		r += "Ag "
+	}
+	// Non-agricultural
+	if w.uwp.atmInt <= 3 && w.uwp.hydInt <= 3 && w.uwp.popInt >= 6 {
+		r += "Na "
+	}
+
+	// These are for both MT and T5SS worlds
+	if w.genType == WgtMtBasic || w.genType == WgtT5ss {
+		// Barren
+		if w.uwp.popInt == 0 && w.uwp.govInt == 0 && w.uwp.lawInt == 0 {
+			r += "Ba "
+		}
+		// Fluid
+		if w.uwp.atmInt >= 10 && w.uwp.atmInt <= 12 && w.uwp.hydInt >= 1 {
+			r += "Fl "
+		}
+		// High Population
+		if w.uwp.popInt >= 9 {
+			r += "Hi "
+		}
+		// Low Population
+		if w.uwp.popInt >= 1 && w.uwp.popInt <= 3 {
+			r += "Lo "
+		}
+	}
+
+	// These remainder vary for each type of generation :-/
+
+	switch w.genType {
+	case WgtCt03:
+		// Desert
+		if w.uwp.sizeInt != 0 && w.uwp.hydInt == 0 {
+			r += "De "
+		}
+		// Water world
+		if w.uwp.hydInt == 10 {
+			r += "Wa "
+		}
+		// Poor
+		if w.uwp.atmInt >= 2 && w.uwp.atmInt <= 5 && w.uwp.hydInt <= 3 {
+			r += "Po "
+		}
+		// Industrial
+		if (w.uwp.atmInt <= 2 || w.uwp.atmInt == 4 || w.uwp.atmInt == 7 || w.uwp.atmInt == 9) && w.uwp.popInt >= 9 {
+			r += "In "
+		}
+		// Non-industrial
+		if w.uwp.popInt <= 6 {
+			r += "Ni "
+		}
+		// Rich
+		if (w.uwp.atmInt == 6 || w.uwp.atmInt == 8) && w.uwp.popInt >= 6 && w.uwp.popInt <= 8 && w.uwp.govInt >= 4 && w.uwp.govInt <= 9 {
+			r += "Ri "
+		}
+	case WgtMtBasic:
+		// Desert
+		if w.uwp.sizeInt != 0 && w.uwp.hydInt == 0 && w.uwp.sizeInt >= 2 {
+			r += "De "
+		}
+		// Water world
+		if w.uwp.hydInt == 10 {
+			r += "Wa "
+		}
+		// Industrial
+		if (w.uwp.atmInt <= 2 || w.uwp.atmInt == 4 || w.uwp.atmInt == 7 || w.uwp.atmInt == 9) && w.uwp.popInt >= 9 {
+			r += "In "
+		}
+		// Non-industrial
+		if w.uwp.popInt <= 6 && w.uwp.popInt >= 1 {
+			r += "Ni "
+		}
+		// Poor
+		if w.uwp.atmInt >= 2 && w.uwp.atmInt <= 5 && w.uwp.hydInt <= 3 && w.uwp.popInt > 0 {
+			r += "Po "
+		}
+		// Rich
+		if (w.uwp.atmInt == 6 || w.uwp.atmInt == 8) && w.uwp.popInt >= 6 && w.uwp.popInt <= 8 && strings.Contains(w.allegiance, basicAllegianceMap["Aslan"]) {
+			r += "Ri "
+		}
+
+	case WgtT5ss:
+		// Desert
+		if w.uwp.atmInt >= 2 && w.uwp.atmInt <= 9 && w.uwp.hydInt == 0 {
+			r += "De "
+		}
+		// Water world
+		if w.uwp.sizeInt >= 3 && w.uwp.sizeInt <= 9 && w.uwp.atmInt >= 3 && w.uwp.atmInt <= 12 && w.uwp.hydInt == 10 {
+			r += "Wa "
+		}
+		// Poor
+		if w.uwp.atmInt >= 2 && w.uwp.atmInt <= 5 && w.uwp.hydInt <= 3 {
+			r += "Po "
+		}
+		// Industrial
+		if (w.uwp.atmInt <= 2 || w.uwp.atmInt == 4 || w.uwp.atmInt == 7 || (w.uwp.atmInt >= 9 && w.uwp.atmInt <= 12)) && w.uwp.popInt >= 9 {
+			r += "In "
+		}
+		// Non-industrial
+		if w.uwp.popInt >= 4 && w.uwp.popInt <= 6 {
+			r += "Ni "
+		}
+		// Garden World
+		if w.uwp.sizeInt >= 6 && w.uwp.sizeInt <= 8 && (w.uwp.atmInt == 5 || w.uwp.atmInt == 6 || w.uwp.atmInt == 8) && w.uwp.hydInt >= 5 && w.uwp.hydInt <= 7 {
+			r += "Ga "
+		}
+		// Hell World
+		if w.uwp.sizeInt >= 3 && w.uwp.sizeInt <= 12 && (w.uwp.atmInt == 2 || w.uwp.atmInt == 4 || w.uwp.atmInt == 7 || (w.uwp.atmInt >= 9 && w.uwp.atmInt <= 12)) && w.uwp.hydInt <= 2 {
+			r += "He "
+		}
+		// Ocean world
+		if w.uwp.sizeInt >= 10 && w.uwp.atmInt >= 3 && w.uwp.atmInt <= 12 && w.uwp.hydInt == 10 {
+			r += "Oc "
+		}
+		// Dieback
+		if w.uwp.popInt == 0 && w.uwp.govInt == 0 && w.uwp.lawInt == 0 && w.uwp.techInt > 0 {
+			r += "Di "
+		}
+		// Pre-High
+		if w.uwp.popInt == 8 {
+			r += "Ph "
+		}
+		// Pre-Agricultural
+		if w.uwp.atmInt >= 4 && w.uwp.atmInt <= 9 && w.uwp.hydInt >= 4 && w.uwp.hydInt <= 8 && (w.uwp.popInt == 4 || w.uwp.popInt == 8) {
+			r += "Pa "
+		}
+		// Prison or Exile
+		if (w.uwp.atmInt == 2 || w.uwp.atmInt == 3 || w.uwp.atmInt == 10 || w.uwp.atmInt == 11) && w.uwp.hydInt >= 1 && w.uwp.hydInt <= 5 && w.uwp.popInt >= 3 && w.uwp.popInt <= 6 && w.uwp.lawInt >= 6 && w.uwp.lawInt <= 9 {
+			r += "Px "
+		}
+		// Pre-Industrial
+		if (w.uwp.atmInt <= 2 || w.uwp.atmInt == 4 || w.uwp.atmInt == 7 || w.uwp.atmInt == 9) && (w.uwp.popInt == 7 || w.uwp.popInt == 8) {
+			r += "Pi "
+		}
+		// Pre-Rich
+		if (w.uwp.atmInt == 6 || w.uwp.atmInt == 8) && (w.uwp.popInt == 5 || w.uwp.popInt == 9) {
+			r += "Pr "
+		}
+		// Rich
+		if (w.uwp.atmInt == 6 || w.uwp.atmInt == 8) && w.uwp.popInt >= 6 && w.uwp.popInt <= 9 {
+			r += "Ri "
+		}
+		// Frozen
+		if w.habZoneVar >= 2 && w.uwp.sizeInt >= 2 && w.uwp.sizeInt <= 9 && w.uwp.hydInt != 0 {
+			r += "Fr "
+		}
+		// Hot
+		if w.habZoneVar == -1 {
+			r += "Ho "
+		}
+		// Cold
+		if w.habZoneVar == 1 {
+			r += "Co "
+		}
+		// Locked
+		if w.planetOrSat == mwTypeCloseSatellite {
+			r += "Co "
+		}
+		// Tropic
+		if w.uwp.sizeInt >= 6 && w.uwp.sizeInt <= 9 && w.uwp.atmInt >= 4 && w.uwp.atmInt <= 9 && w.uwp.hydInt >= 3 && w.uwp.hydInt <= 7 && w.habZoneVar == -1 {
+			r += "Tr "
+		}
+		// Tundra
+		if w.uwp.sizeInt >= 6 && w.uwp.sizeInt <= 9 && w.uwp.atmInt >= 4 && w.uwp.atmInt <= 9 && w.uwp.hydInt >= 3 && w.uwp.hydInt <= 7 && w.habZoneVar == 1 {
+			r += "Tu "
+		}
+
+		// ---- Secondary
+		// Twilight zone (Tz) - Orbit 0 or 1 (calculate later)
+		// Farming (Fa) - HZ but not mainworld ATM 4-9, HYD 4-8, POP 2-6
+		// Mining (Mi) - POP 2-6, Not MW, MW=In
+		// Military Rule (Mr) - Ref
+		// Penal Colony (Pe) - ATM 23AB, HYD 1-5, POP 3-6, GOV 6, LAW 6-9, Not MW
+		// Reserve
+		if w.uwp.popInt >= 1 && w.uwp.popInt <= 4 && w.uwp.govInt == 6 && w.uwp.lawInt >= 4 && w.uwp.lawInt <= 5 {
+			r += "Re "
+		}
+		// // Colony
+		// if uwp.popInt >= 5 && uwp.popInt <= 10 && uwp.govInt == 6 && uwp.lawInt <= 3 {
+		// 	r += "Cy "
+		// }
+		// Far satellite
+		if w.planetOrSat == mwTypeFarSatellite {
+			r += "Sa "
+		}
+	}
+
+	// ---- Population && Economic
+
+	r = strings.TrimRight(r, " ")
+
+	return
+}
+
+// extendWorld extends a basic world to T5SS standards and as a by-product, recalculates extensions.
+// It returns the world worked on.
+func (w *world) extendWorld(r *rand.Rand) *world {
+
+	w.genType = WgtT5ss
+
+	var primary starDetail
+
+	// Check that we have stars, if not generate them.
+	if len(w.stars) == 0 {
+		starSpectralFlux := tools.RollFlux(r, 0)
+		starSizeFlux := tools.RollFlux(r, 0)
+
+		//The "Primary" is the main or homeworld star in a star system.
+		primary := determineStar(r, 0, starSpectralFlux, starSizeFlux, true)
+		w.stars = append(w.stars, &primary)
+
+		// Get additional stars
+		w.generateSystemStars(r, starSpectralFlux, starSizeFlux)
+		// ---- Step F ---- WorldGen Additional Data (Stellar)
+	} else {
+		primary = *w.stars[0]
+	}
+
+	primaryDetail, err := primary.model()
+	if err != nil {
+		log.Printf("Unable to get stellar detail for %s: %v", primary, err)
+	}
+	// Determine the world's habitable zone and orbit
+	w.habZoneVar = determineHabitableZoneVariance(r, primary)
+	w.orbit = primary.habitableZoneOrbit() + w.habZoneVar
+	// Possibly adjust for a minimum orbit. Both habitable zone variance and orbit will need to change.
+	if w.orbit < primaryDetail.MinOrbit {
+		w.habZoneVar = w.habZoneVar + primaryDetail.MinOrbit
+		w.orbit = primaryDetail.MinOrbit
+	}
+
+	w.planetOrSat = determineMainworldType(r)
+	w.mwSatGG = false
+	if strings.Contains(w.planetOrSat, "Sa") {
+		if tools.RollFlux(r, 0) <= 0 {
+			w.mwSatGG = true
+		}
+		w.satOrbit = determineSatOrbit(r, w.mwSatGG, strings.Contains(w.planetOrSat, "Close"))
+	}
+	// Probably already have PBG
+	//w.pbg = determinePBG(w.mwSatGG)
+	w.worlds = 1 + w.pbg.gasGiants + w.pbg.planetoids + tools.RollD6(r) + tools.RollD6(r)
+	w.determineExtensions(r)
+	return w
+}
@@ -0,0 +1,397 @@
+package main
+
+// worldMap.go contains code for generating classic icosahedral (triangle net) world maps, and
+// exporting them as PNG or SVG images. Maps are generated from the world UWP, and the same seed
+// always gives the same map.
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Terrain types for world map triangles.
+const (
+	terOcean = iota
+	terIce
+	terMountain
+	terDesert
+	terPlains
+	terForest
+	terBarren
+	terWasteland
+	terTown
+	terCity
+)
+
+// terrainColours contains the colour each terrain type is drawn in.
+var terrainColours = map[int]color.RGBA{
+	terOcean:     {R: 0x1f, G: 0x4e, B: 0x9c, A: 0xff},
+	terIce:       {R: 0xf0, G: 0xf4, B: 0xff, A: 0xff},
+	terMountain:  {R: 0x7a, G: 0x5c, B: 0x3e, A: 0xff},
+	terDesert:    {R: 0xe0, G: 0xc0, B: 0x78, A: 0xff},
+	terPlains:    {R: 0x8c, G: 0xc0, B: 0x5a, A: 0xff},
+	terForest:    {R: 0x2e, G: 0x7d, B: 0x32, A: 0xff},
+	terBarren:    {R: 0x9e, G: 0x9e, B: 0x9e, A: 0xff},
+	terWasteland: {R: 0xa0, G: 0x8c, B: 0x50, A: 0xff},
+	terTown:      {R: 0xff, G: 0xa0, B: 0x40, A: 0xff},
+	terCity:      {R: 0xe0, G: 0x30, B: 0x30, A: 0xff},
+}
+
+// starportColour is the colour used for the starport marker.
+var starportColour = color.RGBA{R: 0xff, G: 0xff, B: 0x00, A: 0xff}
+
+// mapPoint is a point on the (unscaled) world map. Triangles of the net have sides of length 1.
+type mapPoint struct {
+	X, Y float64
+}
+
+// mapTriangle is a single small triangle of the world map.
+type mapTriangle struct {
+	P       [3]mapPoint // The corners of the triangle
+	Terrain int         // One of the ter constants
+	lat     float64     // The latitude of the centre, in degrees
+	height  float64     // The height (noise value) of the centre
+	wet     float64     // The moisture (second noise value) of the centre
+}
+
+// worldMap is a generated world map.
+type worldMap struct {
+	Name      string        // The name of the world, eg "Avalar"
+	UWP       string        // The world UWP, eg "A75599C-C"
+	Seed      int64         // The seed the map was generated from
+	Triangles []mapTriangle // All the triangles of the map
+	Starport  int           // Index of the triangle containing the starport, or -1 if there is none
+}
+
+// mapHeight is the height of one row of the triangle net.
+var mapHeight = math.Sqrt(3) / 2
+
+// generateWorldMap generates the world map for a world with the given UWP. The size of the world sets how
+// finely the faces of the net are divided, the hydrographics sets the fraction of ocean, the atmosphere sets
+// the land terrain and ice caps, and the population and tech level set the number and size of cities.
+func generateWorldMap(name string, uwp worldUwp, seed int64) *worldMap {
+
+	r := rand.New(rand.NewSource(seed))
+	m := &worldMap{Name: name, UWP: uwp.String(), Seed: seed, Starport: -1}
+
+	res := uwp.sizeInt
+	if res < 2 {
+		res = 2
+	}
+	for _, face := range icosahedronNet() {
+		m.Triangles = append(m.Triangles, subdivideFace(face, res)...)
+	}
+
+	// Heights and moisture come from smooth noise over the sphere, so features run across the faces of the net.
+	heights := newSphereNoise(r)
+	moisture := newSphereNoise(r)
+	for i := range m.Triangles {
+		t := &m.Triangles[i]
+		lat, lon := t.latLon()
+		t.lat = lat
+		t.height = heights.at(lat, lon)
+		t.wet = moisture.at(lat, lon)
+	}
+
+	// Fill the lowest triangles with ocean, to match the hydrographics percentage.
+	oceanFraction := float64(uwp.hydInt) / 10
+	if uwp.sizeInt == 0 {
+		oceanFraction = 0
+	}
+	seaLevel := m.heightQuantile(oceanFraction)
+	mountainLevel := m.heightQuantile(oceanFraction + (1-oceanFraction)*0.85)
+
+	capLatitude := 90.0
+	switch {
+	case uwp.hydInt == 0:
+	case uwp.atmInt < 2:
+		capLatitude = 55
+	default:
+		capLatitude = 78 - 1.5*float64(uwp.hydInt)
+	}
+
+	for i := range m.Triangles {
+		t := &m.Triangles[i]
+		switch {
+		case math.Abs(t.lat) >= capLatitude:
+			t.Terrain = terIce
+		case oceanFraction >= 1 || (oceanFraction > 0 && t.height < seaLevel):
+			t.Terrain = terOcean
+		case t.height >= mountainLevel:
+			t.Terrain = terMountain
+		case uwp.atmInt < 2:
+			t.Terrain = terBarren
+		case uwp.atmInt >= 10 && uwp.atmInt <= 12:
+			t.Terrain = terWasteland
+		case uwp.hydInt <= 2 || t.wet < -0.2:
+			t.Terrain = terDesert
+		case t.wet > 0.2:
+			t.Terrain = terForest
+		default:
+			t.Terrain = terPlains
+		}
+	}
+
+	m.placeCities(r, uwp)
+	return m
+}
+
+// placeCities places towns and cities on the map. There are more with higher populations, and they are
+// cities rather than towns at higher tech levels. The starport (if any) is placed in or next to the largest city.
+func (m *worldMap) placeCities(r *rand.Rand, uwp worldUwp) {
+
+	if uwp.popInt <= 0 {
+		if uwp.starport != "X" && uwp.starport != "Y" && uwp.starport != "" {
+			m.Starport = m.randomTriangle(r, true)
+		}
+		return
+	}
+	count := uwp.popInt * uwp.popInt / 4
+	if count < 1 {
+		count = 1
+	}
+	cities := uwp.popInt/3 + uwp.techInt/6
+	first := -1
+	for i := 0; i < count; i++ {
+		idx := m.randomTriangle(r, true)
+		if idx < 0 {
+			break
+		}
+		m.Triangles[idx].Terrain = terTown
+		if i < cities {
+			m.Triangles[idx].Terrain = terCity
+		}
+		if first < 0 {
+			first = idx
+		}
+	}
+	if uwp.starport != "X" && uwp.starport != "Y" && uwp.starport != "" {
+		m.Starport = first
+	}
+}
+
+// randomTriangle picks a random triangle suitable for settlement, dry land if possible, otherwise any.
+// It returns the index of the triangle, or -1 if there are no triangles.
+func (m *worldMap) randomTriangle(r *rand.Rand, land bool) int {
+	var candidates []int
+	for i, t := range m.Triangles {
+		if !land || (t.Terrain != terOcean && t.Terrain != terIce && t.Terrain != terTown && t.Terrain != terCity) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		if land {
+			return m.randomTriangle(r, false)
+		}
+		return -1
+	}
+	return candidates[r.Intn(len(candidates))]
+}
+
+// heightQuantile returns the height below which the given fraction of the triangles lie.
+func (m *worldMap) heightQuantile(fraction float64) float64 {
+	if len(m.Triangles) == 0 {
+		return 0
+	}
+	heights := make([]float64, len(m.Triangles))
+	for i, t := range m.Triangles {
+		heights[i] = t.height
+	}
+	sort.Float64s(heights)
+	idx := int(fraction * float64(len(heights)))
+	if idx >= len(heights) {
+		return heights[len(heights)-1] + 1
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	return heights[idx]
+}
+
+// icosahedronNet returns the 20 faces of the classic world map net: five northern caps, a strip of ten
+// faces around the equator, and five southern caps.
+func icosahedronNet() (faces [][3]mapPoint) {
+	h := mapHeight
+	for k := 0.0; k < 5; k++ {
+		faces = append(faces,
+			[3]mapPoint{{k + 0.5, 0}, {k, h}, {k + 1, h}},                   // Northern cap
+			[3]mapPoint{{k, h}, {k + 1, h}, {k + 0.5, 2 * h}},               // Equatorial, pointing down
+			[3]mapPoint{{k + 1, h}, {k + 0.5, 2 * h}, {k + 1.5, 2 * h}},     // Equatorial, pointing up
+			[3]mapPoint{{k + 0.5, 2 * h}, {k + 1.5, 2 * h}, {k + 1, 3 * h}}, // Southern cap
+		)
+	}
+	return
+}
+
+// subdivideFace divides a face of the net into res*res small triangles.
+func subdivideFace(face [3]mapPoint, res int) (tris []mapTriangle) {
+	a, b, c := face[0], face[1], face[2]
+	p := func(i, j int) mapPoint {
+		fi, fj := float64(i)/float64(res), float64(j)/float64(res)
+		return mapPoint{a.X + (b.X-a.X)*fi + (c.X-a.X)*fj, a.Y + (b.Y-a.Y)*fi + (c.Y-a.Y)*fj}
+	}
+	for i := 0; i < res; i++ {
+		for j := 0; j < res-i; j++ {
+			tris = append(tris, mapTriangle{P: [3]mapPoint{p(i, j), p(i+1, j), p(i, j+1)}})
+			if j < res-i-1 {
+				tris = append(tris, mapTriangle{P: [3]mapPoint{p(i+1, j), p(i+1, j+1), p(i, j+1)}})
+			}
+		}
+	}
+	return
+}
+
+// centre returns the centre of the triangle.
+func (t mapTriangle) centre() mapPoint {
+	return mapPoint{(t.P[0].X + t.P[1].X + t.P[2].X) / 3, (t.P[0].Y + t.P[1].Y + t.P[2].Y) / 3}
+}
+
+// latLon returns the (approximate) latitude and longitude in degrees of the centre of the triangle.
+func (t mapTriangle) latLon() (lat, lon float64) {
+	c := t.centre()
+	lat = 90 - 180*c.Y/(3*mapHeight)
+	lon = 360 * (c.X - 0.5 + (c.Y / (3 * mapHeight))) / 5
+	return
+}
+
+// sphereNoise is a simple smooth noise function over the surface of a sphere, made from a sum of waves.
+type sphereNoise struct {
+	waves [][4]float64 // Direction (x, y, z) and phase for each wave
+}
+
+// newSphereNoise creates a noise function from the random source.
+func newSphereNoise(r *rand.Rand) sphereNoise {
+	var n sphereNoise
+	for i := 0; i < 12; i++ {
+		n.waves = append(n.waves, [4]float64{r.NormFloat64(), r.NormFloat64(), r.NormFloat64(), r.Float64() * 2 * math.Pi})
+	}
+	return n
+}
+
+// at returns the noise value (roughly -1 to +1) at the given latitude and longitude.
+func (n sphereNoise) at(lat, lon float64) (v float64) {
+	la, lo := lat*math.Pi/180, lon*math.Pi/180
+	x, y, z := math.Cos(la)*math.Cos(lo), math.Cos(la)*math.Sin(lo), math.Sin(la)
+	for i, w := range n.waves {
+		freq := 1 + float64(i%4)
+		v += math.Sin(freq*(w[0]*x+w[1]*y+w[2]*z)+w[3]) / freq
+	}
+	return v / 2
+}
+
+// bounds returns the size of the (unscaled) map.
+func (m *worldMap) bounds() (width, height float64) {
+	return 5.5, 3 * mapHeight
+}
+
+// writePNG writes the map as a PNG image, with triangle sides of scale pixels.
+func (m *worldMap) writePNG(w io.Writer, scale int) error {
+
+	bw, bh := m.bounds()
+	img := image.NewRGBA(image.Rect(0, 0, int(bw*float64(scale))+1, int(bh*float64(scale))+1))
+	for _, t := range m.Triangles {
+		fillTriangle(img, t, float64(scale), terrainColours[t.Terrain])
+	}
+	if m.Starport >= 0 {
+		c := m.Triangles[m.Starport].centre()
+		x, y := int(c.X*float64(scale)), int(c.Y*float64(scale))
+		size := scale / 20
+		if size < 2 {
+			size = 2
+		}
+		for dx := -size; dx <= size; dx++ {
+			for dy := -size; dy <= size; dy++ {
+				img.Set(x+dx, y+dy, starportColour)
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// fillTriangle fills the scaled triangle in the image with the colour.
+func fillTriangle(img *image.RGBA, t mapTriangle, scale float64, col color.RGBA) {
+	var p [3]mapPoint
+	for i := range t.P {
+		p[i] = mapPoint{t.P[i].X * scale, t.P[i].Y * scale}
+	}
+	minX := math.Floor(math.Min(p[0].X, math.Min(p[1].X, p[2].X)))
+	maxX := math.Ceil(math.Max(p[0].X, math.Max(p[1].X, p[2].X)))
+	minY := math.Floor(math.Min(p[0].Y, math.Min(p[1].Y, p[2].Y)))
+	maxY := math.Ceil(math.Max(p[0].Y, math.Max(p[1].Y, p[2].Y)))
+	edge := func(a, b mapPoint, x, y float64) float64 {
+		return (b.X-a.X)*(y-a.Y) - (b.Y-a.Y)*(x-a.X)
+	}
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			px, py := x+0.5, y+0.5
+			e0, e1, e2 := edge(p[0], p[1], px, py), edge(p[1], p[2], px, py), edge(p[2], p[0], px, py)
+			if (e0 >= 0 && e1 >= 0 && e2 >= 0) || (e0 <= 0 && e1 <= 0 && e2 <= 0) {
+				img.SetRGBA(int(x), int(y), col)
+			}
+		}
+	}
+}
+
+// writeSVG writes the map as an SVG image, with triangle sides of scale units.
+func (m *worldMap) writeSVG(w io.Writer, scale int) error {
+
+	s := float64(scale)
+	bw, bh := m.bounds()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\">\n", bw*s, bh*s+s/2)
+	fmt.Fprintf(&sb, "<title>%s %s world map</title>\n", html.EscapeString(m.Name), m.UWP)
+	for _, t := range m.Triangles {
+		c := terrainColours[t.Terrain]
+		fmt.Fprintf(&sb, "<polygon points=\"%.2f,%.2f %.2f,%.2f %.2f,%.2f\" fill=\"#%02x%02x%02x\" stroke=\"#%02x%02x%02x\" stroke-width=\"0.5\"/>\n",
+			t.P[0].X*s, t.P[0].Y*s, t.P[1].X*s, t.P[1].Y*s, t.P[2].X*s, t.P[2].Y*s, c.R, c.G, c.B, c.R, c.G, c.B)
+	}
+	if m.Starport >= 0 {
+		c := m.Triangles[m.Starport].centre()
+		size := s / 20
+		fmt.Fprintf(&sb, "<rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" fill=\"#ffff00\" stroke=\"#000000\"/>\n",
+			c.X*s-size, c.Y*s-size, 2*size, 2*size)
+	}
+	fmt.Fprintf(&sb, "<text x=\"4\" y=\"%.0f\" font-family=\"sans-serif\" font-size=\"%.0f\">%s %s</text>\n", bh*s+s/3, s/6, html.EscapeString(m.Name), m.UWP)
+	sb.WriteString("</svg>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// saveWorldMap saves the map to the named file, as a PNG or SVG image depending on the file extension.
+func (m *worldMap) saveWorldMap(filename string, scale int) error {
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext != ".png" && ext != ".svg" {
+		return fmt.Errorf("%w: %s", ErrUnknownImageFormat, filename)
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if ext == ".png" {
+		err = m.writePNG(f, scale)
+	} else {
+		err = m.writeSVG(f, scale)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// worldMapFilename returns the default filename for a world map, matching the hand-made maps,
+// for example "Avalar UWP A75599C-C world map.png".
+func worldMapFilename(name, uwp, ext string) string {
+	return fmt.Sprintf("%s UWP %s world map.%s", name, uwp, ext)
+}